@@ -0,0 +1,473 @@
+package env
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// awsClient sends requests to the JSON APIs of AWS services, signed with
+// Signature Version 4.
+type awsClient struct {
+	service   string
+	region    string
+	endpoint  string
+	keyID     string
+	secretKey string
+	token     string
+	timeout   time.Duration
+	client    *http.Client
+}
+
+// AWSOption configures an SSMSource or a SecretsManagerSource.
+type AWSOption func(*awsClient)
+
+// AWSRegion sets the region of the service. The default is the value of the
+// environment variable AWS_REGION, or AWS_DEFAULT_REGION.
+func AWSRegion(region string) AWSOption {
+	return func(c *awsClient) {
+		c.region = region
+	}
+}
+
+// AWSCredentials sets the credentials requests are signed with; token is the
+// session token of temporary credentials, and may be empty. The default is the
+// values of the environment variables AWS_ACCESS_KEY_ID, AWS_SECRET_ACCESS_KEY
+// and AWS_SESSION_TOKEN.
+func AWSCredentials(keyID, secretKey, token string) AWSOption {
+	return func(c *awsClient) {
+		c.keyID, c.secretKey, c.token = keyID, secretKey, token
+	}
+}
+
+// AWSEndpoint sets the URL requests are sent to, such as
+// "http://localhost:4566" for LocalStack. The default is the regional endpoint
+// of the service, such as "https://ssm.eu-west-1.amazonaws.com".
+func AWSEndpoint(endpoint string) AWSOption {
+	return func(c *awsClient) {
+		c.endpoint = endpoint
+	}
+}
+
+// AWSTimeout sets the time a request is given to complete, after which it
+// fails. The default is 10 seconds.
+func AWSTimeout(d time.Duration) AWSOption {
+	return func(c *awsClient) {
+		c.timeout = d
+	}
+}
+
+// AWSHTTPClient makes a source use c to send requests. The default is
+// http.DefaultClient; either way, requests are limited by AWSTimeout.
+func AWSHTTPClient(client *http.Client) AWSOption {
+	return func(c *awsClient) {
+		c.client = client
+	}
+}
+
+// newAWSClient returns an awsClient for service, configured using opts and the
+// environment.
+func newAWSClient(service string, opts []AWSOption) (*awsClient, error) {
+	c := &awsClient{
+		service:   service,
+		region:    os.Getenv("AWS_REGION"),
+		keyID:     os.Getenv("AWS_ACCESS_KEY_ID"),
+		secretKey: os.Getenv("AWS_SECRET_ACCESS_KEY"),
+		token:     os.Getenv("AWS_SESSION_TOKEN"),
+		timeout:   defaultRequestTimeout,
+		client:    http.DefaultClient,
+	}
+	if len(c.region) == 0 {
+		c.region = os.Getenv("AWS_DEFAULT_REGION")
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	if len(c.region) == 0 {
+		return nil, errors.New("env: AWS region is required")
+	}
+	if len(c.keyID) == 0 || len(c.secretKey) == 0 {
+		return nil, errors.New("env: AWS credentials are required")
+	}
+	if len(c.endpoint) == 0 {
+		c.endpoint = "https://" + service + "." + c.region + ".amazonaws.com"
+	}
+	if u, err := url.Parse(c.endpoint); err != nil || len(u.Host) == 0 {
+		return nil, fmt.Errorf("env: invalid AWS endpoint %q", c.endpoint)
+	}
+
+	return c, nil
+}
+
+// call calls the action of the JSON API of the service, as in
+// "AmazonSSM.GetParametersByPath", and decodes the response into out.
+func (c *awsClient) call(ctx context.Context, action string, in, out any) error {
+	body, err := json.Marshal(in)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.endpoint, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/x-amz-json-1.1")
+	req.Header.Set("X-Amz-Target", action)
+	c.sign(req, body, time.Now())
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		var e struct {
+			Type    string `json:"__type"`
+			Message string `json:"message"`
+		}
+		_ = json.NewDecoder(resp.Body).Decode(&e)
+		if i := strings.LastIndexByte(e.Type, '#'); i >= 0 {
+			e.Type = e.Type[i+1:]
+		}
+		switch {
+		case len(e.Type) > 0 && len(e.Message) > 0:
+			return fmt.Errorf("%s: %s: %s", action, e.Type, e.Message)
+		case len(e.Type) > 0:
+			return fmt.Errorf("%s: %s", action, e.Type)
+		default:
+			return fmt.Errorf("%s: %s", action, resp.Status)
+		}
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("%s: invalid response: %w", action, err)
+	}
+
+	return nil
+}
+
+// sign signs req, whose body is body, with Signature Version 4 at time t.
+func (c *awsClient) sign(req *http.Request, body []byte, t time.Time) {
+	t = t.UTC()
+	date := t.Format("20060102")
+	req.Header.Set("X-Amz-Date", t.Format("20060102T150405Z"))
+	if len(c.token) > 0 {
+		req.Header.Set("X-Amz-Security-Token", c.token)
+	}
+
+	host := req.Host
+	if len(host) == 0 {
+		host = req.URL.Host
+	}
+	headers := map[string]string{"host": host}
+	for name, values := range req.Header {
+		headers[strings.ToLower(name)] = strings.TrimSpace(strings.Join(values, ","))
+	}
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var canonical strings.Builder
+	path := req.URL.EscapedPath()
+	if len(path) == 0 {
+		path = "/"
+	}
+	query := strings.ReplaceAll(req.URL.Query().Encode(), "+", "%20")
+	canonical.WriteString(req.Method + "\n" + path + "\n" + query + "\n")
+	for _, name := range names {
+		canonical.WriteString(name + ":" + headers[name] + "\n")
+	}
+	signed := strings.Join(names, ";")
+	canonical.WriteString("\n" + signed + "\n" + hashHex(body))
+
+	scope := date + "/" + c.region + "/" + c.service + "/aws4_request"
+	toSign := "AWS4-HMAC-SHA256\n" + t.Format("20060102T150405Z") + "\n" + scope + "\n" + hashHex([]byte(canonical.String()))
+
+	key := hmacSHA256([]byte("AWS4"+c.secretKey), date)
+	key = hmacSHA256(key, c.region)
+	key = hmacSHA256(key, c.service)
+	key = hmacSHA256(key, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(key, toSign))
+
+	req.Header.Set("Authorization", "AWS4-HMAC-SHA256 Credential="+c.keyID+"/"+scope+", SignedHeaders="+signed+", Signature="+signature)
+}
+
+// hashHex returns the SHA-256 hash of b, encoded in hexadecimal.
+func hashHex(b []byte) string {
+	sum := sha256.Sum256(b)
+	return hex.EncodeToString(sum[:])
+}
+
+// hmacSHA256 returns the HMAC-SHA256 of data using key.
+func hmacSHA256(key []byte, data string) []byte {
+	h := hmac.New(sha256.New, key)
+	h.Write([]byte(data))
+	return h.Sum(nil)
+}
+
+// pathName converts the name of a parameter or secret, relative to a path, to
+// the name of a variable, replacing slashes by underscores.
+func pathName(rel string) string {
+	return strings.ReplaceAll(strings.Trim(rel, "/"), "/", "_")
+}
+
+// SSMSource is a Source backed by the parameters under a path of AWS Systems
+// Manager Parameter Store. The parameter "/app/prod/db/password" under the path
+// "/app/prod" provides the variable DB_PASSWORD: the path is removed, slashes
+// are replaced by underscores, and names are matched case-insensitively.
+// SecureString parameters are decrypted, but since Parameter Store commonly
+// holds plain configuration as well, values are not marked as sensitive; use
+// MarkSensitive for the keys of secrets.
+//
+// Parameter Store is accessed over its HTTP API, with requests signed using
+// Signature Version 4. Every parameter under the path is read at once, by Load
+// or by the first lookup, and kept until Forget is called. An SSMSource is safe
+// for concurrent use.
+type SSMSource struct {
+	c    *awsClient
+	path string
+
+	mu     sync.Mutex
+	values map[string]string
+}
+
+// NewSSMSource returns an SSMSource for the parameters under path, such as
+// "/app/prod".
+func NewSSMSource(path string, opts ...AWSOption) (*SSMSource, error) {
+	c, err := newAWSClient("ssm", opts)
+	if err != nil {
+		return nil, err
+	}
+
+	return &SSMSource{c: c, path: "/" + strings.Trim(path, "/")}, nil
+}
+
+// Load reads every parameter under the path, replacing those read before. Use
+// it at startup to report failures early, instead of at the first lookup.
+func (s *SSMSource) Load(ctx context.Context) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.load(ctx)
+}
+
+// load reads every parameter under the path. It must be called with s.mu held.
+func (s *SSMSource) load(ctx context.Context) error {
+	type request struct {
+		Path           string
+		Recursive      bool
+		WithDecryption bool
+		MaxResults     int
+		NextToken      string `json:",omitempty"`
+	}
+	var resp struct {
+		Parameters []struct {
+			Name  string
+			Value string
+		}
+		NextToken string
+	}
+
+	values := make(map[string]string)
+	req := request{Path: s.path, Recursive: true, WithDecryption: true, MaxResults: 10}
+	for {
+		resp.Parameters, resp.NextToken = nil, ""
+		if err := s.c.call(ctx, "AmazonSSM.GetParametersByPath", req, &resp); err != nil {
+			return err
+		}
+		for _, p := range resp.Parameters {
+			if rel, ok := cutPrefix(p.Name, strings.TrimSuffix(s.path, "/")+"/"); ok {
+				values[pathName(rel)] = p.Value
+			}
+		}
+		if len(resp.NextToken) == 0 {
+			break
+		}
+		req.NextToken = resp.NextToken
+	}
+	s.values = values
+
+	return nil
+}
+
+// Lookup retrieves the parameter that matches name, reading every parameter
+// under the path unless they have been read.
+func (s *SSMSource) Lookup(name string) (string, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.values == nil {
+		if err := s.load(context.Background()); err != nil {
+			return "", false, err
+		}
+	}
+
+	return matchName(s.values, name)
+}
+
+// Forget removes the parameters read, so that they are read again by the next
+// lookup.
+func (s *SSMSource) Forget() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.values = nil
+}
+
+// String returns the name of s, as in "ssm:/app/prod".
+func (s *SSMSource) String() string {
+	return "ssm:" + s.path
+}
+
+// SecretsManagerSource is a Source backed by the secrets of AWS Secrets Manager
+// whose names start with a prefix. The secret "app/prod/db/password" under the
+// prefix "app/prod/" provides the variable DB_PASSWORD: the prefix is removed,
+// slashes are replaced by underscores, and names are matched
+// case-insensitively. Secrets are used as they are, even if they hold JSON.
+//
+// Secrets Manager is accessed over its HTTP API, with requests signed using
+// Signature Version 4. Every secret under the prefix is read at once, by Load
+// or by the first lookup, and kept until Forget is called. A
+// SecretsManagerSource is safe for concurrent use.
+type SecretsManagerSource struct {
+	c      *awsClient
+	prefix string
+
+	mu     sync.Mutex
+	values map[string]string
+}
+
+// NewSecretsManagerSource returns a SecretsManagerSource for the secrets whose
+// names start with prefix, such as "app/prod/".
+func NewSecretsManagerSource(prefix string, opts ...AWSOption) (*SecretsManagerSource, error) {
+	c, err := newAWSClient("secretsmanager", opts)
+	if err != nil {
+		return nil, err
+	}
+	if len(prefix) == 0 {
+		return nil, errors.New("env: Secrets Manager prefix is required")
+	}
+
+	return &SecretsManagerSource{c: c, prefix: prefix}, nil
+}
+
+// Load reads every secret under the prefix, replacing those read before. Use it
+// at startup to report failures early, instead of at the first lookup.
+func (s *SecretsManagerSource) Load(ctx context.Context) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.load(ctx)
+}
+
+// load reads every secret under the prefix, in batches. It must be called with
+// s.mu held.
+func (s *SecretsManagerSource) load(ctx context.Context) error {
+	type filter struct {
+		Key    string
+		Values []string
+	}
+	type request struct {
+		Filters    []filter
+		MaxResults int
+		NextToken  string `json:",omitempty"`
+	}
+	var resp struct {
+		SecretValues []struct {
+			Name         string
+			SecretString *string
+			SecretBinary []byte
+		}
+		Errors []struct {
+			SecretId  string
+			ErrorCode string
+			Message   string
+		}
+		NextToken string
+	}
+
+	values := make(map[string]string)
+	req := request{Filters: []filter{{Key: "name", Values: []string{s.prefix}}}, MaxResults: 20}
+	for {
+		resp.SecretValues, resp.Errors, resp.NextToken = nil, nil, ""
+		if err := s.c.call(ctx, "secretsmanager.BatchGetSecretValue", req, &resp); err != nil {
+			return err
+		}
+		if len(resp.Errors) > 0 {
+			e := resp.Errors[0]
+			return fmt.Errorf("secret %s: %s: %s", e.SecretId, e.ErrorCode, e.Message)
+		}
+		for _, sv := range resp.SecretValues {
+			rel, ok := cutPrefix(sv.Name, s.prefix)
+			if !ok {
+				continue
+			}
+			if sv.SecretString != nil {
+				values[pathName(rel)] = *sv.SecretString
+			} else {
+				values[pathName(rel)] = string(sv.SecretBinary)
+			}
+		}
+		if len(resp.NextToken) == 0 {
+			break
+		}
+		req.NextToken = resp.NextToken
+	}
+	s.values = values
+
+	return nil
+}
+
+// Lookup retrieves the secret that matches name, reading every secret under the
+// prefix unless they have been read.
+func (s *SecretsManagerSource) Lookup(name string) (string, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.values == nil {
+		if err := s.load(context.Background()); err != nil {
+			return "", false, err
+		}
+	}
+
+	return matchName(s.values, name)
+}
+
+// Sensitive reports true, so that the secrets are redacted by Dump. See
+// SensitiveSource.
+func (s *SecretsManagerSource) Sensitive() bool {
+	return true
+}
+
+// Forget removes the secrets read, so that they are read again by the next
+// lookup.
+func (s *SecretsManagerSource) Forget() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.values = nil
+}
+
+// String returns the name of s, as in "secretsmanager:app/prod/".
+func (s *SecretsManagerSource) String() string {
+	return "secretsmanager:" + s.prefix
+}
@@ -0,0 +1,283 @@
+package env_test
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/christgf/env"
+)
+
+const (
+	awsKeyID     = "AKIDEXAMPLE"
+	awsSecretKey = "wJalrXUtnFEMI/K7MDENG+bPxRfiCYEXAMPLEKEY"
+)
+
+// fakeAWS is a stand-in for the JSON APIs of Parameter Store and Secrets
+// Manager, which checks the Signature Version 4 of every request.
+type fakeAWS struct {
+	t       *testing.T
+	service string
+	values  map[string]string
+
+	mu    sync.Mutex
+	calls int
+}
+
+func newFakeAWS(t *testing.T, service string, values map[string]string) (*fakeAWS, string) {
+	t.Helper()
+
+	fa := &fakeAWS{t: t, service: service, values: values}
+	srv := httptest.NewServer(fa)
+	t.Cleanup(srv.Close)
+
+	return fa, srv.URL
+}
+
+func (fa *fakeAWS) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	fa.mu.Lock()
+	fa.calls++
+	fa.mu.Unlock()
+
+	body, _ := io.ReadAll(r.Body)
+	if err := fa.verify(r, body); err != nil {
+		w.WriteHeader(http.StatusForbidden)
+		fmt.Fprintf(w, `{"__type":"com.amazon.coral.service#InvalidSignatureException","message":%q}`, err.Error())
+		return
+	}
+
+	var req struct {
+		Path       string
+		MaxResults int
+		NextToken  string
+		Filters    []struct{ Values []string }
+	}
+	_ = json.Unmarshal(body, &req)
+	prefix := req.Path
+	if len(req.Filters) > 0 {
+		prefix = req.Filters[0].Values[0]
+	}
+
+	var names []string
+	for name := range fa.values {
+		if strings.HasPrefix(name, prefix) {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	start, _ := strconv.Atoi(req.NextToken)
+	end := start + req.MaxResults
+	next := strconv.Itoa(end)
+	if end >= len(names) {
+		end, next = len(names), ""
+	}
+
+	var items []map[string]string
+	for _, name := range names[start:end] {
+		switch r.Header.Get("X-Amz-Target") {
+		case "AmazonSSM.GetParametersByPath":
+			items = append(items, map[string]string{"Name": name, "Value": fa.values[name], "Type": "SecureString"})
+		case "secretsmanager.BatchGetSecretValue":
+			items = append(items, map[string]string{"Name": name, "SecretString": fa.values[name]})
+		}
+	}
+	key := "Parameters"
+	if fa.service == "secretsmanager" {
+		key = "SecretValues"
+	}
+	_ = json.NewEncoder(w).Encode(map[string]any{key: items, "NextToken": next})
+}
+
+// verify checks the signature of r, computing it independently of the package.
+func (fa *fakeAWS) verify(r *http.Request, body []byte) error {
+	auth := r.Header.Get("Authorization")
+	var credential, signedHeaders, signature string
+	for _, part := range strings.Split(strings.TrimPrefix(auth, "AWS4-HMAC-SHA256 "), ", ") {
+		k, v, _ := strings.Cut(part, "=")
+		switch k {
+		case "Credential":
+			credential = v
+		case "SignedHeaders":
+			signedHeaders = v
+		case "Signature":
+			signature = v
+		}
+	}
+	scope := strings.SplitN(credential, "/", 2)
+	if len(scope) != 2 || scope[0] != awsKeyID || !strings.HasSuffix(scope[1], "/eu-west-1/"+fa.service+"/aws4_request") {
+		return fmt.Errorf("unexpected credential %q", credential)
+	}
+	if !strings.Contains(signedHeaders, "x-amz-target") || !strings.Contains(signedHeaders, "host") {
+		return fmt.Errorf("unexpected signed headers %q", signedHeaders)
+	}
+
+	var canonical bytes.Buffer
+	fmt.Fprintf(&canonical, "%s\n/\n\n", r.Method)
+	for _, name := range strings.Split(signedHeaders, ";") {
+		value := r.Header.Get(name)
+		if name == "host" {
+			value = r.Host
+		}
+		fmt.Fprintf(&canonical, "%s:%s\n", name, value)
+	}
+	bodyHash := sha256.Sum256(body)
+	fmt.Fprintf(&canonical, "\n%s\n%s", signedHeaders, hex.EncodeToString(bodyHash[:]))
+	canonicalHash := sha256.Sum256(canonical.Bytes())
+
+	mac := func(key []byte, data string) []byte {
+		h := hmac.New(sha256.New, key)
+		h.Write([]byte(data))
+		return h.Sum(nil)
+	}
+	date, _, _ := strings.Cut(scope[1], "/")
+	key := mac(mac(mac(mac([]byte("AWS4"+awsSecretKey), date), "eu-west-1"), fa.service), "aws4_request")
+	toSign := "AWS4-HMAC-SHA256\n" + r.Header.Get("X-Amz-Date") + "\n" + scope[1] + "\n" + hex.EncodeToString(canonicalHash[:])
+	if want := hex.EncodeToString(mac(key, toSign)); signature != want {
+		return fmt.Errorf("signature %s does not match %s", signature, want)
+	}
+
+	return nil
+}
+
+func TestSSMSource(t *testing.T) {
+	fa, endpoint := newFakeAWS(t, "ssm", map[string]string{
+		"/app/prod/db/host":     "db.internal",
+		"/app/prod/db/password": "hunter2",
+		"/app/prod/PORT":        "9000",
+		"/app/prod/a":           "1",
+		"/app/prod/b":           "2",
+		"/app/prod/c":           "3",
+		"/app/prod/d":           "4",
+		"/app/prod/e":           "5",
+		"/app/prod/f":           "6",
+		"/app/prod/g":           "7",
+		"/app/prod/h":           "8",
+		"/app/staging/PORT":     "1",
+	})
+
+	src, err := env.NewSSMSource("/app/prod/", env.AWSRegion("eu-west-1"), env.AWSCredentials(awsKeyID, awsSecretKey, ""), env.AWSEndpoint(endpoint))
+	if err != nil {
+		t.Fatalf("NewSSMSource(): %v", err)
+	}
+	if err := src.Load(context.Background()); err != nil {
+		t.Fatalf("Load(): %v", err)
+	}
+
+	var vs env.VarSet
+	vs.SetSources(src)
+	if got, want := vs.String("DB_PASSWORD", ""), "hunter2"; got != want {
+		t.Errorf("String(%q): got %q, want %q", "DB_PASSWORD", got, want)
+	}
+	if got, want := vs.String("DB_HOST", ""), "db.internal"; got != want {
+		t.Errorf("String(%q): got %q, want %q", "DB_HOST", got, want)
+	}
+	if got, want := vs.Int("PORT", 0), 9000; got != want {
+		t.Errorf("Int(%q): got %d, want %d", "PORT", got, want)
+	}
+	if got, want := vs.Int("H", 0), 8; got != want {
+		t.Errorf("Int(%q): got %d, want %d", "H", got, want)
+	}
+
+	// Eleven parameters are read in two pages, and lookups do not read again.
+	fa.mu.Lock()
+	calls := fa.calls
+	fa.mu.Unlock()
+	if calls != 2 {
+		t.Errorf("got %d calls, want 2", calls)
+	}
+	if got, want := src.String(), "ssm:/app/prod"; got != want {
+		t.Errorf("String(): got %q, want %q", got, want)
+	}
+}
+
+func TestSSMSource_badCredentials(t *testing.T) {
+	_, endpoint := newFakeAWS(t, "ssm", map[string]string{})
+
+	src, err := env.NewSSMSource("/app/prod", env.AWSRegion("eu-west-1"), env.AWSCredentials(awsKeyID, "wrong", ""), env.AWSEndpoint(endpoint))
+	if err != nil {
+		t.Fatalf("NewSSMSource(): %v", err)
+	}
+	_, ok, err := src.Lookup("PORT")
+	if ok || err == nil || !strings.Contains(err.Error(), "InvalidSignatureException") {
+		t.Errorf("Lookup(): got %v, %v, want InvalidSignatureException", ok, err)
+	}
+}
+
+func TestSecretsManagerSource(t *testing.T) {
+	t.Setenv("AWS_REGION", "eu-west-1")
+	t.Setenv("AWS_ACCESS_KEY_ID", awsKeyID)
+	t.Setenv("AWS_SECRET_ACCESS_KEY", awsSecretKey)
+	t.Setenv("AWS_SESSION_TOKEN", "session")
+
+	_, endpoint := newFakeAWS(t, "secretsmanager", map[string]string{
+		"app/prod/db/password": "hunter2",
+		"app/prod/api-key":     "k3y",
+		"other/db/password":    "nope",
+	})
+
+	src, err := env.NewSecretsManagerSource("app/prod/", env.AWSEndpoint(endpoint))
+	if err != nil {
+		t.Fatalf("NewSecretsManagerSource(): %v", err)
+	}
+
+	tests := []struct {
+		name      string
+		wantValue string
+		wantOK    bool
+	}{
+		{name: "DB_PASSWORD", wantValue: "hunter2", wantOK: true},
+		{name: "db_password", wantValue: "hunter2", wantOK: true},
+		{name: "API-KEY", wantValue: "k3y", wantOK: true},
+		{name: "PASSWORD"},
+	}
+	for _, tt := range tests {
+		value, ok, err := src.Lookup(tt.name)
+		if err != nil || value != tt.wantValue || ok != tt.wantOK {
+			t.Errorf("Lookup(%q): got %q, %v, %v, want %q, %v", tt.name, value, ok, err, tt.wantValue, tt.wantOK)
+		}
+	}
+
+	var vs env.VarSet
+	vs.SetSources(src)
+	if got, want := vs.String("DB_PASSWORD", ""), "hunter2"; got != want {
+		t.Errorf("String(%q): got %q, want %q", "DB_PASSWORD", got, want)
+	}
+	checkRedacted(t, &vs, "DB_PASSWORD", "hunter2")
+}
+
+func TestNewSSMSource_errors(t *testing.T) {
+	t.Setenv("AWS_REGION", "")
+	t.Setenv("AWS_DEFAULT_REGION", "")
+	t.Setenv("AWS_ACCESS_KEY_ID", "")
+	t.Setenv("AWS_SECRET_ACCESS_KEY", "")
+
+	if _, err := env.NewSSMSource("/app", env.AWSCredentials(awsKeyID, awsSecretKey, "")); err == nil {
+		t.Errorf("NewSSMSource(): got no error without region")
+	}
+	if _, err := env.NewSSMSource("/app", env.AWSRegion("eu-west-1")); err == nil {
+		t.Errorf("NewSSMSource(): got no error without credentials")
+	}
+}
+
+func TestSSMSource_timeout(t *testing.T) {
+	srv := newHangingServer(t)
+
+	src, err := env.NewSSMSource("/app", env.AWSRegion("eu-west-1"), env.AWSCredentials(awsKeyID, awsSecretKey, ""), env.AWSEndpoint(srv.URL), env.AWSTimeout(50*time.Millisecond))
+	if err != nil {
+		t.Fatalf("NewSSMSource(): %v", err)
+	}
+	checkTimeout(t, src)
+}
@@ -0,0 +1,175 @@
+package env
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// BinaryOption configures the validation of binary values retrieved with
+// Base64, Hex and Binary, and parsed with ParseBase64, ParseHex and ParseBinary.
+type BinaryOption func(*binaryOptions)
+
+type binaryOptions struct {
+	length int
+}
+
+// ExactLength rejects binary values that are not exactly n bytes long once
+// decoded, such as keys of a fixed size.
+func ExactLength(n int) BinaryOption {
+	return func(o *binaryOptions) {
+		o.length = n
+	}
+}
+
+// checkBinary validates b according to the options provided.
+func checkBinary(b []byte, opts []BinaryOption) ([]byte, error) {
+	o := binaryOptions{length: -1}
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	if o.length >= 0 && len(b) != o.length {
+		return nil, fmt.Errorf("env: binary value has %d bytes, want %d", len(b), o.length)
+	}
+
+	return b, nil
+}
+
+// ParseBase64 decodes s as base64, and validates the result according to the
+// options provided. Both the standard and the URL-safe alphabets are accepted,
+// with or without padding. Errors do not include s, since binary values are
+// often secret.
+func ParseBase64(s string, opts ...BinaryOption) ([]byte, error) {
+	enc := base64.RawStdEncoding
+	if strings.ContainsAny(s, "-_") {
+		enc = base64.RawURLEncoding
+	}
+
+	b, err := enc.DecodeString(strings.TrimRight(s, "="))
+	if err != nil {
+		return nil, errors.New("env: invalid base64 value")
+	}
+
+	return checkBinary(b, opts)
+}
+
+// ParseHex decodes s as hexadecimal, and validates the result according to the
+// options provided. Both lower and upper case digits are accepted. Errors do not
+// include s, since binary values are often secret.
+func ParseHex(s string, opts ...BinaryOption) ([]byte, error) {
+	b, err := hex.DecodeString(s)
+	if err != nil {
+		return nil, errors.New("env: invalid hex value")
+	}
+
+	return checkBinary(b, opts)
+}
+
+// ParseBinary decodes s according to its prefix, and validates the result
+// according to the options provided. Values prefixed with "base64:" are decoded
+// using ParseBase64, values prefixed with "hex:" are decoded using ParseHex, and
+// other values are used as they are. For example, all of the following decode
+// to the same bytes:
+//
+//	SIGNING_KEY=base64:c2VjcmV0
+//	SIGNING_KEY=hex:736563726574
+//	SIGNING_KEY=secret
+func ParseBinary(s string, opts ...BinaryOption) ([]byte, error) {
+	if rest, ok := cutPrefix(s, "base64:"); ok {
+		return ParseBase64(rest, opts...)
+	}
+	if rest, ok := cutPrefix(s, "hex:"); ok {
+		return ParseHex(rest, opts...)
+	}
+
+	return checkBinary([]byte(s), opts)
+}
+
+func cutPrefix(s, prefix string) (string, bool) {
+	if !strings.HasPrefix(s, prefix) {
+		return s, false
+	}
+
+	return s[len(prefix):], true
+}
+
+// Base64 retrieves the value of the environment variable named by the key,
+// decodes the value as base64, validates it according to the options provided,
+// and returns the result. If the variable is not present or its value cannot be
+// decoded or is not valid, fallback is returned.
+func (vs *VarSet) Base64(key string, fallback []byte, opts ...BinaryOption) []byte {
+	return vs.binary(key, fallback, ParseBase64, opts)
+}
+
+// Hex retrieves the value of the environment variable named by the key, decodes
+// the value as hexadecimal, validates it according to the options provided, and
+// returns the result. If the variable is not present or its value cannot be
+// decoded or is not valid, fallback is returned.
+func (vs *VarSet) Hex(key string, fallback []byte, opts ...BinaryOption) []byte {
+	return vs.binary(key, fallback, ParseHex, opts)
+}
+
+// Binary retrieves the value of the environment variable named by the key,
+// decodes the value according to its "base64:" or "hex:" prefix, validates it
+// according to the options provided, and returns the result. Values without a
+// prefix are used as they are. If the variable is not present or its value
+// cannot be decoded or is not valid, fallback is returned.
+func (vs *VarSet) Binary(key string, fallback []byte, opts ...BinaryOption) []byte {
+	return vs.binary(key, fallback, ParseBinary, opts)
+}
+
+func (vs *VarSet) binary(key string, fallback []byte, parse func(string, ...BinaryOption) ([]byte, error), opts []BinaryOption) []byte {
+	return get(vs, key, fallback, func(s string) ([]byte, error) {
+		return parse(s, opts...)
+	})
+}
+
+// Base64 retrieves the value of the environment variable named by the key,
+// decodes the value as base64, validates it according to the options provided,
+// and returns the result. If the variable is not present or its value cannot be
+// decoded or is not valid, fallback is returned.
+func Base64(key string, fallback []byte, opts ...BinaryOption) []byte {
+	return osVarSet.Base64(key, fallback, opts...)
+}
+
+// Hex retrieves the value of the environment variable named by the key, decodes
+// the value as hexadecimal, validates it according to the options provided, and
+// returns the result. If the variable is not present or its value cannot be
+// decoded or is not valid, fallback is returned.
+func Hex(key string, fallback []byte, opts ...BinaryOption) []byte {
+	return osVarSet.Hex(key, fallback, opts...)
+}
+
+// Binary retrieves the value of the environment variable named by the key,
+// decodes the value according to its "base64:" or "hex:" prefix, validates it
+// according to the options provided, and returns the result. Values without a
+// prefix are used as they are. If the variable is not present or its value
+// cannot be decoded or is not valid, fallback is returned.
+func Binary(key string, fallback []byte, opts ...BinaryOption) []byte {
+	return osVarSet.Binary(key, fallback, opts...)
+}
+
+// Base64Var retrieves the value of the environment variable named by the key,
+// decodes the value as base64, validates it according to the options provided,
+// and stores the result into the variable pointed by p.
+func Base64Var(p *[]byte, key string, fallback []byte, opts ...BinaryOption) {
+	*p = osVarSet.Base64(key, fallback, opts...)
+}
+
+// HexVar retrieves the value of the environment variable named by the key,
+// decodes the value as hexadecimal, validates it according to the options
+// provided, and stores the result into the variable pointed by p.
+func HexVar(p *[]byte, key string, fallback []byte, opts ...BinaryOption) {
+	*p = osVarSet.Hex(key, fallback, opts...)
+}
+
+// BinaryVar retrieves the value of the environment variable named by the key,
+// decodes the value according to its "base64:" or "hex:" prefix, validates it
+// according to the options provided, and stores the result into the variable
+// pointed by p.
+func BinaryVar(p *[]byte, key string, fallback []byte, opts ...BinaryOption) {
+	*p = osVarSet.Binary(key, fallback, opts...)
+}
@@ -0,0 +1,134 @@
+package env_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/christgf/env"
+)
+
+func TestParseBase64(t *testing.T) {
+	tests := map[string]struct {
+		value   string
+		want    []byte
+		wantErr bool
+	}{
+		"standard":           {value: "+/8=", want: []byte{0xfb, 0xff}},
+		"standard unpadded":  {value: "+/8", want: []byte{0xfb, 0xff}},
+		"URL-safe":           {value: "-_8=", want: []byte{0xfb, 0xff}},
+		"URL-safe unpadded":  {value: "-_8", want: []byte{0xfb, 0xff}},
+		"empty":              {value: "", want: []byte{}},
+		"mixed alphabets":    {value: "+_8=", wantErr: true},
+		"invalid characters": {value: "c2Vj!mV0", wantErr: true},
+		"invalid length":     {value: "c", wantErr: true},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			got, err := env.ParseBase64(tc.value)
+			if tc.wantErr {
+				if err == nil {
+					t.Errorf("ParseBase64(%q): got %v, want error", tc.value, got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseBase64(%q): %v", tc.value, err)
+			}
+			if !bytes.Equal(got, tc.want) {
+				t.Errorf("ParseBase64(%q): got %v, want %v", tc.value, got, tc.want)
+			}
+		})
+	}
+}
+
+func TestParseBinary(t *testing.T) {
+	want := []byte("secret")
+	for _, value := range []string{"base64:c2VjcmV0", "hex:736563726574", "secret"} {
+		got, err := env.ParseBinary(value)
+		if err != nil {
+			t.Fatalf("ParseBinary(%q): %v", value, err)
+		}
+		if !bytes.Equal(got, want) {
+			t.Errorf("ParseBinary(%q): got %q, want %q", value, got, want)
+		}
+	}
+
+	if _, err := env.ParseBinary("hex:secret"); err == nil {
+		t.Errorf("ParseBinary(%q): got no error", "hex:secret")
+	}
+	if _, err := env.ParseBinary("base64:c2VjcmV0", env.ExactLength(32)); err == nil {
+		t.Errorf("ParseBinary(%q, ExactLength(32)): got no error", "base64:c2VjcmV0")
+	}
+}
+
+func TestBase64(t *testing.T) {
+	const envKey = "ENV_TEST_BASE64"
+
+	fallback := []byte("fallback")
+	if got := env.Base64(envKey, fallback); !bytes.Equal(got, fallback) {
+		t.Errorf("Base64(%q): got %q, want %q", envKey, got, fallback)
+	}
+
+	t.Setenv(envKey, "c2VjcmV0")
+	if got, want := env.Base64(envKey, fallback), []byte("secret"); !bytes.Equal(got, want) {
+		t.Errorf("Base64(%q): got %q, want %q", envKey, got, want)
+	}
+	if got, want := env.Base64(envKey, fallback, env.ExactLength(6)), []byte("secret"); !bytes.Equal(got, want) {
+		t.Errorf("Base64(%q, ExactLength(6)): got %q, want %q", envKey, got, want)
+	}
+	if got := env.Base64(envKey, fallback, env.ExactLength(32)); !bytes.Equal(got, fallback) {
+		t.Errorf("Base64(%q, ExactLength(32)): got %q, want %q", envKey, got, fallback)
+	}
+
+	var p []byte
+	env.Base64Var(&p, envKey, fallback)
+	if want := []byte("secret"); !bytes.Equal(p, want) {
+		t.Errorf("Base64Var(%q): got %q, want %q", envKey, p, want)
+	}
+
+	prefix, key := "ENV_", "TEST_BASE64"
+	env.SetPrefix(prefix)
+	if got, want := env.Base64(key, fallback), []byte("secret"); !bytes.Equal(got, want) {
+		t.Errorf("Base64(Prefix=%q, Key=%q): got %q, want %q", prefix, key, got, want)
+	}
+	env.SetPrefix("")
+}
+
+func TestHex(t *testing.T) {
+	const envKey = "ENV_TEST_HEX"
+
+	fallback := []byte("fallback")
+	t.Setenv(envKey, "736563726574")
+	if got, want := env.Hex(envKey, fallback), []byte("secret"); !bytes.Equal(got, want) {
+		t.Errorf("Hex(%q): got %q, want %q", envKey, got, want)
+	}
+
+	var p []byte
+	env.HexVar(&p, envKey, fallback, env.ExactLength(6))
+	if want := []byte("secret"); !bytes.Equal(p, want) {
+		t.Errorf("HexVar(%q): got %q, want %q", envKey, p, want)
+	}
+
+	t.Setenv(envKey, "736563726574a")
+	if got := env.Hex(envKey, fallback); !bytes.Equal(got, fallback) {
+		t.Errorf("Hex(%q): got %q, want %q", envKey, got, fallback)
+	}
+}
+
+func TestBinary(t *testing.T) {
+	const envKey = "ENV_TEST_BINARY"
+
+	fallback := []byte("fallback")
+	t.Setenv(envKey, "base64:c2VjcmV0")
+	if got, want := env.Binary(envKey, fallback), []byte("secret"); !bytes.Equal(got, want) {
+		t.Errorf("Binary(%q): got %q, want %q", envKey, got, want)
+	}
+
+	t.Setenv(envKey, "hex:zz")
+	var p []byte
+	env.BinaryVar(&p, envKey, fallback)
+	if !bytes.Equal(p, fallback) {
+		t.Errorf("BinaryVar(%q): got %q, want %q", envKey, p, fallback)
+	}
+}
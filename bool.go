@@ -0,0 +1,118 @@
+package env
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ParseBool parses a boolean value. In addition to the values accepted by
+// strconv.ParseBool, it accepts "y", "yes", "on", "enable" and "enabled" for
+// true, and "n", "no", "off", "disable" and "disabled" for false. Values are
+// matched case-insensitively, ignoring surrounding whitespace.
+//
+// ParseBool can be passed to SetBoolParser to make Bool accept these values.
+func ParseBool(s string) (bool, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "1", "t", "true", "y", "yes", "on", "enable", "enabled":
+		return true, nil
+	case "0", "f", "false", "n", "no", "off", "disable", "disabled":
+		return false, nil
+	}
+
+	return false, fmt.Errorf("env: invalid boolean %q", s)
+}
+
+// SetBoolParser makes this VarSet parse boolean values using parse, in Bool and
+// TriBool. The default parser is strconv.ParseBool, which does not accept values
+// such as "yes" or "on"; use ParseBool for a richer vocabulary. Use nil to reset.
+func (vs *VarSet) SetBoolParser(parse func(string) (bool, error)) {
+	vs.parseBool = parse
+}
+
+// boolParser returns the function this VarSet uses to parse boolean values.
+func (vs *VarSet) boolParser() func(string) (bool, error) {
+	if vs.parseBool == nil {
+		return strconv.ParseBool
+	}
+
+	return vs.parseBool
+}
+
+// Tristate is a boolean setting that may also be left unset, to distinguish a
+// setting that is not configured from one that is explicitly false.
+type Tristate int8
+
+// Values of a Tristate.
+const (
+	NotSet Tristate = iota
+	True
+	False
+)
+
+// IsSet reports whether the setting is explicitly true or false.
+func (ts Tristate) IsSet() bool {
+	return ts == True || ts == False
+}
+
+// OrElse returns true or false if the setting is explicitly set, otherwise it
+// returns fallback.
+func (ts Tristate) OrElse(fallback bool) bool {
+	switch ts {
+	case True:
+		return true
+	case False:
+		return false
+	default:
+		return fallback
+	}
+}
+
+// String returns "true", "false" or "unset".
+func (ts Tristate) String() string {
+	switch ts {
+	case True:
+		return "true"
+	case False:
+		return "false"
+	default:
+		return "unset"
+	}
+}
+
+// TriBool retrieves the value of the environment variable named by the key,
+// parses the value as a boolean, and returns the result as True or False. If the
+// variable is not present or its value cannot be parsed, NotSet is returned.
+func (vs *VarSet) TriBool(key string) Tristate {
+	return get(vs, key, NotSet, func(s string) (Tristate, error) {
+		res, err := vs.boolParser()(s)
+		if err != nil {
+			return NotSet, err
+		}
+		if res {
+			return True, nil
+		}
+
+		return False, nil
+	})
+}
+
+// SetBoolParser makes the default VarSet parse boolean values using parse. See
+// VarSet.SetBoolParser.
+func SetBoolParser(parse func(string) (bool, error)) {
+	osVarSet.SetBoolParser(parse)
+}
+
+// TriBool retrieves the value of the environment variable named by the key,
+// parses the value as a boolean, and returns the result as True or False. If the
+// variable is not present or its value cannot be parsed, NotSet is returned.
+func TriBool(key string) Tristate {
+	return osVarSet.TriBool(key)
+}
+
+// TriBoolVar retrieves the value of the environment variable named by the key,
+// parses the value as a boolean, and stores the result as True, False or NotSet
+// into the variable pointed by p.
+func TriBoolVar(p *Tristate, key string) {
+	*p = osVarSet.TriBool(key)
+}
@@ -0,0 +1,168 @@
+package env_test
+
+import (
+	"testing"
+
+	"github.com/christgf/env"
+)
+
+func TestParseBool(t *testing.T) {
+	tests := []struct {
+		s       string
+		want    bool
+		wantErr bool
+	}{
+		{s: "true", want: true},
+		{s: "TRUE", want: true},
+		{s: "1", want: true},
+		{s: "yes", want: true},
+		{s: "Y", want: true},
+		{s: "On", want: true},
+		{s: " enabled ", want: true},
+		{s: "enable", want: true},
+		{s: "false", want: false},
+		{s: "0", want: false},
+		{s: "no", want: false},
+		{s: "N", want: false},
+		{s: "OFF", want: false},
+		{s: "Disabled", want: false},
+		{s: "disable", want: false},
+		{s: "", wantErr: true},
+		{s: "foobar", wantErr: true},
+		{s: "2", wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := env.ParseBool(tt.s)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("ParseBool(%q): want error", tt.s)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("ParseBool(%q): %v", tt.s, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("ParseBool(%q): got %v, want %v", tt.s, got, tt.want)
+		}
+	}
+}
+
+func TestVarSet_SetBoolParser(t *testing.T) {
+	const envKey = "ENV_TEST_BOOL_PARSER"
+
+	t.Setenv(envKey, "yes")
+
+	var vs env.VarSet
+	if got := vs.Bool(envKey, false); got != false {
+		t.Errorf("Bool(%q): got %v", envKey, got)
+	}
+
+	vs.SetBoolParser(env.ParseBool)
+	if got := vs.Bool(envKey, false); got != true {
+		t.Errorf("Bool(%q): got %v", envKey, got)
+	}
+
+	vs.SetBoolParser(nil)
+	if got := vs.Bool(envKey, false); got != false {
+		t.Errorf("Bool(%q): got %v", envKey, got)
+	}
+}
+
+func TestTriBool(t *testing.T) {
+	const envKey = "ENV_TEST_TRI_BOOL"
+
+	if got, want := env.TriBool(envKey), env.NotSet; got != want {
+		t.Errorf("TriBool(%q): got %v, want %v", envKey, got, want)
+	}
+
+	tests := []struct {
+		name      string
+		envValue  string
+		wantValue env.Tristate
+	}{
+		{
+			name:      "value is empty",
+			envValue:  "",
+			wantValue: env.NotSet,
+		},
+		{
+			name:      "value set to true",
+			envValue:  "true",
+			wantValue: env.True,
+		},
+		{
+			name:      "value set to false",
+			envValue:  "false",
+			wantValue: env.False,
+		},
+		{
+			name:      "value set to 0",
+			envValue:  "0",
+			wantValue: env.False,
+		},
+		{
+			name:      "value is foobar",
+			envValue:  "foobar",
+			wantValue: env.NotSet,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv(envKey, tt.envValue)
+			if got, want := env.TriBool(envKey), tt.wantValue; got != want {
+				t.Errorf("TriBool(%q): got %v, want %v", envKey, got, want)
+			}
+
+			var p env.Tristate
+			env.TriBoolVar(&p, envKey)
+			if got, want := p, tt.wantValue; got != want {
+				t.Errorf("TriBoolVar(%q): got %v, want %v", envKey, got, want)
+			}
+
+			prefix, key := "ENV_", "TEST_TRI_BOOL"
+			env.SetPrefix(prefix)
+			if got, want := env.TriBool(key), tt.wantValue; got != want {
+				t.Errorf("TriBool(Prefix=%q, Key=%q): got %v, want %v", prefix, key, got, want)
+			}
+
+			env.SetPrefix("")
+			if got, want := env.TriBool(key), env.NotSet; got != want {
+				t.Errorf("TriBool(Prefix=%q, Key=%q): got %v, want %v", prefix, key, got, want)
+			}
+		})
+	}
+
+	var vs env.VarSet
+	vs.SetBoolParser(env.ParseBool)
+	t.Setenv(envKey, "off")
+	if got, want := vs.TriBool(envKey), env.False; got != want {
+		t.Errorf("TriBool(%q): got %v, want %v", envKey, got, want)
+	}
+}
+
+func TestTristate(t *testing.T) {
+	tests := []struct {
+		ts       env.Tristate
+		wantSet  bool
+		fallback bool
+		wantOr   bool
+		wantName string
+	}{
+		{ts: env.NotSet, wantSet: false, fallback: true, wantOr: true, wantName: "unset"},
+		{ts: env.True, wantSet: true, fallback: false, wantOr: true, wantName: "true"},
+		{ts: env.False, wantSet: true, fallback: true, wantOr: false, wantName: "false"},
+	}
+	for _, tt := range tests {
+		if got := tt.ts.IsSet(); got != tt.wantSet {
+			t.Errorf("%v.IsSet(): got %v, want %v", tt.ts, got, tt.wantSet)
+		}
+		if got := tt.ts.OrElse(tt.fallback); got != tt.wantOr {
+			t.Errorf("%v.OrElse(%v): got %v, want %v", tt.ts, tt.fallback, got, tt.wantOr)
+		}
+		if got := tt.ts.String(); got != tt.wantName {
+			t.Errorf("String(): got %q, want %q", got, tt.wantName)
+		}
+	}
+}
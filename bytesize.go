@@ -0,0 +1,161 @@
+package env
+
+import (
+	"fmt"
+	"math"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+// ByteSize is a size in bytes, such as the size of a buffer, a cache or an
+// upload limit.
+type ByteSize uint64
+
+// Common sizes, in SI (decimal) and IEC (binary) units.
+const (
+	Byte ByteSize = 1
+
+	KB ByteSize = 1000 * Byte
+	MB ByteSize = 1000 * KB
+	GB ByteSize = 1000 * MB
+	TB ByteSize = 1000 * GB
+	PB ByteSize = 1000 * TB
+	EB ByteSize = 1000 * PB
+
+	KiB ByteSize = 1024 * Byte
+	MiB ByteSize = 1024 * KiB
+	GiB ByteSize = 1024 * MiB
+	TiB ByteSize = 1024 * GiB
+	PiB ByteSize = 1024 * TiB
+	EiB ByteSize = 1024 * PiB
+)
+
+// byteUnits lists the units understood by ParseByteSize and used by
+// ByteSize.String, from the largest to the smallest.
+var byteUnits = []struct {
+	symbol string
+	size   ByteSize
+}{
+	{"EiB", EiB}, {"EB", EB},
+	{"PiB", PiB}, {"PB", PB},
+	{"TiB", TiB}, {"TB", TB},
+	{"GiB", GiB}, {"GB", GB},
+	{"MiB", MiB}, {"MB", MB},
+	{"KiB", KiB}, {"kB", KB},
+	{"B", Byte},
+}
+
+// String formats b using the largest unit that represents it exactly, such as
+// "10MiB", "2GB" or "1500B", so that ParseByteSize(b.String()) == b.
+func (b ByteSize) String() string {
+	for _, u := range byteUnits {
+		if b >= u.size && b%u.size == 0 {
+			return strconv.FormatUint(uint64(b/u.size), 10) + u.symbol
+		}
+	}
+
+	return "0B"
+}
+
+// ParseByteSize parses a size in bytes, such as "512", "10MiB", "2 GB" or
+// "1.5KB". Units are matched case-insensitively; SI units (kB, MB, GB, TB, PB,
+// EB) are powers of 1000 and IEC units (KiB, MiB, GiB, TiB, PiB, EiB) powers of
+// 1024. A number without a unit is a number of bytes. Fractional sizes are
+// rounded down to a whole number of bytes, and sizes that do not fit in a
+// uint64 result in an error.
+func ParseByteSize(s string) (ByteSize, error) {
+	s = strings.TrimSpace(s)
+
+	i := 0
+	for i < len(s) && ('0' <= s[i] && s[i] <= '9' || s[i] == '.') {
+		i++
+	}
+	number, symbol := s[:i], strings.TrimSpace(s[i:])
+	if len(number) == 0 || strings.Count(number, ".") > 1 || number == "." {
+		return 0, fmt.Errorf("env: invalid byte size %q", s)
+	}
+
+	unit, ok := byteUnit(symbol)
+	if !ok {
+		return 0, fmt.Errorf("env: unknown unit %q in byte size %q", symbol, s)
+	}
+
+	r, ok := new(big.Rat).SetString(number)
+	if !ok {
+		return 0, fmt.Errorf("env: invalid byte size %q", s)
+	}
+	r.Mul(r, new(big.Rat).SetUint64(uint64(unit)))
+
+	n := new(big.Int).Quo(r.Num(), r.Denom())
+	if !n.IsUint64() {
+		return 0, fmt.Errorf("env: byte size %q overflows uint64", s)
+	}
+
+	return ByteSize(n.Uint64()), nil
+}
+
+func byteUnit(symbol string) (ByteSize, bool) {
+	if len(symbol) == 0 {
+		return Byte, true
+	}
+	for _, u := range byteUnits {
+		if strings.EqualFold(u.symbol, symbol) {
+			return u.size, true
+		}
+	}
+
+	return 0, false
+}
+
+// Bytes retrieves the value of the environment variable named by the key,
+// parses the value as a size in bytes, and returns the result. See
+// ParseByteSize for the accepted formats. If the variable is not present or its
+// value cannot be parsed, fallback is returned.
+func (vs *VarSet) Bytes(key string, fallback ByteSize) ByteSize {
+	return get(vs, key, fallback, ParseByteSize)
+}
+
+// BytesInt64 retrieves the value of the environment variable named by the key,
+// parses the value as a size in bytes, and returns the result as an int64. See
+// ParseByteSize for the accepted formats. If the variable is not present or its
+// value cannot be parsed or does not fit in an int64, fallback is returned.
+func (vs *VarSet) BytesInt64(key string, fallback int64) int64 {
+	return get(vs, key, fallback, func(s string) (int64, error) {
+		res, err := ParseByteSize(s)
+		if err == nil && res > math.MaxInt64 {
+			err = fmt.Errorf("env: byte size %q overflows int64", s)
+		}
+		return int64(res), err
+	})
+}
+
+// Bytes retrieves the value of the environment variable named by the key,
+// parses the value as a size in bytes, and returns the result. See
+// ParseByteSize for the accepted formats. If the variable is not present or its
+// value cannot be parsed, fallback is returned.
+func Bytes(key string, fallback ByteSize) ByteSize {
+	return osVarSet.Bytes(key, fallback)
+}
+
+// BytesInt64 retrieves the value of the environment variable named by the key,
+// parses the value as a size in bytes, and returns the result as an int64. See
+// ParseByteSize for the accepted formats. If the variable is not present or its
+// value cannot be parsed or does not fit in an int64, fallback is returned.
+func BytesInt64(key string, fallback int64) int64 {
+	return osVarSet.BytesInt64(key, fallback)
+}
+
+// BytesVar retrieves the value of the environment variable named by the key,
+// parses the value as a size in bytes, and stores the result into the variable
+// pointed by p.
+func BytesVar(p *ByteSize, key string, fallback ByteSize) {
+	*p = osVarSet.Bytes(key, fallback)
+}
+
+// BytesInt64Var retrieves the value of the environment variable named by the
+// key, parses the value as a size in bytes, and stores the result as an int64
+// into the variable pointed by p.
+func BytesInt64Var(p *int64, key string, fallback int64) {
+	*p = osVarSet.BytesInt64(key, fallback)
+}
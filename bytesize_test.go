@@ -0,0 +1,182 @@
+package env_test
+
+import (
+	"math"
+	"testing"
+
+	"github.com/christgf/env"
+)
+
+func TestBytes(t *testing.T) {
+	const envKey = "ENV_TEST_BYTES"
+
+	if got, want := env.Bytes(envKey, 4*env.KiB), 4*env.KiB; got != want {
+		t.Errorf("Bytes(%q): got %v, want %v", envKey, got, want)
+	}
+
+	tests := []struct {
+		name      string
+		envValue  string
+		fallback  env.ByteSize
+		wantValue env.ByteSize
+	}{
+		{
+			name:      "value is empty",
+			envValue:  "",
+			fallback:  42,
+			wantValue: 42,
+		},
+		{
+			name:      "value is zero",
+			envValue:  "0",
+			fallback:  42,
+			wantValue: 0,
+		},
+		{
+			name:      "value has no unit",
+			envValue:  "512",
+			fallback:  42,
+			wantValue: 512,
+		},
+		{
+			name:      "value is in bytes",
+			envValue:  "512B",
+			fallback:  42,
+			wantValue: 512,
+		},
+		{
+			name:      "value is in MiB",
+			envValue:  "10MiB",
+			fallback:  42,
+			wantValue: 10 * env.MiB,
+		},
+		{
+			name:      "value is in GB",
+			envValue:  "2GB",
+			fallback:  42,
+			wantValue: 2 * env.GB,
+		},
+		{
+			name:      "value is lowercase with space",
+			envValue:  " 64 kib ",
+			fallback:  42,
+			wantValue: 64 * env.KiB,
+		},
+		{
+			name:      "value is fractional",
+			envValue:  "1.5kB",
+			fallback:  42,
+			wantValue: 1500,
+		},
+		{
+			name:      "value is fractional bytes",
+			envValue:  "0.3KiB",
+			fallback:  42,
+			wantValue: 307,
+		},
+		{
+			name:      "value is maximum",
+			envValue:  "18446744073709551615",
+			fallback:  42,
+			wantValue: math.MaxUint64,
+		},
+		{
+			name:      "value overflows",
+			envValue:  "16EiB",
+			fallback:  42,
+			wantValue: 42,
+		},
+		{
+			name:      "value is negative",
+			envValue:  "-1MB",
+			fallback:  42,
+			wantValue: 42,
+		},
+		{
+			name:      "unit is unknown",
+			envValue:  "10XB",
+			fallback:  42,
+			wantValue: 42,
+		},
+		{
+			name:      "value is foobar",
+			envValue:  "foobar",
+			fallback:  42,
+			wantValue: 42,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv(envKey, tt.envValue)
+			if got, want := env.Bytes(envKey, tt.fallback), tt.wantValue; got != want {
+				t.Errorf("Bytes(%q): got %d, want %d", envKey, got, want)
+			}
+
+			var p env.ByteSize
+			env.BytesVar(&p, envKey, tt.fallback)
+			if got, want := p, tt.wantValue; got != want {
+				t.Errorf("BytesVar(%q): got %d, want %d", envKey, got, want)
+			}
+
+			prefix, key := "ENV_", "TEST_BYTES"
+			env.SetPrefix(prefix)
+			if got, want := env.Bytes(key, tt.fallback), tt.wantValue; got != want {
+				t.Errorf("Bytes(Prefix=%q, Key=%q): got %d, want %d", prefix, key, got, want)
+			}
+
+			env.SetPrefix("")
+			if got, want := env.Bytes(key, tt.fallback), tt.fallback; got != want {
+				t.Errorf("Bytes(Prefix=%q, Key=%q): got %d, want %d", prefix, key, got, want)
+			}
+		})
+	}
+}
+
+func TestBytesInt64(t *testing.T) {
+	const envKey = "ENV_TEST_BYTES_INT64"
+
+	t.Setenv(envKey, "10MiB")
+	if got, want := env.BytesInt64(envKey, 42), int64(10<<20); got != want {
+		t.Errorf("BytesInt64(%q): got %d, want %d", envKey, got, want)
+	}
+
+	t.Setenv(envKey, "8EiB")
+	if got, want := env.BytesInt64(envKey, 42), int64(42); got != want {
+		t.Errorf("BytesInt64(%q): got %d, want %d", envKey, got, want)
+	}
+
+	var p int64
+	env.BytesInt64Var(&p, envKey, 42)
+	if got, want := p, int64(42); got != want {
+		t.Errorf("BytesInt64Var(%q): got %d, want %d", envKey, got, want)
+	}
+}
+
+func TestByteSize_String(t *testing.T) {
+	tests := []struct {
+		size env.ByteSize
+		want string
+	}{
+		{size: 0, want: "0B"},
+		{size: 1500, want: "1500B"},
+		{size: 1000, want: "1kB"},
+		{size: 1024, want: "1KiB"},
+		{size: 10 * env.MiB, want: "10MiB"},
+		{size: 2 * env.GB, want: "2GB"},
+		{size: 1536 * env.MiB, want: "1536MiB"},
+		{size: math.MaxUint64, want: "18446744073709551615B"},
+	}
+	for _, tt := range tests {
+		if got := tt.size.String(); got != tt.want {
+			t.Errorf("ByteSize(%d).String(): got %q, want %q", uint64(tt.size), got, tt.want)
+		}
+
+		size, err := env.ParseByteSize(tt.want)
+		if err != nil {
+			t.Fatalf("ParseByteSize(%q): %v", tt.want, err)
+		}
+		if size != tt.size {
+			t.Errorf("ParseByteSize(%q): got %d, want %d", tt.want, size, tt.size)
+		}
+	}
+}
@@ -0,0 +1,269 @@
+package env
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Defaults of a ConsulSource.
+const (
+	defaultConsulWait     = 5 * time.Minute
+	defaultConsulCacheTTL = 5 * time.Minute
+)
+
+// ConsulSource is a Source backed by the keys under a prefix of the key/value
+// store of HashiCorp Consul. The key "app/db/password" under the prefix "app"
+// provides the variable DB_PASSWORD: the prefix is removed, slashes are
+// replaced by underscores, and names are matched case-insensitively.
+//
+// Consul is accessed over its HTTP API. The keys are read once and cached for
+// the cache TTL, unless the source is watched: Watch keeps the keys up to date
+// using blocking queries, which Consul answers as soon as a key under the prefix
+// changes. A ConsulSource is safe for concurrent use.
+type ConsulSource struct {
+	addr       string
+	prefix     string
+	token      string
+	datacenter string
+	wait       time.Duration
+	ttl        time.Duration
+	timeout    time.Duration
+	client     *http.Client
+
+	mu       sync.Mutex
+	values   map[string]string
+	till     time.Time
+	watching int
+}
+
+// ConsulOption configures a ConsulSource.
+type ConsulOption func(*ConsulSource)
+
+// ConsulToken makes a ConsulSource authenticate with the ACL token provided.
+func ConsulToken(token string) ConsulOption {
+	return func(c *ConsulSource) {
+		c.token = token
+	}
+}
+
+// ConsulDatacenter makes a ConsulSource read the keys of the datacenter dc,
+// instead of the datacenter of the agent it queries.
+func ConsulDatacenter(dc string) ConsulOption {
+	return func(c *ConsulSource) {
+		c.datacenter = dc
+	}
+}
+
+// ConsulWait sets the longest time Consul holds a blocking query of Watch
+// before it answers without changes. The default is 5 minutes.
+func ConsulWait(d time.Duration) ConsulOption {
+	return func(c *ConsulSource) {
+		c.wait = d
+	}
+}
+
+// ConsulCacheTTL sets the time keys are cached for while the source is not
+// watched. The default is 5 minutes, and zero disables caching.
+func ConsulCacheTTL(d time.Duration) ConsulOption {
+	return func(c *ConsulSource) {
+		c.ttl = d
+	}
+}
+
+// ConsulTimeout sets the time a request to Consul is given to complete, after
+// which it fails. Blocking queries are given the wait time set by ConsulWait in
+// addition. The default is 10 seconds.
+func ConsulTimeout(d time.Duration) ConsulOption {
+	return func(c *ConsulSource) {
+		c.timeout = d
+	}
+}
+
+// ConsulHTTPClient makes a ConsulSource use c to access Consul. The default is
+// http.DefaultClient; either way, requests are limited by ConsulTimeout.
+func ConsulHTTPClient(client *http.Client) ConsulOption {
+	return func(c *ConsulSource) {
+		c.client = client
+	}
+}
+
+// NewConsulSource returns a ConsulSource for the keys under prefix, such as
+// "app/config", of the Consul agent at addr, such as "http://127.0.0.1:8500".
+// An empty prefix selects every key.
+func NewConsulSource(addr, prefix string, opts ...ConsulOption) (*ConsulSource, error) {
+	c := &ConsulSource{
+		addr:    strings.TrimSuffix(addr, "/"),
+		prefix:  strings.Trim(prefix, "/"),
+		wait:    defaultConsulWait,
+		ttl:     defaultConsulCacheTTL,
+		timeout: defaultRequestTimeout,
+		client:  http.DefaultClient,
+	}
+	if len(c.prefix) > 0 {
+		c.prefix += "/"
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	if _, err := url.Parse(c.addr); err != nil || len(c.addr) == 0 {
+		return nil, fmt.Errorf("env: invalid Consul address %q", addr)
+	}
+
+	return c, nil
+}
+
+// Lookup retrieves the key that matches name, reading the keys from Consul
+// unless they are cached or watched.
+func (c *ConsulSource) Lookup(name string) (string, bool, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.values == nil || (c.watching == 0 && !time.Now().Before(c.till)) {
+		values, _, err := c.read(context.Background(), 0)
+		if err != nil {
+			return "", false, err
+		}
+		c.values, c.till = values, time.Now().Add(c.ttl)
+	}
+
+	return matchName(c.values, name)
+}
+
+// Watch keeps the keys of c up to date until ctx is done, calling fn with the
+// variables that changed whenever keys under the prefix are set or deleted.
+// Keys read before Watch was called are compared against the first keys it
+// reads; otherwise, the first keys are not reported as changes. Failed queries
+// are retried with increasing delays, up to a minute. Watch returns ctx.Err().
+func (c *ConsulSource) Watch(ctx context.Context, fn func([]Change)) error {
+	c.mu.Lock()
+	c.watching++
+	c.mu.Unlock()
+
+	defer func() {
+		c.mu.Lock()
+		c.watching--
+		c.till = time.Now().Add(c.ttl)
+		c.mu.Unlock()
+	}()
+
+	var index uint64
+	var delay time.Duration
+	for {
+		values, next, err := c.read(ctx, index)
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if err == nil && next == 0 {
+			err = errors.New("Consul response has no index")
+		}
+		if err != nil {
+			delay = retryDelay(delay)
+			if !sleep(ctx, delay) {
+				return ctx.Err()
+			}
+			continue
+		}
+		delay = 0
+
+		// Consul may reset its index, in which case the query must start over.
+		if next < index {
+			next = 0
+		}
+		index = next
+
+		c.mu.Lock()
+		old := c.values
+		c.values = values
+		c.mu.Unlock()
+
+		if old != nil {
+			if changes := diffValues(old, values); len(changes) > 0 {
+				fn(changes)
+			}
+		}
+	}
+}
+
+// Forget removes the cached keys, so that they are read again by the next
+// lookup.
+func (c *ConsulSource) Forget() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.values = nil
+}
+
+// String returns the name of c, as in "consul:app/config".
+func (c *ConsulSource) String() string {
+	return "consul:" + strings.TrimSuffix(c.prefix, "/")
+}
+
+// read reads the keys under the prefix, along with the index of the response.
+// If index is not zero, the read is a blocking query that Consul answers once
+// its index exceeds index, or once the wait time has elapsed. Consul adds up to
+// a sixteenth of the wait time to it, which the timeout of the read allows for.
+func (c *ConsulSource) read(ctx context.Context, index uint64) (map[string]string, uint64, error) {
+	q := url.Values{"recurse": {"true"}}
+	if len(c.datacenter) > 0 {
+		q.Set("dc", c.datacenter)
+	}
+	timeout := c.timeout
+	if index > 0 {
+		q.Set("index", strconv.FormatUint(index, 10))
+		q.Set("wait", strconv.FormatInt(int64(c.wait/time.Second), 10)+"s")
+		timeout += c.wait + c.wait/16
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	endpoint := "/v1/kv/" + c.prefix
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.addr+endpoint+"?"+q.Encode(), nil)
+	if err != nil {
+		return nil, 0, err
+	}
+	if len(c.token) > 0 {
+		req.Header.Set("X-Consul-Token", c.token)
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer resp.Body.Close()
+
+	next, _ := strconv.ParseUint(resp.Header.Get("X-Consul-Index"), 10, 64)
+	values := make(map[string]string)
+	switch {
+	case resp.StatusCode == http.StatusNotFound:
+		return values, next, nil
+	case resp.StatusCode >= 300:
+		return nil, 0, fmt.Errorf("GET %s: %s", endpoint, resp.Status)
+	}
+
+	var pairs []struct {
+		Key   string `json:"Key"`
+		Value []byte `json:"Value"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&pairs); err != nil {
+		return nil, 0, fmt.Errorf("GET %s: invalid response: %w", endpoint, err)
+	}
+	for _, p := range pairs {
+		rel, ok := cutPrefix(p.Key, c.prefix)
+		if !ok || len(rel) == 0 || strings.HasSuffix(rel, "/") {
+			continue
+		}
+		values[strings.ReplaceAll(rel, "/", "_")] = string(p.Value)
+	}
+
+	return values, next, nil
+}
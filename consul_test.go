@@ -0,0 +1,215 @@
+package env_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/christgf/env"
+)
+
+// fakeConsul is a stand-in for the key/value HTTP API of Consul, supporting
+// recursive reads and blocking queries.
+type fakeConsul struct {
+	mu      sync.Mutex
+	kv      map[string]string
+	index   uint64
+	changed chan struct{}
+	reads   int
+	token   string
+}
+
+func newFakeConsul(t *testing.T, kv map[string]string) (*fakeConsul, string) {
+	t.Helper()
+
+	fc := &fakeConsul{kv: kv, index: 7, changed: make(chan struct{})}
+	srv := httptest.NewServer(fc)
+	t.Cleanup(srv.Close)
+
+	return fc, srv.URL
+}
+
+func (fc *fakeConsul) set(key, value string, deleted bool) {
+	fc.mu.Lock()
+	defer fc.mu.Unlock()
+
+	if deleted {
+		delete(fc.kv, key)
+	} else {
+		fc.kv[key] = value
+	}
+	fc.index++
+	close(fc.changed)
+	fc.changed = make(chan struct{})
+}
+
+func (fc *fakeConsul) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	prefix := strings.TrimPrefix(r.URL.Path, "/v1/kv/")
+	if prefix == r.URL.Path || r.URL.Query().Get("recurse") != "true" {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	fc.mu.Lock()
+	fc.reads++
+	fc.token = r.Header.Get("X-Consul-Token")
+	if index, _ := strconv.ParseUint(r.URL.Query().Get("index"), 10, 64); index >= fc.index {
+		wait, _ := time.ParseDuration(r.URL.Query().Get("wait"))
+		changed := fc.changed
+		fc.mu.Unlock()
+		select {
+		case <-changed:
+		case <-time.After(wait):
+		case <-r.Context().Done():
+		}
+		fc.mu.Lock()
+	}
+	defer fc.mu.Unlock()
+
+	type pair struct {
+		Key   string
+		Value []byte
+	}
+	var pairs []pair
+	for key, value := range fc.kv {
+		if strings.HasPrefix(key, prefix) {
+			pairs = append(pairs, pair{Key: key, Value: []byte(value)})
+		}
+	}
+
+	w.Header().Set("X-Consul-Index", strconv.FormatUint(fc.index, 10))
+	if len(pairs) == 0 {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	_ = json.NewEncoder(w).Encode(pairs)
+}
+
+func TestConsulSource(t *testing.T) {
+	fc, addr := newFakeConsul(t, map[string]string{
+		"app/config/PORT":        "9000",
+		"app/config/db/password": "hunter2",
+		"app/config/":            "",
+		"app/configuration/PORT": "1",
+	})
+
+	src, err := env.NewConsulSource(addr, "/app/config/", env.ConsulToken("acl-token"))
+	if err != nil {
+		t.Fatalf("NewConsulSource(): %v", err)
+	}
+
+	var vs env.VarSet
+	vs.SetSources(src)
+	if got, want := vs.Int("PORT", 8080), 9000; got != want {
+		t.Errorf("Int(%q): got %d, want %d", "PORT", got, want)
+	}
+	if got, want := vs.String("DB_PASSWORD", ""), "hunter2"; got != want {
+		t.Errorf("String(%q): got %q, want %q", "DB_PASSWORD", got, want)
+	}
+	if got, want := vs.String("MISSING", "fallback"), "fallback"; got != want {
+		t.Errorf("String(%q): got %q, want %q", "MISSING", got, want)
+	}
+
+	fc.mu.Lock()
+	reads, token := fc.reads, fc.token
+	fc.mu.Unlock()
+	if reads != 1 {
+		t.Errorf("keys read %d times, want 1", reads)
+	}
+	if token != "acl-token" {
+		t.Errorf("got token %q, want %q", token, "acl-token")
+	}
+	if got, want := src.String(), "consul:app/config"; got != want {
+		t.Errorf("String(): got %q, want %q", got, want)
+	}
+}
+
+func TestConsulSource_notFound(t *testing.T) {
+	_, addr := newFakeConsul(t, map[string]string{})
+
+	src, err := env.NewConsulSource(addr, "app/config")
+	if err != nil {
+		t.Fatalf("NewConsulSource(): %v", err)
+	}
+	if _, ok, err := src.Lookup("PORT"); ok || err != nil {
+		t.Errorf("Lookup(): got %v, %v, want not found", ok, err)
+	}
+}
+
+func TestConsulSource_Watch(t *testing.T) {
+	fc, addr := newFakeConsul(t, map[string]string{
+		"app/config/PORT":  "9000",
+		"app/config/DEBUG": "false",
+	})
+
+	src, err := env.NewConsulSource(addr, "app/config", env.ConsulWait(time.Second))
+	if err != nil {
+		t.Fatalf("NewConsulSource(): %v", err)
+	}
+	if _, _, err := src.Lookup("PORT"); err != nil {
+		t.Fatalf("Lookup(): %v", err)
+	}
+
+	changes := make(chan []env.Change)
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() {
+		done <- src.Watch(ctx, func(c []env.Change) { changes <- c })
+	}()
+
+	// Give Watch the time to issue a blocking query.
+	time.Sleep(100 * time.Millisecond)
+	fc.set("app/config/PORT", "9001", false)
+	fc.set("app/config/DEBUG", "", true)
+
+	var got []env.Change
+	for len(got) < 2 {
+		select {
+		case c := <-changes:
+			got = append(got, c...)
+		case <-time.After(5 * time.Second):
+			t.Fatalf("Watch(): got changes %v, want more", got)
+		}
+	}
+	want := []env.Change{
+		{Name: "PORT", Value: "9001"},
+		{Name: "DEBUG", Deleted: true},
+	}
+	if len(got) == 2 && got[0].Name == "DEBUG" {
+		got[0], got[1] = got[1], got[0]
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Watch(): got changes %v, want %v", got, want)
+	}
+
+	if value, ok, err := src.Lookup("PORT"); value != "9001" || !ok || err != nil {
+		t.Errorf("Lookup(%q): got %q, %v, %v, want %q", "PORT", value, ok, err, "9001")
+	}
+
+	cancel()
+	select {
+	case err := <-done:
+		if err != context.Canceled {
+			t.Errorf("Watch(): got %v, want %v", err, context.Canceled)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatalf("Watch(): did not return after cancel")
+	}
+}
+
+func TestConsulSource_timeout(t *testing.T) {
+	srv := newHangingServer(t)
+
+	src, err := env.NewConsulSource(srv.URL, "app", env.ConsulTimeout(50*time.Millisecond))
+	if err != nil {
+		t.Fatalf("NewConsulSource(): %v", err)
+	}
+	checkTimeout(t, src)
+}
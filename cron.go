@@ -0,0 +1,275 @@
+package env
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Schedule is a recurring schedule, such as the schedule of a periodic job.
+type Schedule interface {
+	// Next returns the first activation time of the schedule that is strictly
+	// later than t, in the location of t. If the schedule has no activation time
+	// within five years of t, Next returns the zero time.
+	Next(t time.Time) time.Time
+}
+
+// cronDescriptors maps the descriptors understood by ParseSchedule to the
+// standard expressions they stand for.
+var cronDescriptors = map[string]string{
+	"@yearly":   "0 0 1 1 *",
+	"@annually": "0 0 1 1 *",
+	"@monthly":  "0 0 1 * *",
+	"@weekly":   "0 0 * * 0",
+	"@daily":    "0 0 * * *",
+	"@midnight": "0 0 * * *",
+	"@hourly":   "0 * * * *",
+}
+
+// cronFields describes the five fields of a standard cron expression.
+var cronFields = []struct {
+	name     string
+	min, max int
+	names    []string
+}{
+	{name: "minute", min: 0, max: 59},
+	{name: "hour", min: 0, max: 23},
+	{name: "day of month", min: 1, max: 31},
+	{name: "month", min: 1, max: 12, names: []string{"", "jan", "feb", "mar", "apr", "may", "jun", "jul", "aug", "sep", "oct", "nov", "dec"}},
+	{name: "day of week", min: 0, max: 7, names: []string{"sun", "mon", "tue", "wed", "thu", "fri", "sat"}},
+}
+
+// ParseSchedule parses a standard five-field cron expression, such as
+// "0 3 * * *" or "*/15 9-17 * * mon-fri", or one of the descriptors @yearly
+// (or @annually), @monthly, @weekly, @daily (or @midnight) and @hourly, or
+// "@every <duration>", where the duration is accepted by ParseExtendedDuration.
+//
+// The fields are, in order, minute (0-59), hour (0-23), day of month (1-31),
+// month (1-12 or jan-dec) and day of week (0-7 or sun-sat, where both 0 and 7
+// are Sunday). Each field is either "*" or a comma-separated list of values and
+// ranges, optionally followed by a step such as "/15". As in standard cron, when
+// both day fields are restricted, a day matches if it matches either of them.
+// Times are matched in the location of the time passed to Next, and times that
+// do not exist there because of a daylight saving time transition are skipped.
+func ParseSchedule(spec string) (Schedule, error) {
+	spec = strings.TrimSpace(spec)
+
+	if strings.HasPrefix(spec, "@every ") {
+		d, err := ParseExtendedDuration(strings.TrimSpace(strings.TrimPrefix(spec, "@every ")))
+		if err != nil {
+			return nil, fmt.Errorf("env: invalid schedule %q: %w", spec, err)
+		}
+		if d <= 0 {
+			return nil, fmt.Errorf("env: invalid schedule %q: duration must be positive", spec)
+		}
+		return everySchedule{spec: spec, every: d}, nil
+	}
+
+	expr := spec
+	if strings.HasPrefix(spec, "@") {
+		var ok bool
+		if expr, ok = cronDescriptors[strings.ToLower(spec)]; !ok {
+			return nil, fmt.Errorf("env: invalid schedule %q: unknown descriptor", spec)
+		}
+	}
+
+	fields := strings.Fields(expr)
+	if len(fields) != len(cronFields) {
+		return nil, fmt.Errorf("env: invalid schedule %q: expected %d fields, found %d", spec, len(cronFields), len(fields))
+	}
+
+	var bits [5]uint64
+	for i, f := range cronFields {
+		b, err := parseCronField(fields[i], f.min, f.max, f.names)
+		if err != nil {
+			return nil, fmt.Errorf("env: invalid schedule %q: %s: %w", spec, f.name, err)
+		}
+		bits[i] = b
+	}
+	if bits[4]&(1<<7) != 0 {
+		bits[4] |= 1 << 0
+	}
+
+	return &cronSchedule{
+		spec:    spec,
+		minute:  bits[0],
+		hour:    bits[1],
+		dom:     bits[2],
+		month:   bits[3],
+		dow:     bits[4],
+		domStar: strings.HasPrefix(fields[2], "*"),
+		dowStar: strings.HasPrefix(fields[4], "*"),
+	}, nil
+}
+
+// parseCronField parses a field of a cron expression into a bit set, where bit
+// n is set if the field matches the value n.
+func parseCronField(field string, min, max int, names []string) (uint64, error) {
+	var bits uint64
+	for _, part := range strings.Split(field, ",") {
+		expr, stepStr, hasStep := strings.Cut(part, "/")
+
+		step := 1
+		if hasStep {
+			var err error
+			if step, err = strconv.Atoi(stepStr); err != nil || step <= 0 {
+				return 0, fmt.Errorf("invalid step %q", stepStr)
+			}
+		}
+
+		var lo, hi int
+		switch loStr, hiStr, isRange := strings.Cut(expr, "-"); {
+		case expr == "*":
+			lo, hi = min, max
+		case isRange:
+			var err error
+			if lo, err = parseCronValue(loStr, min, max, names); err != nil {
+				return 0, err
+			}
+			if hi, err = parseCronValue(hiStr, min, max, names); err != nil {
+				return 0, err
+			}
+			if lo > hi {
+				return 0, fmt.Errorf("invalid range %q", expr)
+			}
+		default:
+			var err error
+			if lo, err = parseCronValue(expr, min, max, names); err != nil {
+				return 0, err
+			}
+			hi = lo
+			if hasStep {
+				hi = max
+			}
+		}
+
+		for v := lo; v <= hi; v += step {
+			bits |= 1 << uint(v)
+		}
+	}
+
+	return bits, nil
+}
+
+// parseCronValue parses a number or a name in a field of a cron expression.
+func parseCronValue(s string, min, max int, names []string) (int, error) {
+	for i, name := range names {
+		if len(name) > 0 && strings.EqualFold(name, s) {
+			return i, nil
+		}
+	}
+
+	v, err := strconv.Atoi(s)
+	if err != nil {
+		return 0, fmt.Errorf("invalid value %q", s)
+	}
+	if v < min || v > max {
+		return 0, fmt.Errorf("value %d out of range [%d, %d]", v, min, max)
+	}
+
+	return v, nil
+}
+
+// cronSchedule is a Schedule described by a standard cron expression.
+type cronSchedule struct {
+	spec                          string
+	minute, hour, dom, month, dow uint64
+	domStar, dowStar              bool
+}
+
+// String returns the expression the schedule was parsed from.
+func (s *cronSchedule) String() string {
+	return s.spec
+}
+
+// Next implements Schedule.
+func (s *cronSchedule) Next(t time.Time) time.Time {
+	loc := t.Location()
+	t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), 0, 0, loc).Add(time.Minute)
+
+	for end := t.AddDate(5, 0, 0); t.Before(end); {
+		switch {
+		case s.month&(1<<uint(t.Month())) == 0:
+			t = forward(t, time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, loc))
+		case !s.matchDay(t):
+			t = forward(t, time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, loc))
+		case s.hour&(1<<uint(t.Hour())) == 0:
+			t = forward(t, time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, loc))
+		case s.minute&(1<<uint(t.Minute())) == 0:
+			t = t.Add(time.Minute)
+		default:
+			return t
+		}
+	}
+
+	return time.Time{}
+}
+
+// forward returns next, the start of a month, a day or an hour after t, unless
+// that time was skipped by a daylight saving time transition and time.Date
+// moved it back to t or before it, as happens in zones where the transition is
+// at midnight. In that case, it returns the start of the hour after t instead.
+func forward(t, next time.Time) time.Time {
+	if next.After(t) {
+		return next
+	}
+
+	h := t.Add(time.Hour)
+	if next = time.Date(h.Year(), h.Month(), h.Day(), h.Hour(), 0, 0, 0, h.Location()); next.After(t) {
+		return next
+	}
+
+	return h
+}
+
+// matchDay reports whether the day of t matches the day of month and the day
+// of week fields of the schedule.
+func (s *cronSchedule) matchDay(t time.Time) bool {
+	dom := s.dom&(1<<uint(t.Day())) != 0
+	dow := s.dow&(1<<uint(t.Weekday())) != 0
+	if s.domStar || s.dowStar {
+		return dom && dow
+	}
+
+	return dom || dow
+}
+
+// everySchedule is a Schedule that activates at fixed intervals.
+type everySchedule struct {
+	spec  string
+	every time.Duration
+}
+
+// String returns the descriptor the schedule was parsed from.
+func (s everySchedule) String() string {
+	return s.spec
+}
+
+// Next implements Schedule.
+func (s everySchedule) Next(t time.Time) time.Time {
+	return t.Add(s.every)
+}
+
+// Cron retrieves the value of the environment variable named by the key, parses
+// the value as a cron expression, and returns the resulting Schedule. See
+// ParseSchedule for the accepted formats. If the variable is not present or its
+// value cannot be parsed, fallback is returned.
+func (vs *VarSet) Cron(key string, fallback Schedule) Schedule {
+	return get(vs, key, fallback, ParseSchedule)
+}
+
+// Cron retrieves the value of the environment variable named by the key, parses
+// the value as a cron expression, and returns the resulting Schedule. See
+// ParseSchedule for the accepted formats. If the variable is not present or its
+// value cannot be parsed, fallback is returned.
+func Cron(key string, fallback Schedule) Schedule {
+	return osVarSet.Cron(key, fallback)
+}
+
+// CronVar retrieves the value of the environment variable named by the key,
+// parses the value as a cron expression, and stores the resulting Schedule into
+// the variable pointed by p.
+func CronVar(p *Schedule, key string, fallback Schedule) {
+	*p = osVarSet.Cron(key, fallback)
+}
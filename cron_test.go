@@ -0,0 +1,247 @@
+package env_test
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/christgf/env"
+)
+
+func TestParseSchedule(t *testing.T) {
+	from := time.Date(2024, time.February, 27, 10, 17, 30, 0, time.UTC) // Tuesday
+
+	tests := []struct {
+		spec     string
+		wantNext []time.Time
+		wantErr  bool
+	}{
+		{
+			spec: "0 3 * * *",
+			wantNext: []time.Time{
+				time.Date(2024, time.February, 28, 3, 0, 0, 0, time.UTC),
+				time.Date(2024, time.February, 29, 3, 0, 0, 0, time.UTC),
+			},
+		},
+		{
+			spec: "*/15 * * * *",
+			wantNext: []time.Time{
+				time.Date(2024, time.February, 27, 10, 30, 0, 0, time.UTC),
+				time.Date(2024, time.February, 27, 10, 45, 0, 0, time.UTC),
+				time.Date(2024, time.February, 27, 11, 0, 0, 0, time.UTC),
+			},
+		},
+		{
+			spec: "30 9-17/4 * * mon-fri",
+			wantNext: []time.Time{
+				time.Date(2024, time.February, 27, 13, 30, 0, 0, time.UTC),
+				time.Date(2024, time.February, 27, 17, 30, 0, 0, time.UTC),
+				time.Date(2024, time.February, 28, 9, 30, 0, 0, time.UTC),
+			},
+		},
+		{
+			spec: "0 0 1,15 * *",
+			wantNext: []time.Time{
+				time.Date(2024, time.March, 1, 0, 0, 0, 0, time.UTC),
+				time.Date(2024, time.March, 15, 0, 0, 0, 0, time.UTC),
+			},
+		},
+		{
+			spec: "0 0 13 * 5",
+			wantNext: []time.Time{
+				time.Date(2024, time.March, 1, 0, 0, 0, 0, time.UTC),
+				time.Date(2024, time.March, 8, 0, 0, 0, 0, time.UTC),
+				time.Date(2024, time.March, 13, 0, 0, 0, 0, time.UTC),
+			},
+		},
+		{
+			spec: "0 12 * * 7",
+			wantNext: []time.Time{
+				time.Date(2024, time.March, 3, 12, 0, 0, 0, time.UTC),
+			},
+		},
+		{
+			spec: "0 0 29 feb *",
+			wantNext: []time.Time{
+				time.Date(2024, time.February, 29, 0, 0, 0, 0, time.UTC),
+				time.Date(2028, time.February, 29, 0, 0, 0, 0, time.UTC),
+			},
+		},
+		{
+			spec:     "0 0 30 2 *",
+			wantNext: []time.Time{{}},
+		},
+		{
+			spec: "@daily",
+			wantNext: []time.Time{
+				time.Date(2024, time.February, 28, 0, 0, 0, 0, time.UTC),
+			},
+		},
+		{
+			spec: "@monthly",
+			wantNext: []time.Time{
+				time.Date(2024, time.March, 1, 0, 0, 0, 0, time.UTC),
+			},
+		},
+		{
+			spec: "@weekly",
+			wantNext: []time.Time{
+				time.Date(2024, time.March, 3, 0, 0, 0, 0, time.UTC),
+			},
+		},
+		{
+			spec: "@every 5m",
+			wantNext: []time.Time{
+				time.Date(2024, time.February, 27, 10, 22, 30, 0, time.UTC),
+				time.Date(2024, time.February, 27, 10, 27, 30, 0, time.UTC),
+			},
+		},
+		{
+			spec: "@every 1d",
+			wantNext: []time.Time{
+				time.Date(2024, time.February, 28, 10, 17, 30, 0, time.UTC),
+			},
+		},
+		{spec: "", wantErr: true},
+		{spec: "0 3 * *", wantErr: true},
+		{spec: "0 3 * * * *", wantErr: true},
+		{spec: "60 * * * *", wantErr: true},
+		{spec: "0 24 * * *", wantErr: true},
+		{spec: "0 0 0 * *", wantErr: true},
+		{spec: "0 0 * 13 *", wantErr: true},
+		{spec: "0 0 * * 8", wantErr: true},
+		{spec: "*/0 * * * *", wantErr: true},
+		{spec: "5-1 * * * *", wantErr: true},
+		{spec: "0 0 * foo *", wantErr: true},
+		{spec: "@fortnightly", wantErr: true},
+		{spec: "@every -5m", wantErr: true},
+		{spec: "@every soon", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.spec, func(t *testing.T) {
+			s, err := env.ParseSchedule(tt.spec)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("ParseSchedule(%q): want error", tt.spec)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseSchedule(%q): %v", tt.spec, err)
+			}
+
+			if got, want := fmt.Sprint(s), tt.spec; got != want {
+				t.Errorf("ParseSchedule(%q).String(): got %q, want %q", tt.spec, got, want)
+			}
+
+			next := from
+			for _, want := range tt.wantNext {
+				next = s.Next(next)
+				if !next.Equal(want) {
+					t.Fatalf("ParseSchedule(%q).Next(): got %v, want %v", tt.spec, next, want)
+				}
+			}
+		})
+	}
+}
+
+func TestParseSchedule_Location(t *testing.T) {
+	tests := []struct {
+		name string
+		zone string
+		spec string
+		from [5]int // year, month, day, hour, minute
+		want [5]int
+	}{
+		{name: "local time", zone: "Europe/Athens", spec: "30 3 * * *", from: [5]int{2024, 3, 29, 12, 0}, want: [5]int{2024, 3, 30, 3, 30}},
+		// Clocks in Athens move from 03:00 to 04:00 on the last Sunday of March.
+		{name: "skipped hour", zone: "Europe/Athens", spec: "30 3 * * *", from: [5]int{2024, 3, 30, 12, 0}, want: [5]int{2024, 4, 1, 3, 30}},
+		// Clocks move from 00:00 to 01:00 in the following zones, so that
+		// midnight does not exist on the day of the transition.
+		{name: "skipped midnight Havana", zone: "America/Havana", spec: "0 12 * * 1", from: [5]int{2026, 3, 7, 12, 0}, want: [5]int{2026, 3, 9, 12, 0}},
+		{name: "skipped midnight Santiago", zone: "America/Santiago", spec: "0 12 * * 1", from: [5]int{2026, 9, 5, 12, 0}, want: [5]int{2026, 9, 7, 12, 0}},
+		{name: "skipped midnight Sao Paulo", zone: "America/Sao_Paulo", spec: "0 12 * * 1", from: [5]int{2018, 11, 3, 12, 0}, want: [5]int{2018, 11, 5, 12, 0}},
+		{name: "daily skipped midnight", zone: "America/Havana", spec: "@daily", from: [5]int{2026, 3, 7, 12, 0}, want: [5]int{2026, 3, 9, 0, 0}},
+		{name: "hourly skipped midnight", zone: "America/Havana", spec: "@hourly", from: [5]int{2026, 3, 7, 23, 30}, want: [5]int{2026, 3, 8, 1, 0}},
+		{name: "monthly skipped midnight", zone: "America/Sao_Paulo", spec: "0 12 1 * *", from: [5]int{2018, 10, 31, 12, 0}, want: [5]int{2018, 11, 1, 12, 0}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			loc, err := time.LoadLocation(tt.zone)
+			if err != nil {
+				t.Skipf("time zone database not available: %v", err)
+			}
+			s, err := env.ParseSchedule(tt.spec)
+			if err != nil {
+				t.Fatalf("ParseSchedule(%q): %v", tt.spec, err)
+			}
+
+			from := time.Date(tt.from[0], time.Month(tt.from[1]), tt.from[2], tt.from[3], tt.from[4], 0, 0, loc)
+			want := time.Date(tt.want[0], time.Month(tt.want[1]), tt.want[2], tt.want[3], tt.want[4], 0, 0, loc)
+			if got := s.Next(from); !got.Equal(want) {
+				t.Errorf("ParseSchedule(%q).Next(%v): got %v, want %v", tt.spec, from, got, want)
+			}
+		})
+	}
+}
+
+func TestCron(t *testing.T) {
+	const envKey = "ENV_TEST_CRON"
+
+	fallback, err := env.ParseSchedule("@hourly")
+	if err != nil {
+		t.Fatalf("ParseSchedule(): %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		envValue string
+		wantSpec string
+	}{
+		{
+			name:     "value is empty",
+			envValue: "",
+			wantSpec: "@hourly",
+		},
+		{
+			name:     "value is expression",
+			envValue: "0 3 * * *",
+			wantSpec: "0 3 * * *",
+		},
+		{
+			name:     "value is descriptor",
+			envValue: "@every 5m",
+			wantSpec: "@every 5m",
+		},
+		{
+			name:     "value is foobar",
+			envValue: "foobar",
+			wantSpec: "@hourly",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv(envKey, tt.envValue)
+			if got, want := fmt.Sprint(env.Cron(envKey, fallback)), tt.wantSpec; got != want {
+				t.Errorf("Cron(%q): got %q, want %q", envKey, got, want)
+			}
+
+			var p env.Schedule
+			env.CronVar(&p, envKey, fallback)
+			if got, want := fmt.Sprint(p), tt.wantSpec; got != want {
+				t.Errorf("CronVar(%q): got %q, want %q", envKey, got, want)
+			}
+
+			prefix, key := "ENV_", "TEST_CRON"
+			env.SetPrefix(prefix)
+			if got, want := fmt.Sprint(env.Cron(key, fallback)), tt.wantSpec; got != want {
+				t.Errorf("Cron(Prefix=%q, Key=%q): got %q, want %q", prefix, key, got, want)
+			}
+
+			env.SetPrefix("")
+			if got, want := fmt.Sprint(env.Cron(key, fallback)), "@hourly"; got != want {
+				t.Errorf("Cron(Prefix=%q, Key=%q): got %q, want %q", prefix, key, got, want)
+			}
+		})
+	}
+}
@@ -0,0 +1,272 @@
+package env
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Origins of the values of a Record.
+const (
+	originEnv     = "env"
+	originDefault = "default"
+)
+
+// Record describes a variable that was read from a VarSet, as reported by
+// Records and Dump.
+type Record struct {
+	// Key is the key the variable was read with.
+	Key string
+	// Value is the effective value of the variable, formatted as text.
+	Value string
+	// Default is the fallback value of the variable, formatted as text. It is
+	// empty for getters without a fallback, such as OptionalString.
+	Default string
+	// Origin is "env" if Value was retrieved from the environment, the name
+	// of the source it was retrieved from, or "default" if it is the fallback
+	// value.
+	Origin string
+	// Overridden reports whether Value was retrieved from the environment or a
+	// source and differs from Default.
+	Overridden bool
+	// Sensitive reports whether the key was marked as sensitive, in which case
+	// Value and Default are "[REDACTED]".
+	Sensitive bool
+	// Err is the error that occurred while retrieving or parsing the value of
+	// the environment variable, if any. Parse errors that quote a URL with a
+	// password report the URL with its password redacted.
+	Err error
+}
+
+// DumpFormat is the output format of Dump.
+type DumpFormat int
+
+// Formats supported by Dump.
+const (
+	// DumpText writes one line per variable, with space-separated fields, as
+	// in: PORT="9000" origin=env default="8080" overridden=true
+	DumpText DumpFormat = iota
+	// DumpJSON writes a JSON array with one object per variable.
+	DumpJSON
+)
+
+// get retrieves the value of the environment variable named by the key, parses
+// it using parse, and records the read for Dump. If the variable is not present
+// or its value cannot be parsed, fallback is returned.
+func get[T any](vs *VarSet, key string, fallback T, parse func(string) (T, error)) T {
+	value, origin, err := vs.lookup(key)
+	if len(origin) == 0 {
+		vs.record(key, fallback, fallback, originDefault, err)
+		return fallback
+	}
+
+	res, err := parse(value)
+	if err != nil {
+		vs.record(key, fallback, fallback, originDefault, parseErr(err, value))
+		return fallback
+	}
+
+	vs.record(key, res, fallback, origin, nil)
+	return res
+}
+
+// record records that key was read with the value and fallback provided. Values
+// are formatted using formatValue; nil values are recorded as empty.
+func (vs *VarSet) record(key string, value, fallback any, origin string, err error) {
+	key = plainKey(key)
+	r := Record{
+		Key:     key,
+		Value:   formatValue(value),
+		Default: formatValue(fallback),
+		Origin:  origin,
+		Err:     err,
+	}
+	r.Overridden = origin != originDefault && r.Value != r.Default
+
+	vs.mu.Lock()
+	defer vs.mu.Unlock()
+
+	if vs.reads == nil {
+		vs.reads = make(map[string]Record)
+	}
+	vs.reads[key] = r
+}
+
+// parseErr returns the error to record for value, which could not be parsed
+// because of err. Errors often quote the value, so if value is a URL with a
+// password, such as "postgres://user:pass@db/app", and the message of err quotes
+// it or the password, an error that quotes the value with its password redacted
+// is returned instead, without the details of err.
+func parseErr(err error, value string) error {
+	redactedValue, password := redactPassword(value)
+	if len(password) == 0 {
+		return err
+	}
+	if msg := err.Error(); !strings.Contains(msg, value) && !strings.Contains(msg, password) {
+		return err
+	}
+
+	return fmt.Errorf("invalid value %q", redactedValue)
+}
+
+// redactPassword returns s with the password of the user information of a URL
+// replaced by "xxxxx", as url.URL.Redacted does, along with the password. It
+// does not require s to be a valid URL. If s has no password, it is returned as
+// it is.
+func redactPassword(s string) (string, string) {
+	i := strings.Index(s, "://")
+	if i < 0 {
+		return s, ""
+	}
+	authority := s[i+3:]
+	if end := strings.IndexAny(authority, "/?#"); end >= 0 {
+		authority = authority[:end]
+	}
+	at := strings.LastIndexByte(authority, '@')
+	if at < 0 {
+		return s, ""
+	}
+	colon := strings.IndexByte(authority[:at], ':')
+	if colon < 0 || colon == at-1 {
+		return s, ""
+	}
+
+	start, end := i+3+colon+1, i+3+at
+	return s[:start] + "xxxxx" + s[end:], s[start:end]
+}
+
+// recordDefault records that the fallback value of key was computed to be
+// value, keeping the error recorded for key, if any.
+func (vs *VarSet) recordDefault(key string, value any) {
+	key = plainKey(key)
+
+	vs.mu.Lock()
+	defer vs.mu.Unlock()
+
+	r, ok := vs.reads[key]
+	if !ok {
+		return
+	}
+	r.Value = formatValue(value)
+	r.Default = r.Value
+	vs.reads[key] = r
+}
+
+// formatValue formats v for a Record. Passwords in URLs are redacted, and byte
+// slices are reported by length only, since they often hold keys.
+func formatValue(v any) string {
+	switch v := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case []byte:
+		return fmt.Sprintf("%d bytes", len(v))
+	case *url.URL:
+		if v == nil {
+			return ""
+		}
+		return v.Redacted()
+	case fmt.Stringer:
+		return v.String()
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// Records returns a record for every key read from this VarSet so far, sorted
+// by key. If a key was read more than once, the last read is reported. Values
+// of sensitive keys are redacted.
+func (vs *VarSet) Records() []Record {
+	vs.mu.Lock()
+	defer vs.mu.Unlock()
+
+	records := make([]Record, 0, len(vs.reads))
+	for key, r := range vs.reads {
+		if vs.sensitive[key] {
+			r.Sensitive = true
+			r.Value, r.Default = redacted, redacted
+			if r.Err != nil {
+				r.Err = errors.New("invalid value")
+			}
+		}
+		records = append(records, r)
+	}
+	sort.Slice(records, func(i, j int) bool {
+		return records[i].Key < records[j].Key
+	})
+
+	return records
+}
+
+// Dump writes every key read from this VarSet so far to w, along with its
+// effective value, its default, where the value came from, and whether the
+// default was overridden, using the format provided. Values of sensitive keys
+// are redacted, so the output can be logged at startup. For example:
+//
+//	port := vs.Int("PORT", 8080)
+//	password := vs.Secret("DB_PASSWORD")
+//	...
+//	vs.Dump(os.Stderr, env.DumpText)
+func (vs *VarSet) Dump(w io.Writer, format DumpFormat) error {
+	records := vs.Records()
+
+	switch format {
+	case DumpText:
+		var b strings.Builder
+		for _, r := range records {
+			fmt.Fprintf(&b, "%s=%s origin=%s default=%s overridden=%t", r.Key, strconv.Quote(r.Value), r.Origin, strconv.Quote(r.Default), r.Overridden)
+			if r.Err != nil {
+				fmt.Fprintf(&b, " error=%s", strconv.Quote(r.Err.Error()))
+			}
+			b.WriteByte('\n')
+		}
+		_, err := io.WriteString(w, b.String())
+		return err
+	case DumpJSON:
+		type jsonRecord struct {
+			Key        string `json:"key"`
+			Value      string `json:"value"`
+			Default    string `json:"default"`
+			Origin     string `json:"origin"`
+			Overridden bool   `json:"overridden"`
+			Sensitive  bool   `json:"sensitive,omitempty"`
+			Error      string `json:"error,omitempty"`
+		}
+		out := make([]jsonRecord, 0, len(records))
+		for _, r := range records {
+			jr := jsonRecord{
+				Key:        r.Key,
+				Value:      r.Value,
+				Default:    r.Default,
+				Origin:     r.Origin,
+				Overridden: r.Overridden,
+				Sensitive:  r.Sensitive,
+			}
+			if r.Err != nil {
+				jr.Error = r.Err.Error()
+			}
+			out = append(out, jr)
+		}
+		return json.NewEncoder(w).Encode(out)
+	default:
+		return fmt.Errorf("env: unknown dump format %d", format)
+	}
+}
+
+// Records returns a record for every key read from the default VarSet so far,
+// sorted by key. See VarSet.Records.
+func Records() []Record {
+	return osVarSet.Records()
+}
+
+// Dump writes every key read from the default VarSet so far to w, using the
+// format provided. See VarSet.Dump.
+func Dump(w io.Writer, format DumpFormat) error {
+	return osVarSet.Dump(w, format)
+}
@@ -0,0 +1,43 @@
+//go:build go1.21
+
+package env
+
+import "log/slog"
+
+// LogAttrs returns an attribute for every key read from this VarSet so far, in
+// the order of Records. Each attribute is a group holding the value, default,
+// origin and overridden fields of the record, and its error if any. Values of
+// sensitive keys are redacted. For example:
+//
+//	logger.LogAttrs(ctx, slog.LevelInfo, "configuration", vs.LogAttrs()...)
+func (vs *VarSet) LogAttrs() []slog.Attr {
+	records := vs.Records()
+
+	attrs := make([]slog.Attr, 0, len(records))
+	for _, r := range records {
+		fields := []any{
+			slog.String("value", r.Value),
+			slog.String("default", r.Default),
+			slog.String("origin", r.Origin),
+			slog.Bool("overridden", r.Overridden),
+		}
+		if r.Err != nil {
+			fields = append(fields, slog.String("error", r.Err.Error()))
+		}
+		attrs = append(attrs, slog.Group(r.Key, fields...))
+	}
+
+	return attrs
+}
+
+// LogValue implements slog.LogValuer, so that a VarSet is logged as a group of
+// the attributes returned by LogAttrs.
+func (vs *VarSet) LogValue() slog.Value {
+	return slog.GroupValue(vs.LogAttrs()...)
+}
+
+// LogAttrs returns an attribute for every key read from the default VarSet so
+// far. See VarSet.LogAttrs.
+func LogAttrs() []slog.Attr {
+	return osVarSet.LogAttrs()
+}
@@ -0,0 +1,38 @@
+//go:build go1.21
+
+package env_test
+
+import (
+	"bytes"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"github.com/christgf/env"
+)
+
+func TestVarSet_LogAttrs(t *testing.T) {
+	t.Setenv("ENV_TEST_PORT", "9000")
+	t.Setenv("ENV_TEST_DB_PASSWORD", "hunter2")
+
+	var vs env.VarSet
+	vs.SetPrefix("ENV_TEST_")
+	vs.Int("PORT", 8080)
+	vs.Secret("DB_PASSWORD")
+
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewJSONHandler(&buf, &slog.HandlerOptions{
+		ReplaceAttr: func(groups []string, a slog.Attr) slog.Attr {
+			if len(groups) == 0 && a.Key == slog.TimeKey {
+				return slog.Attr{}
+			}
+			return a
+		},
+	}))
+	logger.Info("configuration", "env", &vs)
+
+	want := `{"level":"INFO","msg":"configuration","env":{"DB_PASSWORD":{"value":"[REDACTED]","default":"[REDACTED]","origin":"env","overridden":true},"PORT":{"value":"9000","default":"8080","origin":"env","overridden":true}}}`
+	if got := strings.TrimSpace(buf.String()); got != want {
+		t.Errorf("Info(): got %s, want %s", got, want)
+	}
+}
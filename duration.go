@@ -0,0 +1,186 @@
+package env
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"time"
+)
+
+// Lengths of the extra units understood by ParseExtendedDuration.
+const (
+	Day  = 24 * time.Hour
+	Week = 7 * Day
+)
+
+// ParseExtendedDuration parses a duration string. It accepts every string that
+// time.ParseDuration does, and additionally:
+//
+//   - the units "d" for days and "w" for weeks, as in "7d", "1.5d" or "1w2d12h";
+//   - ISO 8601 durations of the form PnWnDTnHnMnS, as in "P7D", "PT30M" or
+//     "P1DT2H30M". Years and months are rejected, since their length varies.
+//
+// A day is always 24 hours long and a week 7 days long.
+func ParseExtendedDuration(s string) (time.Duration, error) {
+	orig := s
+
+	neg := false
+	if len(s) > 0 && (s[0] == '-' || s[0] == '+') {
+		neg = s[0] == '-'
+		s = s[1:]
+	}
+
+	var d time.Duration
+	var err error
+	switch {
+	case len(s) > 0 && (s[0] == 'P' || s[0] == 'p'):
+		d, err = parseISODuration(s[1:])
+	case strings.ContainsAny(s, "dw"):
+		d, err = parseUnitDuration(s)
+	default:
+		return time.ParseDuration(orig)
+	}
+	if err != nil {
+		return 0, fmt.Errorf("env: invalid duration %q: %w", orig, err)
+	}
+
+	if neg {
+		d = -d
+	}
+
+	return d, nil
+}
+
+// parseUnitDuration parses an unsigned sequence of decimal numbers, each with
+// an optional fraction and a unit suffix, such as "1w2d12h".
+func parseUnitDuration(s string) (time.Duration, error) {
+	if len(s) == 0 {
+		return 0, fmt.Errorf("empty duration")
+	}
+
+	var total time.Duration
+	for len(s) > 0 {
+		i := 0
+		for i < len(s) && ('0' <= s[i] && s[i] <= '9' || s[i] == '.') {
+			i++
+		}
+		j := i
+		for j < len(s) && !('0' <= s[j] && s[j] <= '9' || s[j] == '.') {
+			j++
+		}
+		if i == 0 || j == i {
+			return 0, fmt.Errorf("expected a number followed by a unit")
+		}
+
+		d, err := unitDuration(s[:i], s[i:j])
+		if err != nil {
+			return 0, err
+		}
+		if total > math.MaxInt64-d {
+			return 0, fmt.Errorf("duration out of range")
+		}
+		total += d
+		s = s[j:]
+	}
+
+	return total, nil
+}
+
+// unitDuration returns the duration of number units, where number is an
+// unsigned decimal number and unit is one of the units of time.ParseDuration,
+// or "d" or "w".
+func unitDuration(number, unit string) (time.Duration, error) {
+	var scale time.Duration
+	switch unit {
+	case "d":
+		scale = 24
+	case "w":
+		scale = 24 * 7
+	default:
+		return time.ParseDuration(number + unit)
+	}
+
+	hours, err := time.ParseDuration(number + "h")
+	if err != nil {
+		return 0, err
+	}
+	if hours > math.MaxInt64/scale {
+		return 0, fmt.Errorf("duration out of range")
+	}
+
+	return hours * scale, nil
+}
+
+// parseISODuration parses an ISO 8601 duration without its leading 'P', such as
+// "1DT2H" or "T30M".
+func parseISODuration(s string) (time.Duration, error) {
+	date, clock, hasTime := strings.Cut(strings.ToUpper(s), "T")
+	if len(date) == 0 && len(clock) == 0 {
+		return 0, fmt.Errorf("empty duration")
+	}
+	if hasTime && len(clock) == 0 {
+		return 0, fmt.Errorf("expected a time component after 'T'")
+	}
+
+	var b strings.Builder
+	for _, part := range []struct {
+		s     string
+		order string
+		units map[byte]string
+	}{
+		{s: date, order: "YMWD", units: map[byte]string{'W': "w", 'D': "d"}},
+		{s: clock, order: "HMS", units: map[byte]string{'H': "h", 'M': "m", 'S': "s"}},
+	} {
+		last := -1
+		for s := part.s; len(s) > 0; {
+			i := strings.IndexFunc(s, func(r rune) bool { return !('0' <= r && r <= '9' || r == '.' || r == ',') })
+			if i <= 0 {
+				return 0, fmt.Errorf("expected a number followed by a designator")
+			}
+
+			pos := strings.IndexByte(part.order, s[i])
+			if pos < 0 {
+				return 0, fmt.Errorf("unknown designator %q", s[i])
+			}
+			if pos <= last {
+				return 0, fmt.Errorf("designator %q out of order", s[i])
+			}
+			last = pos
+
+			unit, ok := part.units[s[i]]
+			if !ok {
+				return 0, fmt.Errorf("years and months have no fixed duration")
+			}
+			b.WriteString(strings.ReplaceAll(s[:i], ",", "."))
+			b.WriteString(unit)
+			s = s[i+1:]
+		}
+	}
+
+	return parseUnitDuration(b.String())
+}
+
+// ExtendedDuration retrieves the value of the environment variable named by the
+// key, parses the value as a duration that may use days, weeks or the ISO 8601
+// format, and returns the result. See ParseExtendedDuration for the accepted
+// formats. If the variable is not present or its value cannot be parsed,
+// fallback is returned.
+func (vs *VarSet) ExtendedDuration(key string, fallback time.Duration) time.Duration {
+	return get(vs, key, fallback, ParseExtendedDuration)
+}
+
+// ExtendedDuration retrieves the value of the environment variable named by the
+// key, parses the value as a duration that may use days, weeks or the ISO 8601
+// format, and returns the result. See ParseExtendedDuration for the accepted
+// formats. If the variable is not present or its value cannot be parsed,
+// fallback is returned.
+func ExtendedDuration(key string, fallback time.Duration) time.Duration {
+	return osVarSet.ExtendedDuration(key, fallback)
+}
+
+// ExtendedDurationVar retrieves the value of the environment variable named by
+// the key, parses the value as a duration that may use days, weeks or the ISO
+// 8601 format, and stores the result into the variable pointed by p.
+func ExtendedDurationVar(p *time.Duration, key string, fallback time.Duration) {
+	*p = osVarSet.ExtendedDuration(key, fallback)
+}
@@ -0,0 +1,157 @@
+package env_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/christgf/env"
+)
+
+func TestExtendedDuration(t *testing.T) {
+	const envKey = "ENV_TEST_EXTENDED_DURATION"
+
+	if got, want := env.ExtendedDuration(envKey, env.Week), env.Week; got != want {
+		t.Errorf("ExtendedDuration(%q): got %v, want %v", envKey, got, want)
+	}
+
+	tests := []struct {
+		name      string
+		envValue  string
+		fallback  time.Duration
+		wantValue time.Duration
+	}{
+		{
+			name:      "value is empty",
+			envValue:  "",
+			fallback:  time.Hour,
+			wantValue: time.Hour,
+		},
+		{
+			name:      "value is zero",
+			envValue:  "0",
+			fallback:  time.Hour,
+			wantValue: 0,
+		},
+		{
+			name:      "value is standard",
+			envValue:  "1h30m",
+			fallback:  time.Hour,
+			wantValue: 90 * time.Minute,
+		},
+		{
+			name:      "value is in days",
+			envValue:  "7d",
+			fallback:  time.Hour,
+			wantValue: 168 * time.Hour,
+		},
+		{
+			name:      "value is fractional days",
+			envValue:  "1.5d",
+			fallback:  time.Hour,
+			wantValue: 36 * time.Hour,
+		},
+		{
+			name:      "value is mixed",
+			envValue:  "1w2d12h30m",
+			fallback:  time.Hour,
+			wantValue: 9*24*time.Hour + 12*time.Hour + 30*time.Minute,
+		},
+		{
+			name:      "value is negative",
+			envValue:  "-2d",
+			fallback:  time.Hour,
+			wantValue: -48 * time.Hour,
+		},
+		{
+			name:      "value is ISO 8601 days",
+			envValue:  "P7D",
+			fallback:  time.Hour,
+			wantValue: 168 * time.Hour,
+		},
+		{
+			name:      "value is ISO 8601 date and time",
+			envValue:  "P1DT2H",
+			fallback:  time.Hour,
+			wantValue: 26 * time.Hour,
+		},
+		{
+			name:      "value is ISO 8601 time",
+			envValue:  "PT1H30M15.5S",
+			fallback:  time.Hour,
+			wantValue: 90*time.Minute + 15500*time.Millisecond,
+		},
+		{
+			name:      "value is ISO 8601 weeks with comma",
+			envValue:  "P0,5W",
+			fallback:  time.Hour,
+			wantValue: 84 * time.Hour,
+		},
+		{
+			name:      "value is ISO 8601 with years",
+			envValue:  "P1Y",
+			fallback:  time.Hour,
+			wantValue: time.Hour,
+		},
+		{
+			name:      "value is ISO 8601 with months",
+			envValue:  "P1M",
+			fallback:  time.Hour,
+			wantValue: time.Hour,
+		},
+		{
+			name:      "value is ISO 8601 out of order",
+			envValue:  "PT1S1H",
+			fallback:  time.Hour,
+			wantValue: time.Hour,
+		},
+		{
+			name:      "value is ISO 8601 without components",
+			envValue:  "PT",
+			fallback:  time.Hour,
+			wantValue: time.Hour,
+		},
+		{
+			name:      "value has no unit",
+			envValue:  "7",
+			fallback:  time.Hour,
+			wantValue: time.Hour,
+		},
+		{
+			name:      "value overflows",
+			envValue:  "1000000w",
+			fallback:  time.Hour,
+			wantValue: time.Hour,
+		},
+		{
+			name:      "value is foobar",
+			envValue:  "foobar",
+			fallback:  time.Hour,
+			wantValue: time.Hour,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv(envKey, tt.envValue)
+			if got, want := env.ExtendedDuration(envKey, tt.fallback), tt.wantValue; got != want {
+				t.Errorf("ExtendedDuration(%q): got %v, want %v", envKey, got, want)
+			}
+
+			var p time.Duration
+			env.ExtendedDurationVar(&p, envKey, tt.fallback)
+			if got, want := p, tt.wantValue; got != want {
+				t.Errorf("ExtendedDurationVar(%q): got %v, want %v", envKey, got, want)
+			}
+
+			prefix, key := "ENV_", "TEST_EXTENDED_DURATION"
+			env.SetPrefix(prefix)
+			if got, want := env.ExtendedDuration(key, tt.fallback), tt.wantValue; got != want {
+				t.Errorf("ExtendedDuration(Prefix=%q, Key=%q): got %v, want %v", prefix, key, got, want)
+			}
+
+			env.SetPrefix("")
+			if got, want := env.ExtendedDuration(key, tt.fallback), tt.fallback; got != want {
+				t.Errorf("ExtendedDuration(Prefix=%q, Key=%q): got %v, want %v", prefix, key, got, want)
+			}
+		})
+	}
+}
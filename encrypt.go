@@ -0,0 +1,221 @@
+package env
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+)
+
+// encPrefix is the prefix of values encrypted with a Keyring.
+const encPrefix = "enc:v1:"
+
+// ErrDecrypt is returned when an encrypted value cannot be decrypted, because it
+// was encrypted with a different key or it has been tampered with.
+var ErrDecrypt = errors.New("env: value cannot be decrypted: wrong key or tampered data")
+
+// Keyring holds the keys used to encrypt and decrypt values of the form
+// "enc:v1:<key id>:<data>", so that secrets can be committed to .env files.
+// Values are encrypted with AES-GCM, and the key id is authenticated along with
+// the data. Each key is identified by an id, so that keys can be rotated: values
+// are encrypted with the primary key, and decrypted with the key they name.
+// The zero value is an empty Keyring ready to use, and a Keyring is safe for
+// concurrent use.
+type Keyring struct {
+	mu      sync.RWMutex
+	keys    map[string]cipher.AEAD
+	primary string
+}
+
+// NewKeyring returns an empty Keyring.
+func NewKeyring() *Keyring {
+	return &Keyring{keys: make(map[string]cipher.AEAD)}
+}
+
+// GenerateKey returns a new random 256-bit key, suitable for AddKey.
+func GenerateKey() ([]byte, error) {
+	key := make([]byte, 32)
+	if _, err := rand.Read(key); err != nil {
+		return nil, fmt.Errorf("env: %w", err)
+	}
+
+	return key, nil
+}
+
+// AddKey adds key to k under id. The key must be 16, 24 or 32 bytes long, to
+// select AES-128, AES-192 or AES-256, and id must not be empty or contain ':'.
+// The first key added becomes the primary key.
+func (k *Keyring) AddKey(id string, key []byte) error {
+	if len(id) == 0 || strings.Contains(id, ":") {
+		return fmt.Errorf("env: invalid key id %q", id)
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return fmt.Errorf("env: key %s: %w", id, err)
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return fmt.Errorf("env: key %s: %w", id, err)
+	}
+
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	if k.keys == nil {
+		k.keys = make(map[string]cipher.AEAD)
+	}
+	k.keys[id] = aead
+	if len(k.primary) == 0 {
+		k.primary = id
+	}
+
+	return nil
+}
+
+// AddKeyFile adds the key stored in the file named by path to k under id. The
+// contents of the file are decoded using ParseBinary, without a single trailing
+// newline, so the key may be stored as "base64:..." or "hex:...".
+func (k *Keyring) AddKeyFile(id, path string) error {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("env: key %s: %w", id, err)
+	}
+	key, err := ParseBinary(trimNewline(string(b)))
+	if err != nil {
+		return fmt.Errorf("env: key %s: %w", id, err)
+	}
+
+	return k.AddKey(id, key)
+}
+
+// AddKeyEnv adds the key stored in the environment variable named by name to k
+// under id. The value is decoded using ParseBinary, so the key may be stored as
+// "base64:..." or "hex:...".
+func (k *Keyring) AddKeyEnv(id, name string) error {
+	value, ok := os.LookupEnv(name)
+	if !ok {
+		return fmt.Errorf("env: key %s: variable %s is not set", id, name)
+	}
+	key, err := ParseBinary(value)
+	if err != nil {
+		return fmt.Errorf("env: key %s: %w", id, err)
+	}
+
+	return k.AddKey(id, key)
+}
+
+// SetPrimary makes the key added under id the primary key of k, the one used
+// by Encrypt.
+func (k *Keyring) SetPrimary(id string) error {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	if _, ok := k.keys[id]; !ok {
+		return fmt.Errorf("env: unknown key id %q", id)
+	}
+	k.primary = id
+
+	return nil
+}
+
+// Encrypt encrypts plaintext with the primary key of k, and returns a value of
+// the form "enc:v1:<key id>:<data>" that can be stored in the environment.
+func (k *Keyring) Encrypt(plaintext string) (string, error) {
+	k.mu.RLock()
+	id, aead := k.primary, k.keys[k.primary]
+	k.mu.RUnlock()
+
+	if aead == nil {
+		return "", errors.New("env: keyring has no keys")
+	}
+
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", fmt.Errorf("env: %w", err)
+	}
+	header := encPrefix + id
+	data := aead.Seal(nonce, nonce, []byte(plaintext), []byte(header))
+
+	return header + ":" + base64.RawURLEncoding.EncodeToString(data), nil
+}
+
+// Decrypt decrypts a value returned by Encrypt, using the key it names. If the
+// value was encrypted with a different key or it has been tampered with, the
+// error is ErrDecrypt.
+func (k *Keyring) Decrypt(value string) (string, error) {
+	rest, ok := cutPrefix(value, encPrefix)
+	if !ok {
+		return "", errors.New("env: value is not encrypted")
+	}
+	i := strings.IndexByte(rest, ':')
+	if i < 0 {
+		return "", errors.New("env: malformed encrypted value")
+	}
+	id := rest[:i]
+	data, err := base64.RawURLEncoding.DecodeString(rest[i+1:])
+	if err != nil {
+		return "", errors.New("env: malformed encrypted value")
+	}
+
+	k.mu.RLock()
+	aead := k.keys[id]
+	k.mu.RUnlock()
+
+	if aead == nil {
+		return "", fmt.Errorf("env: unknown key id %q", id)
+	}
+	if len(data) < aead.NonceSize() {
+		return "", ErrDecrypt
+	}
+	nonce, ciphertext := data[:aead.NonceSize()], data[aead.NonceSize():]
+	plaintext, err := aead.Open(nil, nonce, ciphertext, []byte(encPrefix+id))
+	if err != nil {
+		return "", ErrDecrypt
+	}
+
+	return string(plaintext), nil
+}
+
+// SetKeyring makes this VarSet decrypt values of the form "enc:v1:..." using k,
+// before they are parsed by String, Bool, Int, et al. Values are decrypted after
+// they are expanded and resolved, and keys with encrypted values are marked as
+// sensitive. If a value cannot be decrypted, getters return their fallback and
+// the error is reported by Err. Values are not decrypted by default, so unless a
+// keyring is set, values of the form "enc:v1:..." are used as they are. Use nil
+// to reset.
+func (vs *VarSet) SetKeyring(k *Keyring) {
+	vs.mu.Lock()
+	defer vs.mu.Unlock()
+
+	vs.keyring = k
+}
+
+// decrypt decrypts value if it is encrypted and a keyring is set, marking key
+// as sensitive, and returns it unchanged otherwise.
+func (vs *VarSet) decrypt(key, value string) (string, error) {
+	if !strings.HasPrefix(value, encPrefix) {
+		return value, nil
+	}
+
+	vs.mu.Lock()
+	k := vs.keyring
+	vs.mu.Unlock()
+
+	if k == nil {
+		return value, nil
+	}
+	vs.MarkSensitive(key)
+
+	return k.Decrypt(value)
+}
+
+// SetKeyring makes the default VarSet decrypt values of the form "enc:v1:..."
+// using k. See VarSet.SetKeyring.
+func SetKeyring(k *Keyring) {
+	osVarSet.SetKeyring(k)
+}
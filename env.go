@@ -5,12 +5,26 @@ import (
 	"fmt"
 	"os"
 	"strconv"
+	"sync"
 	"time"
 )
 
 // VarSet represents a set of environment variables managed as key-value pairs.
 type VarSet struct {
-	prefix string
+	prefix     string
+	mapper     KeyMapper
+	ignoreCase bool
+	expand     bool
+	parseBool  func(string) (bool, error)
+	intFormat  IntFormat
+
+	mu        sync.Mutex
+	sensitive map[string]bool
+	reads     map[string]Record
+	resolvers map[string]Resolver
+	keyring   *Keyring
+	sources   []Source
+	err       error
 }
 
 // SetPrefix makes this VarSet prepend the value of prefix to every key before it
@@ -25,138 +39,340 @@ func (vs *VarSet) Prefix() string {
 	return vs.prefix
 }
 
-// lookup applies the prefix for this VarSet to the key provided and attempts to
-// retrieve the value of the corresponding environment variable. If the variable
-// is present in the environment the value is returned and the boolean is true.
-// Otherwise, the returned value will be empty and the boolean will be false.
-func (vs *VarSet) lookup(key string) (string, bool) {
-	if len(vs.prefix) > 0 {
+// lookup attempts to retrieve the value of the variable named by the key,
+// expanding any references in it if this VarSet is configured to do so,
+// resolving it if it refers to a value kept elsewhere, such as a file, and
+// decrypting it if it is encrypted. If the variable is present in the
+// environment or in one of the sources of this VarSet, the value is returned
+// along with its origin, which is "env" or the name of the source. Otherwise,
+// the returned value and origin will be empty. If the value cannot be
+// retrieved, expanded, resolved or decrypted, the error is returned and kept
+// for Err, and the origin is empty.
+func (vs *VarSet) lookup(key string) (string, string, error) {
+	value, origin, err := vs.lookupValue(key)
+	if err != nil {
+		vs.setErr(err)
+		return "", "", err
+	}
+
+	return value, origin, nil
+}
+
+// lookupValue implements lookup, without keeping the error. If the variable is
+// present but its value cannot be expanded, resolved or decrypted, the value is
+// returned as it was retrieved, along with its origin and the error.
+func (vs *VarSet) lookupValue(key string) (string, string, error) {
+	raw, origin, err := vs.lookupVar(key)
+	if err != nil || len(origin) == 0 {
+		return "", "", err
+	}
+
+	value, err := vs.processValue(key, raw, vs.expand, nil, nil)
+	if err != nil {
+		return raw, origin, err
+	}
+
+	return value, origin, nil
+}
+
+// processValue expands the value of the variable named by the key if expand is
+// true, and then resolves and decrypts it. The key is marked as sensitive if
+// its value is encrypted or references a sensitive variable, and x, if not nil,
+// records whether it is sensitive. The stack holds the keys being expanded, to
+// detect cycles.
+func (vs *VarSet) processValue(key, value string, expand bool, stack []string, x *expansion) (string, error) {
+	var err error
+	if expand {
+		refs := &expansion{}
+		value, err = vs.expandString(value, append(stack, key), refs)
+		if err != nil {
+			return "", err
+		}
+		if refs.sensitive {
+			vs.MarkSensitive(key)
+		}
+	}
+	value, err = vs.resolve(value)
+	if err != nil {
+		return "", err
+	}
+	value, err = vs.decrypt(key, value)
+	if err != nil {
+		return "", err
+	}
+
+	if x != nil && vs.IsSensitive(key) {
+		x.sensitive = true
+	}
+	return value, nil
+}
+
+// setErr keeps err for Err, unless an error was kept already.
+func (vs *VarSet) setErr(err error) {
+	vs.mu.Lock()
+	defer vs.mu.Unlock()
+
+	if vs.err == nil {
+		vs.err = err
+	}
+}
+
+// Err returns the first error that occurred while retrieving a value from this
+// VarSet, if any, such as a reference of the form ${VAR:?message} to a variable
+// that is not set, or a source that failed. Getters such as String, Bool and
+// Int return their fallback in that case, so a program that depends on such
+// values should check Err once it has read its configuration. For example:
+//
+//	vs.SetExpand(true)
+//	dsn := vs.String("DB_URL", "")
+//	if err := vs.Err(); err != nil {
+//		log.Fatal(err)
+//	}
+//
+// Values that are present but cannot be parsed are not reported by Err; they
+// are reported by Records and Dump.
+func (vs *VarSet) Err() error {
+	vs.mu.Lock()
+	defer vs.mu.Unlock()
+
+	return vs.err
+}
+
+// lookupVar retrieves the value of the variable named by the key from the
+// environment, or else from the sources of this VarSet in order, and reports
+// its origin. If the variable is not present, the returned value and origin
+// will be empty. Keys retrieved from a SensitiveSource are marked as sensitive.
+func (vs *VarSet) lookupVar(key string) (string, string, error) {
+	value, ok, err := vs.lookupEnv(key)
+	if err != nil {
+		return "", "", err
+	}
+	if ok {
+		return value, originEnv, nil
+	}
+
+	vs.mu.Lock()
+	sources := vs.sources
+	vs.mu.Unlock()
+
+	if len(sources) == 0 {
+		return "", "", nil
+	}
+	name := vs.varName(key)
+	for _, src := range sources {
+		value, ok, err := src.Lookup(name)
+		if err != nil {
+			return "", "", fmt.Errorf("env: %s: %s: %w", name, sourceName(src), err)
+		}
+		if ok {
+			if isSensitive(src) {
+				vs.MarkSensitive(key)
+			}
+			return value, sourceName(src), nil
+		}
+	}
+
+	return "", "", nil
+}
+
+// varName applies the key mapper and the prefix for this VarSet to the key
+// provided, and returns the name of the corresponding variable. Keys marked
+// using NoPrefix are not prefixed.
+func (vs *VarSet) varName(key string) string {
+	key, noPrefix := splitNoPrefix(key)
+	if vs.mapper != nil {
+		key = vs.mapper(key)
+	}
+	if len(vs.prefix) > 0 && !noPrefix {
 		key = fmt.Sprintf("%s%s", vs.prefix, key)
 	}
 
-	return os.LookupEnv(key)
+	return key
+}
+
+// lookupEnv applies the key mapper and the prefix for this VarSet to the key
+// provided and attempts to retrieve the value of the corresponding environment
+// variable. If the variable is present in the environment the value is returned
+// and the boolean is true. Otherwise, the returned value will be empty and the
+// boolean will be false. Keys marked using NoPrefix are looked up without the
+// prefix. If the key is matched case-insensitively by more than one variable,
+// an error is returned.
+func (vs *VarSet) lookupEnv(key string) (string, bool, error) {
+	key = vs.varName(key)
+
+	value, ok := os.LookupEnv(key)
+	if !ok && vs.ignoreCase {
+		return lookupFold(key)
+	}
+
+	return value, ok, nil
 }
 
 // Lookup retrieves the value of the environment variable named by the key. If
 // the variable is present in the environment the value is returned and the
 // boolean is true. Otherwise, the returned value will be empty and the boolean
-// will be false.
+// will be false. If the variable is present but its value cannot be expanded,
+// resolved or decrypted, the value is returned as it is in the environment, the
+// boolean is true, and the error is reported by Err.
 func (vs *VarSet) Lookup(key string) (string, bool) {
-	return vs.lookup(key)
+	value, origin, err := vs.lookupValue(key)
+	if err != nil {
+		vs.setErr(err)
+	}
+	if len(origin) == 0 {
+		vs.record(key, nil, nil, originDefault, err)
+		return "", false
+	}
+
+	vs.record(key, value, nil, origin, err)
+	return value, true
 }
 
 // String retrieves the value of the environment variable named by the key. If
 // the variable is present in the environment, its value (which may be empty) is
 // returned, otherwise fallback is returned.
 func (vs *VarSet) String(key string, fallback string) string {
-	value, ok := vs.lookup(key)
-	if !ok {
-		return fallback
-	}
-
-	return value
+	return get(vs, key, fallback, func(s string) (string, error) {
+		return s, nil
+	})
 }
 
 // Bool retrieves the value of the environment variable named by the key, parses
 // the value as a boolean, and returns the result. If the variable is not
-// present or its value cannot be parsed, fallback is returned.
+// present or its value cannot be parsed, fallback is returned. See
+// SetBoolParser for the accepted values.
 func (vs *VarSet) Bool(key string, fallback bool) bool {
-	value, ok := vs.lookup(key)
-	if !ok {
-		return fallback
-	}
-
-	res, err := strconv.ParseBool(value)
-	if err != nil {
-		return fallback
-	}
-
-	return res
+	return get(vs, key, fallback, vs.boolParser())
 }
 
 // Int retrieves the value of the environment variable named by the key, parses
 // the value as an integer, and returns the result. If the variable is not
 // present or its value cannot be parsed, fallback is returned.
+// See SetIntFormat for the accepted syntax.
 func (vs *VarSet) Int(key string, fallback int) int {
-	value, ok := vs.lookup(key)
-	if !ok {
-		return fallback
-	}
-
-	res, err := strconv.ParseInt(value, 10, strconv.IntSize)
-	if err != nil {
-		return fallback
-	}
-
-	return int(res)
+	return get(vs, key, fallback, func(s string) (int, error) {
+		res, err := ParseInt(s, vs.intFormat, strconv.IntSize)
+		return int(res), err
+	})
+}
+
+// Int8 retrieves the value of the environment variable named by the key, parses
+// the value as an 8-bit integer, and returns the result. If the variable is not
+// present or its value cannot be parsed or does not fit in an int8, fallback is
+// returned. See SetIntFormat for the accepted syntax.
+func (vs *VarSet) Int8(key string, fallback int8) int8 {
+	return get(vs, key, fallback, func(s string) (int8, error) {
+		res, err := ParseInt(s, vs.intFormat, 8)
+		return int8(res), err
+	})
+}
+
+// Int16 retrieves the value of the environment variable named by the key,
+// parses the value as a 16-bit integer, and returns the result. If the variable
+// is not present or its value cannot be parsed or does not fit in an int16,
+// fallback is returned. See SetIntFormat for the accepted syntax.
+func (vs *VarSet) Int16(key string, fallback int16) int16 {
+	return get(vs, key, fallback, func(s string) (int16, error) {
+		res, err := ParseInt(s, vs.intFormat, 16)
+		return int16(res), err
+	})
+}
+
+// Int32 retrieves the value of the environment variable named by the key,
+// parses the value as a 32-bit integer, and returns the result. If the variable
+// is not present or its value cannot be parsed or does not fit in an int32,
+// fallback is returned. See SetIntFormat for the accepted syntax.
+func (vs *VarSet) Int32(key string, fallback int32) int32 {
+	return get(vs, key, fallback, func(s string) (int32, error) {
+		res, err := ParseInt(s, vs.intFormat, 32)
+		return int32(res), err
+	})
 }
 
 // Int64 retrieves the value of the environment variable named by the key, parses
 // the value as a 64-bit integer, and returns the result. If the variable is not
 // present or its value cannot be parsed, fallback is returned.
+// See SetIntFormat for the accepted syntax.
 func (vs *VarSet) Int64(key string, fallback int64) int64 {
-	value, ok := vs.lookup(key)
-	if !ok {
-		return fallback
-	}
-
-	res, err := strconv.ParseInt(value, 10, 64)
-	if err != nil {
-		return fallback
-	}
-
-	return res
+	return get(vs, key, fallback, func(s string) (int64, error) {
+		return ParseInt(s, vs.intFormat, 64)
+	})
 }
 
 // Uint retrieves the value of the environment variable named by the key, parses
 // the value as an unsigned integer, and returns the result. If the variable is
 // not present or its value cannot be parsed, fallback is returned.
+// See SetIntFormat for the accepted syntax.
 func (vs *VarSet) Uint(key string, fallback uint) uint {
-	value, ok := vs.lookup(key)
-	if !ok {
-		return fallback
-	}
-
-	res, err := strconv.ParseUint(value, 10, strconv.IntSize)
-	if err != nil {
-		return fallback
-	}
-
-	return uint(res)
+	return get(vs, key, fallback, func(s string) (uint, error) {
+		res, err := ParseUint(s, vs.intFormat, strconv.IntSize)
+		return uint(res), err
+	})
+}
+
+// Uint8 retrieves the value of the environment variable named by the key,
+// parses the value as an unsigned 8-bit integer, and returns the result. If the
+// variable is not present or its value cannot be parsed or does not fit in a
+// uint8, fallback is returned. See SetIntFormat for the accepted syntax.
+func (vs *VarSet) Uint8(key string, fallback uint8) uint8 {
+	return get(vs, key, fallback, func(s string) (uint8, error) {
+		res, err := ParseUint(s, vs.intFormat, 8)
+		return uint8(res), err
+	})
+}
+
+// Uint16 retrieves the value of the environment variable named by the key,
+// parses the value as an unsigned 16-bit integer, and returns the result. If
+// the variable is not present or its value cannot be parsed or does not fit in
+// a uint16, fallback is returned. See SetIntFormat for the accepted syntax.
+func (vs *VarSet) Uint16(key string, fallback uint16) uint16 {
+	return get(vs, key, fallback, func(s string) (uint16, error) {
+		res, err := ParseUint(s, vs.intFormat, 16)
+		return uint16(res), err
+	})
+}
+
+// Uint32 retrieves the value of the environment variable named by the key,
+// parses the value as an unsigned 32-bit integer, and returns the result. If
+// the variable is not present or its value cannot be parsed or does not fit in
+// a uint32, fallback is returned. See SetIntFormat for the accepted syntax.
+func (vs *VarSet) Uint32(key string, fallback uint32) uint32 {
+	return get(vs, key, fallback, func(s string) (uint32, error) {
+		res, err := ParseUint(s, vs.intFormat, 32)
+		return uint32(res), err
+	})
 }
 
 // Uint64 retrieves the value of the environment variable named by the key,
 // parses the value as an unsigned 64-bit integer, and returns the result. If the
 // variable is not present or its value cannot be parsed, fallback is returned.
+// See SetIntFormat for the accepted syntax.
 func (vs *VarSet) Uint64(key string, fallback uint64) uint64 {
-	value, ok := vs.lookup(key)
-	if !ok {
-		return fallback
-	}
-
-	res, err := strconv.ParseUint(value, 10, 64)
-	if err != nil {
-		return fallback
-	}
+	return get(vs, key, fallback, func(s string) (uint64, error) {
+		return ParseUint(s, vs.intFormat, 64)
+	})
+}
 
-	return res
+// Uintptr retrieves the value of the environment variable named by the key,
+// parses the value as an unsigned integer large enough to hold a pointer, and
+// returns the result. If the variable is not present or its value cannot be
+// parsed or does not fit in a uintptr, fallback is returned. See SetIntFormat
+// for the accepted syntax.
+func (vs *VarSet) Uintptr(key string, fallback uintptr) uintptr {
+	return get(vs, key, fallback, func(s string) (uintptr, error) {
+		res, err := ParseUint(s, vs.intFormat, uintptrSize)
+		return uintptr(res), err
+	})
 }
 
 // Float32 retrieves the value of the environment variable named by the key,
 // parses the value as a floating-point number, and returns the result. If the
 // variable is not present or its value cannot be parsed, fallback is returned.
 func (vs *VarSet) Float32(key string, fallback float32) float32 {
-	value, ok := vs.lookup(key)
-	if !ok {
-		return fallback
-	}
-
-	res, err := strconv.ParseFloat(value, 32)
-	if err != nil {
-		return fallback
-	}
-
-	return float32(res)
+	return get(vs, key, fallback, func(s string) (float32, error) {
+		res, err := strconv.ParseFloat(s, 32)
+		return float32(res), err
+	})
 }
 
 // Float64 retrieves the value of the environment variable named by the key,
@@ -164,36 +380,21 @@ func (vs *VarSet) Float32(key string, fallback float32) float32 {
 // the variable is not present or its value cannot be parsed, fallback is
 // returned.
 func (vs *VarSet) Float64(key string, fallback float64) float64 {
-	value, ok := vs.lookup(key)
-	if !ok {
-		return fallback
-	}
-
-	res, err := strconv.ParseFloat(value, 64)
-	if err != nil {
-		return fallback
-	}
-
-	return res
+	return get(vs, key, fallback, func(s string) (float64, error) {
+		return strconv.ParseFloat(s, 64)
+	})
 }
 
 // Duration retrieves the value of the environment variable named by the key,
 // parses the value as time.Duration, and returns the result. If the variable is
 // not present or its value cannot be parsed, fallback is returned.
 func (vs *VarSet) Duration(key string, fallback time.Duration) time.Duration {
-	value, ok := vs.lookup(key)
-	if !ok {
-		return fallback
-	}
-
-	res, err := time.ParseDuration(value)
-	if err != nil {
-		return fallback
-	}
-
-	return res
+	return get(vs, key, fallback, time.ParseDuration)
 }
 
+// uintptrSize is the size of a uintptr in bits.
+const uintptrSize = 32 << (^uintptr(0) >> 63)
+
 // osVarSet is the default VarSet. Top-level functions such as String, StringVar,
 // Bool, etc. are wrappers for the methods of osVarSet.
 var osVarSet = &VarSet{prefix: ""}
@@ -218,6 +419,12 @@ func Lookup(key string) (string, bool) {
 	return osVarSet.Lookup(key)
 }
 
+// Err returns the first error that occurred while retrieving a value from the
+// default VarSet, if any. See VarSet.Err.
+func Err() error {
+	return osVarSet.Err()
+}
+
 // String retrieves the value of the environment variable named by the key. If
 // the variable is present in the environment, its value (which may be empty) is
 // returned, otherwise fallback is returned.
@@ -239,6 +446,30 @@ func Int(key string, fallback int) int {
 	return osVarSet.Int(key, fallback)
 }
 
+// Int8 retrieves the value of the environment variable named by the key, parses
+// the value as an 8-bit integer, and returns the result. If the variable is not
+// present or its value cannot be parsed or does not fit in an int8, fallback is
+// returned.
+func Int8(key string, fallback int8) int8 {
+	return osVarSet.Int8(key, fallback)
+}
+
+// Int16 retrieves the value of the environment variable named by the key,
+// parses the value as a 16-bit integer, and returns the result. If the variable
+// is not present or its value cannot be parsed or does not fit in an int16,
+// fallback is returned.
+func Int16(key string, fallback int16) int16 {
+	return osVarSet.Int16(key, fallback)
+}
+
+// Int32 retrieves the value of the environment variable named by the key,
+// parses the value as a 32-bit integer, and returns the result. If the variable
+// is not present or its value cannot be parsed or does not fit in an int32,
+// fallback is returned.
+func Int32(key string, fallback int32) int32 {
+	return osVarSet.Int32(key, fallback)
+}
+
 // Int64 retrieves the value of the environment variable named by the key, parses
 // the value as a 64-bit integer, and returns the result. If the variable is not
 // present or its value cannot be parsed, fallback is returned.
@@ -253,6 +484,30 @@ func Uint(key string, fallback uint) uint {
 	return osVarSet.Uint(key, fallback)
 }
 
+// Uint8 retrieves the value of the environment variable named by the key,
+// parses the value as an unsigned 8-bit integer, and returns the result. If the
+// variable is not present or its value cannot be parsed or does not fit in a
+// uint8, fallback is returned.
+func Uint8(key string, fallback uint8) uint8 {
+	return osVarSet.Uint8(key, fallback)
+}
+
+// Uint16 retrieves the value of the environment variable named by the key,
+// parses the value as an unsigned 16-bit integer, and returns the result. If
+// the variable is not present or its value cannot be parsed or does not fit in
+// a uint16, fallback is returned.
+func Uint16(key string, fallback uint16) uint16 {
+	return osVarSet.Uint16(key, fallback)
+}
+
+// Uint32 retrieves the value of the environment variable named by the key,
+// parses the value as an unsigned 32-bit integer, and returns the result. If
+// the variable is not present or its value cannot be parsed or does not fit in
+// a uint32, fallback is returned.
+func Uint32(key string, fallback uint32) uint32 {
+	return osVarSet.Uint32(key, fallback)
+}
+
 // Uint64 retrieves the value of the environment variable named by the key,
 // parses the value as an unsigned 64-bit integer, and returns the result. If the
 // variable is not present or its value cannot be parsed, fallback is returned.
@@ -260,6 +515,14 @@ func Uint64(key string, fallback uint64) uint64 {
 	return osVarSet.Uint64(key, fallback)
 }
 
+// Uintptr retrieves the value of the environment variable named by the key,
+// parses the value as an unsigned integer large enough to hold a pointer, and
+// returns the result. If the variable is not present or its value cannot be
+// parsed or does not fit in a uintptr, fallback is returned.
+func Uintptr(key string, fallback uintptr) uintptr {
+	return osVarSet.Uintptr(key, fallback)
+}
+
 // Float32 retrieves the value of the environment variable named by the key,
 // parses the value as a floating-point number, and returns the result. If the
 // variable is not present or its value cannot be parsed, fallback is returned.
@@ -302,6 +565,27 @@ func IntVar(p *int, key string, fallback int) {
 	*p = osVarSet.Int(key, fallback)
 }
 
+// Int8Var retrieves the value of the environment variable named by the key,
+// parses the value as an 8-bit integer, and stores the result into the variable
+// pointed by p.
+func Int8Var(p *int8, key string, fallback int8) {
+	*p = osVarSet.Int8(key, fallback)
+}
+
+// Int16Var retrieves the value of the environment variable named by the key,
+// parses the value as a 16-bit integer, and stores the result into the variable
+// pointed by p.
+func Int16Var(p *int16, key string, fallback int16) {
+	*p = osVarSet.Int16(key, fallback)
+}
+
+// Int32Var retrieves the value of the environment variable named by the key,
+// parses the value as a 32-bit integer, and stores the result into the variable
+// pointed by p.
+func Int32Var(p *int32, key string, fallback int32) {
+	*p = osVarSet.Int32(key, fallback)
+}
+
 // Int64Var retrieves the value of the environment variable named by the key,
 // parses the value as a 64-bit integer, and stores the result into the variable
 // pointed by p.
@@ -316,6 +600,27 @@ func UintVar(p *uint, key string, fallback uint) {
 	*p = osVarSet.Uint(key, fallback)
 }
 
+// Uint8Var retrieves the value of the environment variable named by the key,
+// parses the value as an unsigned 8-bit integer, and stores the result into the
+// variable pointed by p.
+func Uint8Var(p *uint8, key string, fallback uint8) {
+	*p = osVarSet.Uint8(key, fallback)
+}
+
+// Uint16Var retrieves the value of the environment variable named by the key,
+// parses the value as an unsigned 16-bit integer, and stores the result into
+// the variable pointed by p.
+func Uint16Var(p *uint16, key string, fallback uint16) {
+	*p = osVarSet.Uint16(key, fallback)
+}
+
+// Uint32Var retrieves the value of the environment variable named by the key,
+// parses the value as an unsigned 32-bit integer, and stores the result into
+// the variable pointed by p.
+func Uint32Var(p *uint32, key string, fallback uint32) {
+	*p = osVarSet.Uint32(key, fallback)
+}
+
 // Uint64Var retrieves the value of the environment variable named by the key,
 // parses the value as an unsigned 64-bit integer, and stores the result into the
 // variable pointed by p.
@@ -323,6 +628,13 @@ func Uint64Var(p *uint64, key string, fallback uint64) {
 	*p = osVarSet.Uint64(key, fallback)
 }
 
+// UintptrVar retrieves the value of the environment variable named by the key,
+// parses the value as an unsigned integer large enough to hold a pointer, and
+// stores the result into the variable pointed by p.
+func UintptrVar(p *uintptr, key string, fallback uintptr) {
+	*p = osVarSet.Uintptr(key, fallback)
+}
+
 // Float32Var retrieves the value of the environment variable named by the key,
 // parses the value as a floating-point number, and stores the result into the
 // variable pointed by p.
@@ -240,6 +240,255 @@ func TestInt(t *testing.T) {
 	}
 }
 
+func TestInt8(t *testing.T) {
+	const envKey = "ENV_TEST_INT8"
+
+	if got, want := env.Int8(envKey, 42), int8(42); got != want {
+		t.Errorf("Int8(%q): got %d, want %d", envKey, got, want)
+	}
+
+	tests := []struct {
+		name      string
+		envValue  string
+		fallback  int8
+		wantValue int8
+	}{
+		{
+			name:      "value is empty",
+			envValue:  "",
+			fallback:  42,
+			wantValue: 42,
+		},
+		{
+			name:      "value is zero",
+			envValue:  "0",
+			fallback:  42,
+			wantValue: 0,
+		},
+		{
+			name:      "value is maximum",
+			envValue:  "127",
+			fallback:  42,
+			wantValue: 127,
+		},
+		{
+			name:      "value is minimum",
+			envValue:  "-128",
+			fallback:  42,
+			wantValue: -128,
+		},
+		{
+			name:      "value overflows",
+			envValue:  "128",
+			fallback:  42,
+			wantValue: 42,
+		},
+		{
+			name:      "value underflows",
+			envValue:  "-129",
+			fallback:  42,
+			wantValue: 42,
+		},
+		{
+			name:      "value is foobar",
+			envValue:  "foobar",
+			fallback:  42,
+			wantValue: 42,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv(envKey, tt.envValue)
+			if got, want := env.Int8(envKey, tt.fallback), tt.wantValue; got != want {
+				t.Errorf("Int8(%q): got %d, want %d", envKey, got, want)
+			}
+
+			var p int8
+			env.Int8Var(&p, envKey, tt.fallback)
+			if got, want := p, tt.wantValue; got != want {
+				t.Errorf("Int8Var(%q): got %d, want %d", envKey, got, want)
+			}
+
+			prefix, key := "ENV_", "TEST_INT8"
+			env.SetPrefix(prefix)
+			if got, want := env.Int8(key, tt.fallback), tt.wantValue; got != want {
+				t.Errorf("Int8(Prefix=%q, Key=%q): got %d, want %d", prefix, key, got, want)
+			}
+
+			env.SetPrefix("")
+			if got, want := env.Int8(key, tt.fallback), tt.fallback; got != want {
+				t.Errorf("Int8(Prefix=%q, Key=%q): got %d, want %d", prefix, key, got, want)
+			}
+		})
+	}
+}
+
+func TestInt16(t *testing.T) {
+	const envKey = "ENV_TEST_INT16"
+
+	if got, want := env.Int16(envKey, 42), int16(42); got != want {
+		t.Errorf("Int16(%q): got %d, want %d", envKey, got, want)
+	}
+
+	tests := []struct {
+		name      string
+		envValue  string
+		fallback  int16
+		wantValue int16
+	}{
+		{
+			name:      "value is empty",
+			envValue:  "",
+			fallback:  42,
+			wantValue: 42,
+		},
+		{
+			name:      "value is zero",
+			envValue:  "0",
+			fallback:  42,
+			wantValue: 0,
+		},
+		{
+			name:      "value is maximum",
+			envValue:  "32767",
+			fallback:  42,
+			wantValue: 32767,
+		},
+		{
+			name:      "value is minimum",
+			envValue:  "-32768",
+			fallback:  42,
+			wantValue: -32768,
+		},
+		{
+			name:      "value overflows",
+			envValue:  "32768",
+			fallback:  42,
+			wantValue: 42,
+		},
+		{
+			name:      "value underflows",
+			envValue:  "-32769",
+			fallback:  42,
+			wantValue: 42,
+		},
+		{
+			name:      "value is foobar",
+			envValue:  "foobar",
+			fallback:  42,
+			wantValue: 42,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv(envKey, tt.envValue)
+			if got, want := env.Int16(envKey, tt.fallback), tt.wantValue; got != want {
+				t.Errorf("Int16(%q): got %d, want %d", envKey, got, want)
+			}
+
+			var p int16
+			env.Int16Var(&p, envKey, tt.fallback)
+			if got, want := p, tt.wantValue; got != want {
+				t.Errorf("Int16Var(%q): got %d, want %d", envKey, got, want)
+			}
+
+			prefix, key := "ENV_", "TEST_INT16"
+			env.SetPrefix(prefix)
+			if got, want := env.Int16(key, tt.fallback), tt.wantValue; got != want {
+				t.Errorf("Int16(Prefix=%q, Key=%q): got %d, want %d", prefix, key, got, want)
+			}
+
+			env.SetPrefix("")
+			if got, want := env.Int16(key, tt.fallback), tt.fallback; got != want {
+				t.Errorf("Int16(Prefix=%q, Key=%q): got %d, want %d", prefix, key, got, want)
+			}
+		})
+	}
+}
+
+func TestInt32(t *testing.T) {
+	const envKey = "ENV_TEST_INT32"
+
+	if got, want := env.Int32(envKey, 42), int32(42); got != want {
+		t.Errorf("Int32(%q): got %d, want %d", envKey, got, want)
+	}
+
+	tests := []struct {
+		name      string
+		envValue  string
+		fallback  int32
+		wantValue int32
+	}{
+		{
+			name:      "value is empty",
+			envValue:  "",
+			fallback:  42,
+			wantValue: 42,
+		},
+		{
+			name:      "value is zero",
+			envValue:  "0",
+			fallback:  42,
+			wantValue: 0,
+		},
+		{
+			name:      "value is maximum",
+			envValue:  "2147483647",
+			fallback:  42,
+			wantValue: 2147483647,
+		},
+		{
+			name:      "value is minimum",
+			envValue:  "-2147483648",
+			fallback:  42,
+			wantValue: -2147483648,
+		},
+		{
+			name:      "value overflows",
+			envValue:  "2147483648",
+			fallback:  42,
+			wantValue: 42,
+		},
+		{
+			name:      "value underflows",
+			envValue:  "-2147483649",
+			fallback:  42,
+			wantValue: 42,
+		},
+		{
+			name:      "value is foobar",
+			envValue:  "foobar",
+			fallback:  42,
+			wantValue: 42,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv(envKey, tt.envValue)
+			if got, want := env.Int32(envKey, tt.fallback), tt.wantValue; got != want {
+				t.Errorf("Int32(%q): got %d, want %d", envKey, got, want)
+			}
+
+			var p int32
+			env.Int32Var(&p, envKey, tt.fallback)
+			if got, want := p, tt.wantValue; got != want {
+				t.Errorf("Int32Var(%q): got %d, want %d", envKey, got, want)
+			}
+
+			prefix, key := "ENV_", "TEST_INT32"
+			env.SetPrefix(prefix)
+			if got, want := env.Int32(key, tt.fallback), tt.wantValue; got != want {
+				t.Errorf("Int32(Prefix=%q, Key=%q): got %d, want %d", prefix, key, got, want)
+			}
+
+			env.SetPrefix("")
+			if got, want := env.Int32(key, tt.fallback), tt.fallback; got != want {
+				t.Errorf("Int32(Prefix=%q, Key=%q): got %d, want %d", prefix, key, got, want)
+			}
+		})
+	}
+}
+
 func TestInt64(t *testing.T) {
 	const envKey = "ENV_TEST_INT64"
 
@@ -394,6 +643,237 @@ func TestUint(t *testing.T) {
 	}
 }
 
+func TestUint8(t *testing.T) {
+	const envKey = "ENV_TEST_UINT8"
+
+	if got, want := env.Uint8(envKey, 42), uint8(42); got != want {
+		t.Errorf("Uint8(%q): got %d, want %d", envKey, got, want)
+	}
+
+	tests := []struct {
+		name      string
+		envValue  string
+		fallback  uint8
+		wantValue uint8
+	}{
+		{
+			name:      "value is empty",
+			envValue:  "",
+			fallback:  42,
+			wantValue: 42,
+		},
+		{
+			name:      "value is zero",
+			envValue:  "0",
+			fallback:  42,
+			wantValue: 0,
+		},
+		{
+			name:      "value is maximum",
+			envValue:  "255",
+			fallback:  42,
+			wantValue: 255,
+		},
+		{
+			name:      "value overflows",
+			envValue:  "256",
+			fallback:  42,
+			wantValue: 42,
+		},
+		{
+			name:      "value is negative",
+			envValue:  "-1",
+			fallback:  42,
+			wantValue: 42,
+		},
+		{
+			name:      "value is foobar",
+			envValue:  "foobar",
+			fallback:  42,
+			wantValue: 42,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv(envKey, tt.envValue)
+			if got, want := env.Uint8(envKey, tt.fallback), tt.wantValue; got != want {
+				t.Errorf("Uint8(%q): got %d, want %d", envKey, got, want)
+			}
+
+			var p uint8
+			env.Uint8Var(&p, envKey, tt.fallback)
+			if got, want := p, tt.wantValue; got != want {
+				t.Errorf("Uint8Var(%q): got %d, want %d", envKey, got, want)
+			}
+
+			prefix, key := "ENV_", "TEST_UINT8"
+			env.SetPrefix(prefix)
+			if got, want := env.Uint8(key, tt.fallback), tt.wantValue; got != want {
+				t.Errorf("Uint8(Prefix=%q, Key=%q): got %d, want %d", prefix, key, got, want)
+			}
+
+			env.SetPrefix("")
+			if got, want := env.Uint8(key, tt.fallback), tt.fallback; got != want {
+				t.Errorf("Uint8(Prefix=%q, Key=%q): got %d, want %d", prefix, key, got, want)
+			}
+		})
+	}
+}
+
+func TestUint16(t *testing.T) {
+	const envKey = "ENV_TEST_UINT16"
+
+	if got, want := env.Uint16(envKey, 42), uint16(42); got != want {
+		t.Errorf("Uint16(%q): got %d, want %d", envKey, got, want)
+	}
+
+	tests := []struct {
+		name      string
+		envValue  string
+		fallback  uint16
+		wantValue uint16
+	}{
+		{
+			name:      "value is empty",
+			envValue:  "",
+			fallback:  42,
+			wantValue: 42,
+		},
+		{
+			name:      "value is zero",
+			envValue:  "0",
+			fallback:  42,
+			wantValue: 0,
+		},
+		{
+			name:      "value is maximum",
+			envValue:  "65535",
+			fallback:  42,
+			wantValue: 65535,
+		},
+		{
+			name:      "value overflows",
+			envValue:  "65536",
+			fallback:  42,
+			wantValue: 42,
+		},
+		{
+			name:      "value is negative",
+			envValue:  "-1",
+			fallback:  42,
+			wantValue: 42,
+		},
+		{
+			name:      "value is foobar",
+			envValue:  "foobar",
+			fallback:  42,
+			wantValue: 42,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv(envKey, tt.envValue)
+			if got, want := env.Uint16(envKey, tt.fallback), tt.wantValue; got != want {
+				t.Errorf("Uint16(%q): got %d, want %d", envKey, got, want)
+			}
+
+			var p uint16
+			env.Uint16Var(&p, envKey, tt.fallback)
+			if got, want := p, tt.wantValue; got != want {
+				t.Errorf("Uint16Var(%q): got %d, want %d", envKey, got, want)
+			}
+
+			prefix, key := "ENV_", "TEST_UINT16"
+			env.SetPrefix(prefix)
+			if got, want := env.Uint16(key, tt.fallback), tt.wantValue; got != want {
+				t.Errorf("Uint16(Prefix=%q, Key=%q): got %d, want %d", prefix, key, got, want)
+			}
+
+			env.SetPrefix("")
+			if got, want := env.Uint16(key, tt.fallback), tt.fallback; got != want {
+				t.Errorf("Uint16(Prefix=%q, Key=%q): got %d, want %d", prefix, key, got, want)
+			}
+		})
+	}
+}
+
+func TestUint32(t *testing.T) {
+	const envKey = "ENV_TEST_UINT32"
+
+	if got, want := env.Uint32(envKey, 42), uint32(42); got != want {
+		t.Errorf("Uint32(%q): got %d, want %d", envKey, got, want)
+	}
+
+	tests := []struct {
+		name      string
+		envValue  string
+		fallback  uint32
+		wantValue uint32
+	}{
+		{
+			name:      "value is empty",
+			envValue:  "",
+			fallback:  42,
+			wantValue: 42,
+		},
+		{
+			name:      "value is zero",
+			envValue:  "0",
+			fallback:  42,
+			wantValue: 0,
+		},
+		{
+			name:      "value is maximum",
+			envValue:  "4294967295",
+			fallback:  42,
+			wantValue: 4294967295,
+		},
+		{
+			name:      "value overflows",
+			envValue:  "4294967296",
+			fallback:  42,
+			wantValue: 42,
+		},
+		{
+			name:      "value is negative",
+			envValue:  "-1",
+			fallback:  42,
+			wantValue: 42,
+		},
+		{
+			name:      "value is foobar",
+			envValue:  "foobar",
+			fallback:  42,
+			wantValue: 42,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv(envKey, tt.envValue)
+			if got, want := env.Uint32(envKey, tt.fallback), tt.wantValue; got != want {
+				t.Errorf("Uint32(%q): got %d, want %d", envKey, got, want)
+			}
+
+			var p uint32
+			env.Uint32Var(&p, envKey, tt.fallback)
+			if got, want := p, tt.wantValue; got != want {
+				t.Errorf("Uint32Var(%q): got %d, want %d", envKey, got, want)
+			}
+
+			prefix, key := "ENV_", "TEST_UINT32"
+			env.SetPrefix(prefix)
+			if got, want := env.Uint32(key, tt.fallback), tt.wantValue; got != want {
+				t.Errorf("Uint32(Prefix=%q, Key=%q): got %d, want %d", prefix, key, got, want)
+			}
+
+			env.SetPrefix("")
+			if got, want := env.Uint32(key, tt.fallback), tt.fallback; got != want {
+				t.Errorf("Uint32(Prefix=%q, Key=%q): got %d, want %d", prefix, key, got, want)
+			}
+		})
+	}
+}
+
 func TestUint64(t *testing.T) {
 	const envKey = "ENV_TEST_UINT64"
 
@@ -477,6 +957,83 @@ func TestUint64(t *testing.T) {
 	}
 }
 
+func TestUintptr(t *testing.T) {
+	const envKey = "ENV_TEST_UINTPTR"
+
+	if got, want := env.Uintptr(envKey, 42), uintptr(42); got != want {
+		t.Errorf("Uintptr(%q): got %d, want %d", envKey, got, want)
+	}
+
+	tests := []struct {
+		name      string
+		envValue  string
+		fallback  uintptr
+		wantValue uintptr
+	}{
+		{
+			name:      "value is empty",
+			envValue:  "",
+			fallback:  42,
+			wantValue: 42,
+		},
+		{
+			name:      "value is zero",
+			envValue:  "0",
+			fallback:  42,
+			wantValue: 0,
+		},
+		{
+			name:      "value is positive",
+			envValue:  "4096",
+			fallback:  42,
+			wantValue: 4096,
+		},
+		{
+			name:      "value is negative",
+			envValue:  "-1",
+			fallback:  42,
+			wantValue: 42,
+		},
+		{
+			name:      "value overflows",
+			envValue:  "18446744073709551616",
+			fallback:  42,
+			wantValue: 42,
+		},
+		{
+			name:      "value is foobar",
+			envValue:  "foobar",
+			fallback:  42,
+			wantValue: 42,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv(envKey, tt.envValue)
+			if got, want := env.Uintptr(envKey, tt.fallback), tt.wantValue; got != want {
+				t.Errorf("Uintptr(%q): got %d, want %d", envKey, got, want)
+			}
+
+			var p uintptr
+			env.UintptrVar(&p, envKey, tt.fallback)
+			if got, want := p, tt.wantValue; got != want {
+				t.Errorf("UintptrVar(%q): got %d, want %d", envKey, got, want)
+			}
+
+			prefix, key := "ENV_", "TEST_UINTPTR"
+			env.SetPrefix(prefix)
+			if got, want := env.Uintptr(key, tt.fallback), tt.wantValue; got != want {
+				t.Errorf("Uintptr(Prefix=%q, Key=%q): got %d, want %d", prefix, key, got, want)
+			}
+
+			env.SetPrefix("")
+			if got, want := env.Uintptr(key, tt.fallback), tt.fallback; got != want {
+				t.Errorf("Uintptr(Prefix=%q, Key=%q): got %d, want %d", prefix, key, got, want)
+			}
+		})
+	}
+}
+
 func TestFloat32(t *testing.T) {
 	const envKey = "ENV_TEST_FLOAT32"
 
@@ -0,0 +1,227 @@
+package env
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// SetExpand makes this VarSet expand references to other variables in the values
+// it retrieves, before they are parsed by String, Bool, Int, et al. References
+// are resolved using the key mapper and the prefix for this VarSet, in the same
+// way as any other key. See Expand for the supported syntax. If a value cannot
+// be expanded, for example because of a reference of the form ${VAR:?message}
+// to a variable that is not set, getters return their fallback and the error
+// is reported by Err.
+func (vs *VarSet) SetExpand(expand bool) {
+	vs.expand = expand
+}
+
+// Expands reports whether this VarSet expands references in values.
+func (vs *VarSet) Expands() bool {
+	return vs.expand
+}
+
+// Expand replaces references to variables in s with their values, looking each
+// variable up using the key mapper and the prefix for this VarSet. Values of
+// referenced variables are expanded in turn, and then resolved and decrypted
+// like any other value; a reference that leads back to itself results in an
+// error. When a value of this VarSet is expanded, its key is marked as
+// sensitive if it references a sensitive variable. The following forms are
+// supported:
+//
+//	$VAR or ${VAR}    the value of VAR, or the empty string if VAR is not set
+//	${VAR:-default}   the value of VAR, or default if VAR is not set or empty
+//	${VAR:?message}   the value of VAR, or an error if VAR is not set or empty
+//	${VAR:+alt}       alt if VAR is set and not empty, otherwise the empty string
+//	$$                a literal '$'
+//
+// The words default, message and alt are expanded as well, but only when used.
+func (vs *VarSet) Expand(s string) (string, error) {
+	return vs.expandString(s, nil, &expansion{})
+}
+
+// expansion records what the references expanded in a value had in common.
+type expansion struct {
+	// sensitive reports whether a sensitive variable was referenced.
+	sensitive bool
+}
+
+// expandValue expands the value of the variable named by the key, and then
+// resolves and decrypts it like any other value, keeping track of the keys that
+// are being expanded in order to detect cycles. The origin of the value is
+// returned as well, and it is empty if the variable is not present.
+func (vs *VarSet) expandValue(key string, stack []string, x *expansion) (string, string, error) {
+	for _, k := range stack {
+		if k == key {
+			return "", "", fmt.Errorf("env: cyclic reference: %s -> %s", strings.Join(stack, " -> "), key)
+		}
+	}
+
+	value, origin, err := vs.lookupVar(key)
+	if err != nil || len(origin) == 0 {
+		return "", "", err
+	}
+
+	value, err = vs.processValue(key, value, true, stack, x)
+	if err != nil {
+		return "", "", err
+	}
+
+	return value, origin, nil
+}
+
+// expandString expands every reference in s. See Expand for the syntax.
+func (vs *VarSet) expandString(s string, stack []string, x *expansion) (string, error) {
+	if !strings.Contains(s, "$") {
+		return s, nil
+	}
+
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] != '$' || i+1 == len(s) {
+			b.WriteByte(s[i])
+			continue
+		}
+
+		switch c := s[i+1]; {
+		case c == '$':
+			b.WriteByte('$')
+			i++
+		case c == '{':
+			end, err := closingBrace(s, i+2)
+			if err != nil {
+				return "", err
+			}
+			value, err := vs.expandBraced(s[i+2:end], stack, x)
+			if err != nil {
+				return "", err
+			}
+			b.WriteString(value)
+			i = end
+		case isNameStart(c):
+			end := i + 2
+			for end < len(s) && isNameChar(s[end]) {
+				end++
+			}
+			value, _, err := vs.expandValue(s[i+1:end], stack, x)
+			if err != nil {
+				return "", err
+			}
+			b.WriteString(value)
+			i = end - 1
+		default:
+			b.WriteByte('$')
+		}
+	}
+
+	return b.String(), nil
+}
+
+// expandBraced expands the contents of a ${...} reference, without the braces.
+func (vs *VarSet) expandBraced(ref string, stack []string, x *expansion) (string, error) {
+	name, op, word := ref, "", ""
+	if i := strings.IndexByte(ref, ':'); i >= 0 {
+		name, op = ref[:i], ref[i:]
+		if len(op) > 2 {
+			op, word = op[:2], op[2:]
+		}
+	}
+	// The text of the reference is left out of errors, since it may hold
+	// secrets.
+	if !isName(name) {
+		return "", errors.New("env: bad substitution: invalid variable name")
+	}
+
+	value, origin, err := vs.expandValue(name, stack, x)
+	if err != nil {
+		return "", err
+	}
+	set := len(origin) > 0 && len(value) > 0
+
+	switch op {
+	case "":
+		return value, nil
+	case ":-":
+		if set {
+			return value, nil
+		}
+		return vs.expandString(word, stack, x)
+	case ":?":
+		if set {
+			return value, nil
+		}
+		msg, err := vs.expandString(word, stack, x)
+		if err != nil {
+			return "", err
+		}
+		if len(msg) == 0 {
+			msg = "parameter not set"
+		}
+		return "", fmt.Errorf("env: %s: %s", name, msg)
+	case ":+":
+		if !set {
+			return "", nil
+		}
+		return vs.expandString(word, stack, x)
+	default:
+		return "", fmt.Errorf("env: %s: bad substitution: unsupported operator %q", name, op)
+	}
+}
+
+// closingBrace returns the index of the '}' that closes a reference whose
+// contents begin at s[start], taking nested references into account. The error
+// reports the position of the reference rather than its text, which may hold
+// secrets.
+func closingBrace(s string, start int) (int, error) {
+	depth := 0
+	for i := start; i < len(s); i++ {
+		switch {
+		case s[i] == '$' && i+1 < len(s) && s[i+1] == '$':
+			i++
+		case s[i] == '$' && i+1 < len(s) && s[i+1] == '{':
+			depth++
+			i++
+		case s[i] == '}':
+			if depth == 0 {
+				return i, nil
+			}
+			depth--
+		}
+	}
+
+	return 0, fmt.Errorf("env: bad substitution: missing '}' for the reference at offset %d", start-2)
+}
+
+func isName(s string) bool {
+	if len(s) == 0 || !isNameStart(s[0]) {
+		return false
+	}
+	for i := 1; i < len(s); i++ {
+		if !isNameChar(s[i]) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func isNameStart(c byte) bool {
+	return c == '_' || 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z'
+}
+
+func isNameChar(c byte) bool {
+	return isNameStart(c) || '0' <= c && c <= '9'
+}
+
+// SetExpand makes the default VarSet expand references to other variables in
+// the values it retrieves. See VarSet.SetExpand.
+func SetExpand(expand bool) {
+	osVarSet.SetExpand(expand)
+}
+
+// Expand replaces references to variables in s with their values, using the
+// default VarSet. See VarSet.Expand for the supported syntax.
+func Expand(s string) (string, error) {
+	return osVarSet.Expand(s)
+}
@@ -0,0 +1,154 @@
+package env_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/christgf/env"
+)
+
+func TestVarSet_Expand(t *testing.T) {
+	t.Setenv("ENV_TEST_DB_USER", "alice")
+	t.Setenv("ENV_TEST_DB_PASS", "s3cret")
+	t.Setenv("ENV_TEST_DB_NAME", "")
+	t.Setenv("ENV_TEST_DB_AUTH", "${DB_USER}:${DB_PASS}")
+	t.Setenv("ENV_TEST_CYCLE_A", "${CYCLE_B}")
+	t.Setenv("ENV_TEST_CYCLE_B", "$CYCLE_A")
+
+	var vs env.VarSet
+	vs.SetPrefix("ENV_TEST_")
+
+	tests := []struct {
+		name    string
+		s       string
+		want    string
+		wantErr string
+	}{
+		{name: "no references", s: "postgres://localhost/app", want: "postgres://localhost/app"},
+		{name: "braced", s: "${DB_USER}@host", want: "alice@host"},
+		{name: "unbraced", s: "$DB_USER@host", want: "alice@host"},
+		{name: "unset", s: "[${DB_HOST}]", want: "[]"},
+		{name: "nested value", s: "postgres://${DB_AUTH}@db", want: "postgres://alice:s3cret@db"},
+		{name: "default unset", s: "${DB_HOST:-localhost}", want: "localhost"},
+		{name: "default empty", s: "${DB_NAME:-app}", want: "app"},
+		{name: "default set", s: "${DB_USER:-bob}", want: "alice"},
+		{name: "default reference", s: "${DB_HOST:-${DB_USER}.local}", want: "alice.local"},
+		{name: "alternative set", s: "${DB_PASS:+with password}", want: "with password"},
+		{name: "alternative unset", s: "${DB_HOST:+with host}", want: ""},
+		{name: "error set", s: "${DB_USER:?user required}", want: "alice"},
+		{name: "error unset", s: "${DB_HOST:?host required}", wantErr: "DB_HOST: host required"},
+		{name: "error unset no message", s: "${DB_HOST:?}", wantErr: "DB_HOST: parameter not set"},
+		{name: "escaped", s: "cost: $$5, ${DB_HOST:-$$HOST}", want: "cost: $5, $HOST"},
+		{name: "literal", s: "$ 5 $", want: "$ 5 $"},
+		{name: "cycle", s: "${CYCLE_A}", wantErr: "cyclic reference"},
+		{name: "bad name", s: "${DB-USER}", wantErr: "bad substitution"},
+		{name: "bad operator", s: "${DB_USER:=bob}", wantErr: "bad substitution"},
+		{name: "unterminated", s: "${DB_USER", wantErr: "missing '}'"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := vs.Expand(tt.s)
+			if len(tt.wantErr) > 0 {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("Expand(%q): got error %v, want %q", tt.s, err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Expand(%q): %v", tt.s, err)
+			}
+			if got != tt.want {
+				t.Errorf("Expand(%q): got %q, want %q", tt.s, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestVarSet_SetExpand(t *testing.T) {
+	const envKey = "ENV_TEST_EXPAND_URL"
+
+	t.Setenv("ENV_TEST_EXPAND_USER", "alice")
+	t.Setenv("ENV_TEST_EXPAND_PORT", "${ENV_TEST_EXPAND_PORT_BASE:-8000}")
+	t.Setenv(envKey, "postgres://${ENV_TEST_EXPAND_USER}@${ENV_TEST_EXPAND_HOST:-localhost}/app")
+	t.Setenv("ENV_TEST_EXPAND_CYCLE", "${ENV_TEST_EXPAND_CYCLE}")
+
+	var vs env.VarSet
+	if got, want := vs.String(envKey, ""), "postgres://${ENV_TEST_EXPAND_USER}@${ENV_TEST_EXPAND_HOST:-localhost}/app"; got != want {
+		t.Errorf("String(%q): got %q, want %q", envKey, got, want)
+	}
+
+	vs.SetExpand(true)
+	if !vs.Expands() {
+		t.Fatalf("Expands(): got false")
+	}
+	if got, want := vs.String(envKey, ""), "postgres://alice@localhost/app"; got != want {
+		t.Errorf("String(%q): got %q, want %q", envKey, got, want)
+	}
+	if got, want := vs.Int("ENV_TEST_EXPAND_PORT", 0), 8000; got != want {
+		t.Errorf("Int(%q): got %d, want %d", "ENV_TEST_EXPAND_PORT", got, want)
+	}
+	if got, want := vs.String("ENV_TEST_EXPAND_CYCLE", "fallback"), "fallback"; got != want {
+		t.Errorf("String(%q): got %q, want %q", "ENV_TEST_EXPAND_CYCLE", got, want)
+	}
+}
+
+func TestVarSet_SetExpand_Err(t *testing.T) {
+	const envKey = "ENV_TEST_EXPAND_DSN"
+
+	t.Setenv("ENV_TEST_EXPAND_USER", "alice")
+	t.Setenv(envKey, "postgres://${ENV_TEST_EXPAND_USER}@${ENV_TEST_EXPAND_HOST:?host required}/app")
+
+	var vs env.VarSet
+	vs.SetExpand(true)
+	if got, want := vs.String("ENV_TEST_EXPAND_USER", ""), "alice"; got != want {
+		t.Errorf("String(%q): got %q, want %q", "ENV_TEST_EXPAND_USER", got, want)
+	}
+	if err := vs.Err(); err != nil {
+		t.Fatalf("Err(): got %v, want nil", err)
+	}
+
+	if got, want := vs.String(envKey, "fallback"), "fallback"; got != want {
+		t.Errorf("String(%q): got %q, want %q", envKey, got, want)
+	}
+	err := vs.Err()
+	if err == nil || !strings.Contains(err.Error(), "ENV_TEST_EXPAND_HOST: host required") {
+		t.Fatalf("Err(): got %v, want %q", err, "ENV_TEST_EXPAND_HOST: host required")
+	}
+
+	t.Setenv("ENV_TEST_EXPAND_HOST", "db")
+	if got, want := vs.String(envKey, "fallback"), "postgres://alice@db/app"; got != want {
+		t.Errorf("String(%q): got %q, want %q", envKey, got, want)
+	}
+	if got := vs.Err(); got != err {
+		t.Errorf("Err(): got %v, want the first error %v", got, err)
+	}
+}
+
+func TestVarSet_SetExpand_errSecret(t *testing.T) {
+	tests := []struct {
+		name    string
+		value   string
+		wantErr string
+	}{
+		{name: "unterminated", value: "postgres://app:s3cret@${DB_HOST", wantErr: "missing '}' for the reference at offset 22"},
+		{name: "bad name", value: "${s3cret!}", wantErr: "invalid variable name"},
+		{name: "bad operator", value: "${DB_HOST:=s3cret}", wantErr: "unsupported operator"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("ENV_TEST_EXPAND_DSN", tt.value)
+
+			var vs env.VarSet
+			vs.SetExpand(true)
+			vs.MarkSensitive("ENV_TEST_EXPAND_DSN")
+			vs.String("ENV_TEST_EXPAND_DSN", "")
+			err := vs.Err()
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("Err(): got %v, want error containing %q", err, tt.wantErr)
+			}
+			if strings.Contains(err.Error(), "s3cret") {
+				t.Errorf("Err(): got %q, want no secret", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,253 @@
+package env
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// FileSource is a Source backed by a structured configuration file, in JSON,
+// YAML or TOML. Nested keys are flattened into variable names: the keys on the
+// path to every value are joined with dots and mapped using the key mapper of
+// the source, UpperSnakeCase by default, so that the value of max in
+//
+//	db:
+//	  pool:
+//	    max: 10
+//
+// provides the variable DB_POOL_MAX. Arrays are flattened using the index of
+// every element as a key, so that the first element of hosts provides HOSTS_0.
+// Scalars are used as they appear in the file, and null values are not
+// present. Two keys that are mapped to the same name, such as "db.host" and
+// "db_host", result in an error.
+//
+// The file is read when the source is created, and again by Reload. Names are
+// matched case-insensitively. A FileSource is safe for concurrent use.
+type FileSource struct {
+	path       string
+	parse      func([]byte) (map[string]any, error)
+	mapper     KeyMapper
+	trimPrefix string
+
+	mu     sync.Mutex
+	values map[string]string
+}
+
+// FileOption configures a FileSource.
+type FileOption func(*FileSource)
+
+// FileKeyMapper makes a FileSource map flattened keys, such as "db.pool.max",
+// to variable names using m. The default is UpperSnakeCase.
+func FileKeyMapper(m KeyMapper) FileOption {
+	return func(f *FileSource) {
+		f.mapper = m
+	}
+}
+
+// FileTrimPrefix makes a FileSource remove prefix from the names of the
+// variables it looks up before it matches them against the file, so that the
+// file does not need to repeat the prefix of a VarSet.
+func FileTrimPrefix(prefix string) FileOption {
+	return func(f *FileSource) {
+		f.trimPrefix = prefix
+	}
+}
+
+// NewJSONFileSource returns a FileSource for the JSON file named by path, which
+// must hold an object.
+func NewJSONFileSource(path string, opts ...FileOption) (*FileSource, error) {
+	return newFileSource(path, parseJSONFile, opts)
+}
+
+// NewYAMLFileSource returns a FileSource for the YAML file named by path, which
+// must hold a mapping. The file is parsed by a parser that is part of this
+// package, and supports the subset of YAML commonly used for configuration:
+// block and flow collections, plain, quoted and block scalars, and comments.
+// Anchors, aliases, tags and multiple documents are not supported.
+func NewYAMLFileSource(path string, opts ...FileOption) (*FileSource, error) {
+	return newFileSource(path, parseYAML, opts)
+}
+
+// NewTOMLFileSource returns a FileSource for the TOML file named by path. The
+// file is parsed by a parser that is part of this package, and supports TOML
+// 1.0: tables, arrays of tables, dotted keys, inline tables and arrays, and
+// every kind of string, number and date.
+func NewTOMLFileSource(path string, opts ...FileOption) (*FileSource, error) {
+	return newFileSource(path, parseTOML, opts)
+}
+
+// newFileSource returns a FileSource for the file named by path, read using
+// parse.
+func newFileSource(path string, parse func([]byte) (map[string]any, error), opts []FileOption) (*FileSource, error) {
+	f := &FileSource{
+		path:   path,
+		parse:  parse,
+		mapper: UpperSnakeCase,
+	}
+	for _, opt := range opts {
+		opt(f)
+	}
+
+	if err := f.Reload(); err != nil {
+		return nil, err
+	}
+
+	return f, nil
+}
+
+// Reload reads the file again. If it cannot be read, the values read before
+// are kept.
+func (f *FileSource) Reload() error {
+	b, err := os.ReadFile(f.path)
+	if err != nil {
+		return fmt.Errorf("env: %w", err)
+	}
+	tree, err := f.parse(b)
+	if err != nil {
+		return fmt.Errorf("env: %s: %w", f.path, err)
+	}
+	values, err := flatten(tree, f.mapper)
+	if err != nil {
+		return fmt.Errorf("env: %s: %w", f.path, err)
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.values = values
+	return nil
+}
+
+// Lookup retrieves the value that matches name.
+func (f *FileSource) Lookup(name string) (string, bool, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return matchName(f.values, strings.TrimPrefix(name, f.trimPrefix))
+}
+
+// String returns the name of f, as in "file:config.yaml".
+func (f *FileSource) String() string {
+	return "file:" + f.path
+}
+
+// flatten maps the path to every value of tree to a name using mapper. Values
+// of the tree are maps, slices, strings, or nil for null values, which are left
+// out.
+func flatten(tree map[string]any, mapper KeyMapper) (map[string]string, error) {
+	values := make(map[string]string)
+	paths := make(map[string]string)
+
+	var walk func(path string, node any) error
+	walk = func(path string, node any) error {
+		switch node := node.(type) {
+		case map[string]any:
+			keys := make([]string, 0, len(node))
+			for key := range node {
+				keys = append(keys, key)
+			}
+			sort.Strings(keys)
+			for _, key := range keys {
+				if err := walk(joinPath(path, key), node[key]); err != nil {
+					return err
+				}
+			}
+		case []any:
+			for i, elem := range node {
+				if err := walk(joinPath(path, strconv.Itoa(i)), elem); err != nil {
+					return err
+				}
+			}
+		case string:
+			name := mapper(path)
+			if prev, ok := paths[name]; ok {
+				return fmt.Errorf("keys %s and %s are both mapped to %s", prev, path, name)
+			}
+			values[name], paths[name] = node, path
+		}
+
+		return nil
+	}
+
+	if err := walk("", tree); err != nil {
+		return nil, err
+	}
+
+	return values, nil
+}
+
+// joinPath appends key to the dotted path.
+func joinPath(path, key string) string {
+	if len(path) == 0 {
+		return key
+	}
+
+	return path + "." + key
+}
+
+// parseJSONFile parses a JSON object into a tree for flatten.
+func parseJSONFile(b []byte) (map[string]any, error) {
+	d := json.NewDecoder(bytes.NewReader(b))
+	d.UseNumber()
+
+	var v any
+	if err := d.Decode(&v); err != nil {
+		var serr *json.SyntaxError
+		if errors.As(err, &serr) {
+			return nil, fmt.Errorf("line %d: %s", lineOf(b, serr.Offset), serr.Error())
+		}
+		if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+			return nil, errors.New("unexpected end of JSON input")
+		}
+		return nil, err
+	}
+	if _, err := d.Token(); err != io.EOF {
+		return nil, fmt.Errorf("line %d: unexpected data after top-level value", lineOf(b, d.InputOffset()))
+	}
+
+	object, ok := jsonTree(v).(map[string]any)
+	if !ok {
+		return nil, errors.New("top-level value is not an object")
+	}
+
+	return object, nil
+}
+
+// jsonTree converts the numbers and booleans of a decoded JSON value to
+// strings.
+func jsonTree(v any) any {
+	switch v := v.(type) {
+	case map[string]any:
+		for key, elem := range v {
+			v[key] = jsonTree(elem)
+		}
+		return v
+	case []any:
+		for i, elem := range v {
+			v[i] = jsonTree(elem)
+		}
+		return v
+	case json.Number:
+		return v.String()
+	case bool:
+		return strconv.FormatBool(v)
+	default:
+		return v
+	}
+}
+
+// lineOf returns the line number of the byte at offset in b.
+func lineOf(b []byte, offset int64) int {
+	if offset > int64(len(b)) {
+		offset = int64(len(b))
+	}
+
+	return bytes.Count(b[:offset], []byte("\n")) + 1
+}
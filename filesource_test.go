@@ -0,0 +1,182 @@
+package env_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/christgf/env"
+)
+
+// writeConfig writes content to a file named name in a temporary directory, and
+// returns its path.
+func writeConfig(t *testing.T, name, content string) string {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatalf("WriteFile(): %v", err)
+	}
+
+	return path
+}
+
+// checkSource checks that src provides the values in want, and no value for
+// the names in absent.
+func checkSource(t *testing.T, src env.Source, want map[string]string, absent ...string) {
+	t.Helper()
+
+	for name, wantValue := range want {
+		value, ok, err := src.Lookup(name)
+		if err != nil || !ok || value != wantValue {
+			t.Errorf("Lookup(%q): got %q, %v, %v, want %q", name, value, ok, err, wantValue)
+		}
+	}
+	for _, name := range absent {
+		if value, ok, err := src.Lookup(name); ok || err != nil {
+			t.Errorf("Lookup(%q): got %q, %v, %v, want not found", name, value, ok, err)
+		}
+	}
+}
+
+func TestJSONFileSource(t *testing.T) {
+	path := writeConfig(t, "config.json", `{
+	"port": 9000,
+	"debug": true,
+	"ratio": 0.75,
+	"replica": null,
+	"db": {"host": "db.internal", "pool": {"max": 10, "maxIdleConns": 2}},
+	"hosts": ["a.internal", "b.internal"],
+	"servers": [{"name": "primary", "weight": 3}]
+}`)
+
+	src, err := env.NewJSONFileSource(path)
+	if err != nil {
+		t.Fatalf("NewJSONFileSource(): %v", err)
+	}
+	checkSource(t, src, map[string]string{
+		"PORT":                   "9000",
+		"DEBUG":                  "true",
+		"RATIO":                  "0.75",
+		"DB_HOST":                "db.internal",
+		"DB_POOL_MAX":            "10",
+		"DB_POOL_MAX_IDLE_CONNS": "2",
+		"HOSTS_0":                "a.internal",
+		"HOSTS_1":                "b.internal",
+		"SERVERS_0_NAME":         "primary",
+		"SERVERS_0_WEIGHT":       "3",
+		"db_pool_max":            "10",
+	}, "REPLICA", "DB", "DB_POOL", "HOSTS")
+
+	if got, want := src.String(), "file:"+path; got != want {
+		t.Errorf("String(): got %q, want %q", got, want)
+	}
+}
+
+func TestFileSource_VarSet(t *testing.T) {
+	t.Setenv("APP_PORT", "8443")
+	path := writeConfig(t, "config.json", `{"port": 9000, "db": {"host": "db.internal"}}`)
+
+	src, err := env.NewJSONFileSource(path, env.FileTrimPrefix("APP_"))
+	if err != nil {
+		t.Fatalf("NewJSONFileSource(): %v", err)
+	}
+
+	var vs env.VarSet
+	vs.SetPrefix("APP_")
+	vs.SetSources(src)
+	if got, want := vs.Int("PORT", 0), 8443; got != want {
+		t.Errorf("Int(%q): got %d, want %d", "PORT", got, want)
+	}
+	if got, want := vs.String("DB_HOST", ""), "db.internal"; got != want {
+		t.Errorf("String(%q): got %q, want %q", "DB_HOST", got, want)
+	}
+
+	for _, r := range vs.Records() {
+		want := "file:" + path
+		if r.Key == "PORT" {
+			want = "env"
+		}
+		if r.Origin != want {
+			t.Errorf("Records(): got origin %q for %s, want %q", r.Origin, r.Key, want)
+		}
+	}
+}
+
+func TestFileSource_Reload(t *testing.T) {
+	path := writeConfig(t, "config.json", `{"port": 9000}`)
+
+	src, err := env.NewJSONFileSource(path)
+	if err != nil {
+		t.Fatalf("NewJSONFileSource(): %v", err)
+	}
+	if err := os.WriteFile(path, []byte(`{"port": 9001}`), 0o600); err != nil {
+		t.Fatalf("WriteFile(): %v", err)
+	}
+	if err := src.Reload(); err != nil {
+		t.Fatalf("Reload(): %v", err)
+	}
+	checkSource(t, src, map[string]string{"PORT": "9001"})
+
+	// A file that cannot be parsed leaves the values read before.
+	if err := os.WriteFile(path, []byte(`{"port": `), 0o600); err != nil {
+		t.Fatalf("WriteFile(): %v", err)
+	}
+	if err := src.Reload(); err == nil {
+		t.Errorf("Reload(): got no error")
+	}
+	checkSource(t, src, map[string]string{"PORT": "9001"})
+}
+
+func TestFileSource_KeyMapper(t *testing.T) {
+	path := writeConfig(t, "config.json", `{"db": {"pool-max": 10}}`)
+
+	src, err := env.NewJSONFileSource(path, env.FileKeyMapper(env.NormalizeKey))
+	if err != nil {
+		t.Fatalf("NewJSONFileSource(): %v", err)
+	}
+	checkSource(t, src, map[string]string{"db_pool_max": "10"})
+}
+
+func TestFileSource_ambiguousNames(t *testing.T) {
+	path := writeConfig(t, "config.json", `{"db_password": "a", "DB_Password": "b", "db_user": "app"}`)
+
+	src, err := env.NewJSONFileSource(path, env.FileKeyMapper(func(key string) string { return key }))
+	if err != nil {
+		t.Fatalf("NewJSONFileSource(): %v", err)
+	}
+	checkSource(t, src, map[string]string{"db_password": "a", "DB_Password": "b", "DB_USER": "app"})
+
+	value, ok, err := src.Lookup("DB_PASSWORD")
+	if err == nil || !strings.Contains(err.Error(), "names DB_Password, db_password match DB_PASSWORD") {
+		t.Errorf("Lookup(%q): got %q, %v, %v, want an error", "DB_PASSWORD", value, ok, err)
+	}
+}
+
+func TestJSONFileSource_errors(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		wantErr string
+	}{
+		{name: "conflict", content: `{"db": {"host": "a"}, "db_host": "b"}`, wantErr: "keys db.host and db_host are both mapped to DB_HOST"},
+		{name: "syntax", content: "{\n\"port\": 90 00\n}", wantErr: "line 2: invalid character"},
+		{name: "not an object", content: `["a", "b"]`, wantErr: "top-level value is not an object"},
+		{name: "trailing data", content: `{} {}`, wantErr: "unexpected data after top-level value"},
+		{name: "empty", content: ``, wantErr: "unexpected end of JSON input"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := writeConfig(t, "config.json", tt.content)
+			_, err := env.NewJSONFileSource(path)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) || !strings.HasPrefix(err.Error(), "env: "+path+": ") {
+				t.Errorf("NewJSONFileSource(): got %v, want error containing %q", err, tt.wantErr)
+			}
+		})
+	}
+
+	if _, err := env.NewJSONFileSource(filepath.Join(t.TempDir(), "missing.json")); err == nil {
+		t.Errorf("NewJSONFileSource(): got no error for a missing file")
+	}
+}
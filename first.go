@@ -0,0 +1,234 @@
+package env
+
+import (
+	"strconv"
+	"time"
+)
+
+// noPrefixMarker marks keys that are looked up without the prefix of a VarSet.
+// It cannot be part of the name of an environment variable.
+const noPrefixMarker = "="
+
+// NoPrefix marks key so that it is looked up in the environment without the
+// prefix of the VarSet, which is useful for standard variables that are shared
+// with other programs, such as "OTEL_SERVICE_NAME" or "HOME". The key mapper of
+// the VarSet still applies. For example:
+//
+//	vs.SetPrefix("MYAPP_")
+//	name, key := vs.FirstString([]string{"SERVICE_NAME", env.NoPrefix("OTEL_SERVICE_NAME")}, "app")
+//
+// looks up MYAPP_SERVICE_NAME first, and then OTEL_SERVICE_NAME.
+func NoPrefix(key string) string {
+	return noPrefixMarker + key
+}
+
+// splitNoPrefix reports whether key was marked using NoPrefix, and returns the
+// key without the mark.
+func splitNoPrefix(key string) (string, bool) {
+	return cutPrefix(key, noPrefixMarker)
+}
+
+// plainKey returns key without the mark of NoPrefix, so that reads are
+// recorded, and keys are marked as sensitive, under the same name whether or
+// not the key was marked.
+func plainKey(key string) string {
+	key, _ = splitNoPrefix(key)
+	return key
+}
+
+// FirstOf tries each of the keys in order, retrieving the value of the
+// corresponding environment variable from vs and parsing it using parse. It
+// returns the first value that is present and can be parsed, along with the key
+// it was found under. If there is no such value, it returns fallback and an
+// empty key. Parse functions of this package, such as ParseByteSize or
+// ParseExtendedDuration, can be used with FirstOf. Dump reports the key that
+// was used, or the first key if none was.
+func FirstOf[T any](vs *VarSet, keys []string, parse func(string) (T, error), fallback T) (T, string) {
+	var firstErr error
+	for _, key := range keys {
+		value, origin, err := vs.lookup(key)
+		if len(origin) == 0 {
+			if err != nil && firstErr == nil {
+				firstErr = err
+			}
+			continue
+		}
+
+		res, err := parse(value)
+		if err != nil {
+			if firstErr == nil {
+				firstErr = parseErr(err, value)
+			}
+			continue
+		}
+
+		vs.record(key, res, fallback, origin, nil)
+		return res, key
+	}
+
+	if len(keys) > 0 {
+		vs.record(keys[0], fallback, fallback, originDefault, firstErr)
+	}
+	return fallback, ""
+}
+
+// FirstString tries each of the keys in order, and returns the value of the
+// first environment variable that is present, along with the key it was found
+// under. If none of the variables is present, it returns fallback and an empty
+// key. Keys marked using NoPrefix are looked up without the prefix.
+func (vs *VarSet) FirstString(keys []string, fallback string) (string, string) {
+	return FirstOf(vs, keys, func(s string) (string, error) {
+		return s, nil
+	}, fallback)
+}
+
+// FirstBool tries each of the keys in order, and returns the value of the first
+// environment variable that is present and can be parsed as a boolean, along
+// with the key it was found under. If there is no such variable, it returns
+// fallback and an empty key.
+func (vs *VarSet) FirstBool(keys []string, fallback bool) (bool, string) {
+	return FirstOf(vs, keys, vs.boolParser(), fallback)
+}
+
+// FirstInt tries each of the keys in order, and returns the value of the first
+// environment variable that is present and can be parsed as an integer, along
+// with the key it was found under. If there is no such variable, it returns
+// fallback and an empty key.
+func (vs *VarSet) FirstInt(keys []string, fallback int) (int, string) {
+	return FirstOf(vs, keys, func(s string) (int, error) {
+		n, err := ParseInt(s, vs.intFormat, strconv.IntSize)
+		return int(n), err
+	}, fallback)
+}
+
+// FirstInt64 tries each of the keys in order, and returns the value of the
+// first environment variable that is present and can be parsed as a 64-bit
+// integer, along with the key it was found under. If there is no such variable,
+// it returns fallback and an empty key.
+func (vs *VarSet) FirstInt64(keys []string, fallback int64) (int64, string) {
+	return FirstOf(vs, keys, func(s string) (int64, error) {
+		return ParseInt(s, vs.intFormat, 64)
+	}, fallback)
+}
+
+// FirstUint tries each of the keys in order, and returns the value of the first
+// environment variable that is present and can be parsed as an unsigned
+// integer, along with the key it was found under. If there is no such variable,
+// it returns fallback and an empty key.
+func (vs *VarSet) FirstUint(keys []string, fallback uint) (uint, string) {
+	return FirstOf(vs, keys, func(s string) (uint, error) {
+		n, err := ParseUint(s, vs.intFormat, strconv.IntSize)
+		return uint(n), err
+	}, fallback)
+}
+
+// FirstUint64 tries each of the keys in order, and returns the value of the
+// first environment variable that is present and can be parsed as an unsigned
+// 64-bit integer, along with the key it was found under. If there is no such
+// variable, it returns fallback and an empty key.
+func (vs *VarSet) FirstUint64(keys []string, fallback uint64) (uint64, string) {
+	return FirstOf(vs, keys, func(s string) (uint64, error) {
+		return ParseUint(s, vs.intFormat, 64)
+	}, fallback)
+}
+
+// FirstFloat32 tries each of the keys in order, and returns the value of the
+// first environment variable that is present and can be parsed as a
+// floating-point number, along with the key it was found under. If there is no
+// such variable, it returns fallback and an empty key.
+func (vs *VarSet) FirstFloat32(keys []string, fallback float32) (float32, string) {
+	return FirstOf(vs, keys, func(s string) (float32, error) {
+		f, err := strconv.ParseFloat(s, 32)
+		return float32(f), err
+	}, fallback)
+}
+
+// FirstFloat64 tries each of the keys in order, and returns the value of the
+// first environment variable that is present and can be parsed as a 64-bit
+// floating-point number, along with the key it was found under. If there is no
+// such variable, it returns fallback and an empty key.
+func (vs *VarSet) FirstFloat64(keys []string, fallback float64) (float64, string) {
+	return FirstOf(vs, keys, func(s string) (float64, error) {
+		return strconv.ParseFloat(s, 64)
+	}, fallback)
+}
+
+// FirstDuration tries each of the keys in order, and returns the value of the
+// first environment variable that is present and can be parsed as
+// time.Duration, along with the key it was found under. If there is no such
+// variable, it returns fallback and an empty key.
+func (vs *VarSet) FirstDuration(keys []string, fallback time.Duration) (time.Duration, string) {
+	return FirstOf(vs, keys, time.ParseDuration, fallback)
+}
+
+// FirstString tries each of the keys in order, and returns the value of the
+// first environment variable that is present, along with the key it was found
+// under. If none of the variables is present, it returns fallback and an empty
+// key. Keys marked using NoPrefix are looked up without the prefix.
+func FirstString(keys []string, fallback string) (string, string) {
+	return osVarSet.FirstString(keys, fallback)
+}
+
+// FirstBool tries each of the keys in order, and returns the value of the first
+// environment variable that is present and can be parsed as a boolean, along
+// with the key it was found under. If there is no such variable, it returns
+// fallback and an empty key.
+func FirstBool(keys []string, fallback bool) (bool, string) {
+	return osVarSet.FirstBool(keys, fallback)
+}
+
+// FirstInt tries each of the keys in order, and returns the value of the first
+// environment variable that is present and can be parsed as an integer, along
+// with the key it was found under. If there is no such variable, it returns
+// fallback and an empty key.
+func FirstInt(keys []string, fallback int) (int, string) {
+	return osVarSet.FirstInt(keys, fallback)
+}
+
+// FirstInt64 tries each of the keys in order, and returns the value of the
+// first environment variable that is present and can be parsed as a 64-bit
+// integer, along with the key it was found under. If there is no such variable,
+// it returns fallback and an empty key.
+func FirstInt64(keys []string, fallback int64) (int64, string) {
+	return osVarSet.FirstInt64(keys, fallback)
+}
+
+// FirstUint tries each of the keys in order, and returns the value of the first
+// environment variable that is present and can be parsed as an unsigned
+// integer, along with the key it was found under. If there is no such variable,
+// it returns fallback and an empty key.
+func FirstUint(keys []string, fallback uint) (uint, string) {
+	return osVarSet.FirstUint(keys, fallback)
+}
+
+// FirstUint64 tries each of the keys in order, and returns the value of the
+// first environment variable that is present and can be parsed as an unsigned
+// 64-bit integer, along with the key it was found under. If there is no such
+// variable, it returns fallback and an empty key.
+func FirstUint64(keys []string, fallback uint64) (uint64, string) {
+	return osVarSet.FirstUint64(keys, fallback)
+}
+
+// FirstFloat32 tries each of the keys in order, and returns the value of the
+// first environment variable that is present and can be parsed as a
+// floating-point number, along with the key it was found under. If there is no
+// such variable, it returns fallback and an empty key.
+func FirstFloat32(keys []string, fallback float32) (float32, string) {
+	return osVarSet.FirstFloat32(keys, fallback)
+}
+
+// FirstFloat64 tries each of the keys in order, and returns the value of the
+// first environment variable that is present and can be parsed as a 64-bit
+// floating-point number, along with the key it was found under. If there is no
+// such variable, it returns fallback and an empty key.
+func FirstFloat64(keys []string, fallback float64) (float64, string) {
+	return osVarSet.FirstFloat64(keys, fallback)
+}
+
+// FirstDuration tries each of the keys in order, and returns the value of the
+// first environment variable that is present and can be parsed as
+// time.Duration, along with the key it was found under. If there is no such
+// variable, it returns fallback and an empty key.
+func FirstDuration(keys []string, fallback time.Duration) (time.Duration, string) {
+	return osVarSet.FirstDuration(keys, fallback)
+}
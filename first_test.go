@@ -0,0 +1,136 @@
+package env_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/christgf/env"
+)
+
+func TestFirstString(t *testing.T) {
+	keys := []string{"ENV_TEST_OTEL_SERVICE_NAME", "ENV_TEST_SERVICE_NAME", "ENV_TEST_APP_NAME"}
+
+	t.Run("none is present", func(t *testing.T) {
+		got, key := env.FirstString(keys, "app")
+		if got != "app" || key != "" {
+			t.Errorf("FirstString(%q): got %q, %q, want %q, %q", keys, got, key, "app", "")
+		}
+	})
+
+	t.Run("later key is present", func(t *testing.T) {
+		t.Setenv("ENV_TEST_APP_NAME", "billing")
+		got, key := env.FirstString(keys, "app")
+		if got != "billing" || key != "ENV_TEST_APP_NAME" {
+			t.Errorf("FirstString(%q): got %q, %q, want %q, %q", keys, got, key, "billing", "ENV_TEST_APP_NAME")
+		}
+	})
+
+	t.Run("earlier key wins", func(t *testing.T) {
+		t.Setenv("ENV_TEST_APP_NAME", "billing")
+		t.Setenv("ENV_TEST_SERVICE_NAME", "")
+		got, key := env.FirstString(keys, "app")
+		if got != "" || key != "ENV_TEST_SERVICE_NAME" {
+			t.Errorf("FirstString(%q): got %q, %q, want %q, %q", keys, got, key, "", "ENV_TEST_SERVICE_NAME")
+		}
+	})
+}
+
+func TestVarSet_FirstString_noPrefix(t *testing.T) {
+	t.Setenv("ENV_TEST_OTEL_SERVICE_NAME", "otel")
+	t.Setenv("ENV_TEST_OTEL_SERVICE_NAME_PREFIXED", "prefixed")
+
+	var vs env.VarSet
+	vs.SetPrefix("ENV_TEST_")
+	keys := []string{"SERVICE_NAME", env.NoPrefix("ENV_TEST_OTEL_SERVICE_NAME")}
+	got, key := vs.FirstString(keys, "app")
+	if got != "otel" || key != keys[1] {
+		t.Errorf("FirstString(%q): got %q, %q, want %q, %q", keys, got, key, "otel", keys[1])
+	}
+
+	t.Setenv("ENV_TEST_SERVICE_NAME", "mine")
+	got, key = vs.FirstString(keys, "app")
+	if got != "mine" || key != keys[0] {
+		t.Errorf("FirstString(%q): got %q, %q, want %q, %q", keys, got, key, "mine", keys[0])
+	}
+
+	if got, want := vs.String(env.NoPrefix("OTEL_SERVICE_NAME_PREFIXED"), "none"), "none"; got != want {
+		t.Errorf("String(NoPrefix(%q)): got %q, want %q", "OTEL_SERVICE_NAME_PREFIXED", got, want)
+	}
+}
+
+func TestVarSet_FirstString_noPrefixSensitive(t *testing.T) {
+	t.Setenv("ENV_TEST_NOPREFIX_API_TOKEN", "tok123")
+
+	var vs env.VarSet
+	vs.SetPrefix("ENV_TEST_APP_")
+	vs.MarkSensitive("ENV_TEST_NOPREFIX_API_TOKEN")
+	keys := []string{"ENV_TEST_NOPREFIX_API_TOKEN", env.NoPrefix("ENV_TEST_NOPREFIX_API_TOKEN")}
+	if got, key := vs.FirstString(keys, ""); got != "tok123" || key != keys[1] {
+		t.Fatalf("FirstString(%q): got %q, %q, want %q, %q", keys, got, key, "tok123", keys[1])
+	}
+	if !vs.IsSensitive(keys[1]) {
+		t.Errorf("IsSensitive(%q): got false, want true", keys[1])
+	}
+
+	var b bytes.Buffer
+	if err := vs.Dump(&b, env.DumpText); err != nil {
+		t.Fatalf("Dump(): %v", err)
+	}
+	if got := b.String(); strings.Contains(got, "tok123") || !strings.HasPrefix(got, `ENV_TEST_NOPREFIX_API_TOKEN="[REDACTED]"`) {
+		t.Errorf("Dump(): got %q, want the value of %s redacted", got, keys[0])
+	}
+}
+
+func TestFirstTyped(t *testing.T) {
+	t.Setenv("ENV_TEST_FIRST_INVALID", "foobar")
+	t.Setenv("ENV_TEST_FIRST_BOOL", "true")
+	t.Setenv("ENV_TEST_FIRST_INT", "7")
+	t.Setenv("ENV_TEST_FIRST_FLOAT", "0.5")
+	t.Setenv("ENV_TEST_FIRST_DURATION", "1m")
+
+	chain := func(key string) []string {
+		return []string{"ENV_TEST_FIRST_UNSET", "ENV_TEST_FIRST_INVALID", key}
+	}
+
+	if got, key := env.FirstBool(chain("ENV_TEST_FIRST_BOOL"), false); got != true || key != "ENV_TEST_FIRST_BOOL" {
+		t.Errorf("FirstBool(): got %v, %q", got, key)
+	}
+	if got, key := env.FirstInt(chain("ENV_TEST_FIRST_INT"), 0); got != 7 || key != "ENV_TEST_FIRST_INT" {
+		t.Errorf("FirstInt(): got %v, %q", got, key)
+	}
+	if got, key := env.FirstInt64(chain("ENV_TEST_FIRST_INT"), 0); got != 7 || key != "ENV_TEST_FIRST_INT" {
+		t.Errorf("FirstInt64(): got %v, %q", got, key)
+	}
+	if got, key := env.FirstUint(chain("ENV_TEST_FIRST_INT"), 0); got != 7 || key != "ENV_TEST_FIRST_INT" {
+		t.Errorf("FirstUint(): got %v, %q", got, key)
+	}
+	if got, key := env.FirstUint64(chain("ENV_TEST_FIRST_INT"), 0); got != 7 || key != "ENV_TEST_FIRST_INT" {
+		t.Errorf("FirstUint64(): got %v, %q", got, key)
+	}
+	if got, key := env.FirstFloat32(chain("ENV_TEST_FIRST_FLOAT"), 0); got != 0.5 || key != "ENV_TEST_FIRST_FLOAT" {
+		t.Errorf("FirstFloat32(): got %v, %q", got, key)
+	}
+	if got, key := env.FirstFloat64(chain("ENV_TEST_FIRST_FLOAT"), 0); got != 0.5 || key != "ENV_TEST_FIRST_FLOAT" {
+		t.Errorf("FirstFloat64(): got %v, %q", got, key)
+	}
+	if got, key := env.FirstDuration(chain("ENV_TEST_FIRST_DURATION"), 0); got != time.Minute || key != "ENV_TEST_FIRST_DURATION" {
+		t.Errorf("FirstDuration(): got %v, %q", got, key)
+	}
+
+	if got, key := env.FirstInt([]string{"ENV_TEST_FIRST_UNSET", "ENV_TEST_FIRST_INVALID"}, 42); got != 42 || key != "" {
+		t.Errorf("FirstInt(): got %v, %q, want %v, %q", got, key, 42, "")
+	}
+}
+
+func TestFirstOf(t *testing.T) {
+	t.Setenv("ENV_TEST_FIRST_SIZE", "10MiB")
+
+	var vs env.VarSet
+	keys := []string{"ENV_TEST_FIRST_UNSET", "ENV_TEST_FIRST_SIZE"}
+	got, key := env.FirstOf(&vs, keys, env.ParseByteSize, env.KiB)
+	if got != 10*env.MiB || key != "ENV_TEST_FIRST_SIZE" {
+		t.Errorf("FirstOf(%q): got %v, %q, want %v, %q", keys, got, key, 10*env.MiB, "ENV_TEST_FIRST_SIZE")
+	}
+}
@@ -0,0 +1,478 @@
+package env
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Defaults of a GCPSecretSource.
+const (
+	defaultGCPEndpoint     = "https://secretmanager.googleapis.com"
+	defaultGCPMetadataHost = "metadata.google.internal"
+	gcpScope               = "https://www.googleapis.com/auth/cloud-platform"
+	gcpConcurrency         = 8
+)
+
+// GCPSecretSource is a Source backed by the secrets of Google Cloud Secret
+// Manager whose ids start with a prefix. The latest version of the secret
+// "app_db-password" under the prefix "app_" provides the variable DB_PASSWORD:
+// the prefix is removed, dashes are replaced by underscores, and names are
+// matched case-insensitively.
+//
+// Secret Manager is accessed over its HTTP API, with an OAuth 2.0 access token.
+// The token is obtained, and renewed before it expires, using the key of a
+// service account, signed with RS256, or from the metadata server of the
+// instance the program runs on. Every secret under the prefix is read at once,
+// by Load or by the first lookup, and kept until Forget is called. A
+// GCPSecretSource is safe for concurrent use.
+type GCPSecretSource struct {
+	project  string
+	prefix   string
+	endpoint string
+	metadata string
+	token    string
+	key      *gcpKey
+	keyJSON  []byte
+	timeout  time.Duration
+	client   *http.Client
+
+	mu          sync.Mutex
+	values      map[string]string
+	accessToken string
+	tokenExpiry time.Time
+}
+
+// gcpKey is the key of a Google Cloud service account, as downloaded in JSON.
+type gcpKey struct {
+	Type         string `json:"type"`
+	ClientEmail  string `json:"client_email"`
+	PrivateKeyID string `json:"private_key_id"`
+	PrivateKey   string `json:"private_key"`
+	TokenURI     string `json:"token_uri"`
+
+	rsa *rsa.PrivateKey
+}
+
+// GCPOption configures a GCPSecretSource.
+type GCPOption func(*GCPSecretSource)
+
+// GCPToken makes a GCPSecretSource authenticate with the access token provided,
+// as printed by "gcloud auth print-access-token".
+func GCPToken(token string) GCPOption {
+	return func(g *GCPSecretSource) {
+		g.token = token
+	}
+}
+
+// GCPServiceAccountKey makes a GCPSecretSource authenticate as the service
+// account whose JSON key is provided. The default is the key in the file named
+// by the environment variable GOOGLE_APPLICATION_CREDENTIALS, or else the
+// service account of the instance, obtained from the metadata server.
+func GCPServiceAccountKey(keyJSON []byte) GCPOption {
+	return func(g *GCPSecretSource) {
+		g.keyJSON = keyJSON
+	}
+}
+
+// GCPEndpoint sets the URL of the Secret Manager API. The default is
+// "https://secretmanager.googleapis.com".
+func GCPEndpoint(endpoint string) GCPOption {
+	return func(g *GCPSecretSource) {
+		g.endpoint = endpoint
+	}
+}
+
+// GCPMetadataHost sets the host of the metadata server tokens are obtained
+// from when no token or key is provided. The default is the value of the
+// environment variable GCE_METADATA_HOST, or "metadata.google.internal".
+func GCPMetadataHost(host string) GCPOption {
+	return func(g *GCPSecretSource) {
+		g.metadata = host
+	}
+}
+
+// GCPTimeout sets the time a request is given to complete, after which it
+// fails. The default is 10 seconds.
+func GCPTimeout(d time.Duration) GCPOption {
+	return func(g *GCPSecretSource) {
+		g.timeout = d
+	}
+}
+
+// GCPHTTPClient makes a GCPSecretSource use c to send requests. The default is
+// http.DefaultClient; either way, requests are limited by GCPTimeout.
+func GCPHTTPClient(c *http.Client) GCPOption {
+	return func(g *GCPSecretSource) {
+		g.client = c
+	}
+}
+
+// NewGCPSecretSource returns a GCPSecretSource for the secrets of project whose
+// ids start with prefix, such as "app_". An empty prefix selects every secret.
+func NewGCPSecretSource(project, prefix string, opts ...GCPOption) (*GCPSecretSource, error) {
+	g := &GCPSecretSource{
+		project:  project,
+		prefix:   prefix,
+		endpoint: defaultGCPEndpoint,
+		metadata: os.Getenv("GCE_METADATA_HOST"),
+		timeout:  defaultRequestTimeout,
+		client:   http.DefaultClient,
+	}
+	if len(g.metadata) == 0 {
+		g.metadata = defaultGCPMetadataHost
+	}
+	for _, opt := range opts {
+		opt(g)
+	}
+	g.endpoint = strings.TrimSuffix(g.endpoint, "/")
+
+	if len(project) == 0 {
+		return nil, errors.New("env: GCP project is required")
+	}
+	if u, err := url.Parse(g.endpoint); err != nil || len(u.Host) == 0 {
+		return nil, fmt.Errorf("env: invalid GCP endpoint %q", g.endpoint)
+	}
+
+	if len(g.token) == 0 && len(g.keyJSON) == 0 {
+		if path := os.Getenv("GOOGLE_APPLICATION_CREDENTIALS"); len(path) > 0 {
+			b, err := os.ReadFile(path)
+			if err != nil {
+				return nil, fmt.Errorf("env: GCP credentials: %w", err)
+			}
+			g.keyJSON = b
+		}
+	}
+	if len(g.token) == 0 && len(g.keyJSON) > 0 {
+		key, err := parseGCPKey(g.keyJSON)
+		if err != nil {
+			return nil, fmt.Errorf("env: GCP credentials: %w", err)
+		}
+		g.key = key
+	}
+
+	return g, nil
+}
+
+// parseGCPKey parses the JSON key of a service account.
+func parseGCPKey(b []byte) (*gcpKey, error) {
+	var key gcpKey
+	if err := json.Unmarshal(b, &key); err != nil {
+		return nil, err
+	}
+	if key.Type != "service_account" || len(key.ClientEmail) == 0 || len(key.TokenURI) == 0 {
+		return nil, errors.New("not a service account key")
+	}
+
+	block, _ := pem.Decode([]byte(key.PrivateKey))
+	if block == nil {
+		return nil, errors.New("invalid private key")
+	}
+	parsed, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		parsed, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	}
+	if err != nil {
+		return nil, errors.New("invalid private key")
+	}
+	rsaKey, ok := parsed.(*rsa.PrivateKey)
+	if !ok {
+		return nil, errors.New("private key is not an RSA key")
+	}
+	key.rsa = rsaKey
+
+	return &key, nil
+}
+
+// Load reads the latest version of every secret under the prefix, replacing
+// those read before. Use it at startup to report failures early, instead of at
+// the first lookup.
+func (g *GCPSecretSource) Load(ctx context.Context) error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	return g.load(ctx)
+}
+
+// load lists the secrets under the prefix, and then accesses their latest
+// versions concurrently. It must be called with g.mu held.
+func (g *GCPSecretSource) load(ctx context.Context) error {
+	token, err := g.currentToken(ctx)
+	if err != nil {
+		return err
+	}
+
+	var names []string
+	q := url.Values{"pageSize": {"100"}}
+	if len(g.prefix) > 0 {
+		q.Set("filter", "name:"+g.prefix)
+	}
+	for {
+		var resp struct {
+			Secrets []struct {
+				Name string `json:"name"`
+			} `json:"secrets"`
+			NextPageToken string `json:"nextPageToken"`
+		}
+		endpoint := "/v1/projects/" + url.PathEscape(g.project) + "/secrets?" + q.Encode()
+		if _, err := g.get(ctx, token, endpoint, &resp); err != nil {
+			return err
+		}
+		for _, s := range resp.Secrets {
+			// Names have the form "projects/<project>/secrets/<id>".
+			id := s.Name[strings.LastIndexByte(s.Name, '/')+1:]
+			if strings.HasPrefix(id, g.prefix) {
+				names = append(names, s.Name)
+			}
+		}
+		if len(resp.NextPageToken) == 0 {
+			break
+		}
+		q.Set("pageToken", resp.NextPageToken)
+	}
+
+	type result struct {
+		name, value string
+		ok          bool
+		err         error
+	}
+	results := make(chan result, len(names))
+	limit := make(chan struct{}, gcpConcurrency)
+	for _, name := range names {
+		go func(name string) {
+			limit <- struct{}{}
+			defer func() { <-limit }()
+
+			value, ok, err := g.access(ctx, token, name)
+			results <- result{name: name, value: value, ok: ok, err: err}
+		}(name)
+	}
+
+	values := make(map[string]string, len(names))
+	var firstErr error
+	for range names {
+		r := <-results
+		switch {
+		case r.err != nil:
+			if firstErr == nil {
+				firstErr = r.err
+			}
+		case r.ok:
+			id := r.name[strings.LastIndexByte(r.name, '/')+1:]
+			values[strings.ReplaceAll(strings.TrimPrefix(id, g.prefix), "-", "_")] = r.value
+		}
+	}
+	if firstErr != nil {
+		return firstErr
+	}
+	g.values = values
+
+	return nil
+}
+
+// access reads the latest version of the secret named by name, and reports
+// whether it has one.
+func (g *GCPSecretSource) access(ctx context.Context, token, name string) (string, bool, error) {
+	var resp struct {
+		Payload struct {
+			Data []byte `json:"data"`
+		} `json:"payload"`
+	}
+	status, err := g.get(ctx, token, "/v1/"+name+"/versions/latest:access", &resp)
+	if err != nil || status == http.StatusNotFound {
+		return "", false, err
+	}
+
+	return string(resp.Payload.Data), true, nil
+}
+
+// get sends a GET request to the Secret Manager API, and decodes the response
+// into out. A response with status 404 is returned without an error, along
+// with its status, so that callers can handle it.
+func (g *GCPSecretSource) get(ctx context.Context, token, endpoint string, out any) (int, error) {
+	ctx, cancel := context.WithTimeout(ctx, g.timeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, g.endpoint+endpoint, nil)
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+
+	resp, err := g.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	path := endpoint
+	if i := strings.IndexByte(path, '?'); i >= 0 {
+		path = path[:i]
+	}
+	switch {
+	case resp.StatusCode == http.StatusNotFound:
+		return resp.StatusCode, nil
+	case resp.StatusCode >= 300:
+		var e struct {
+			Error struct {
+				Message string `json:"message"`
+				Status  string `json:"status"`
+			} `json:"error"`
+		}
+		_ = json.NewDecoder(resp.Body).Decode(&e)
+		if len(e.Error.Status) > 0 {
+			return resp.StatusCode, fmt.Errorf("GET %s: %s: %s", path, e.Error.Status, e.Error.Message)
+		}
+		return resp.StatusCode, fmt.Errorf("GET %s: %s", path, resp.Status)
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return resp.StatusCode, fmt.Errorf("GET %s: invalid response: %w", path, err)
+	}
+
+	return resp.StatusCode, nil
+}
+
+// currentToken returns the access token to authenticate with, obtaining a new
+// one if there is none or it expires within a minute. It must be called with
+// g.mu held.
+func (g *GCPSecretSource) currentToken(ctx context.Context) (string, error) {
+	if len(g.token) > 0 {
+		return g.token, nil
+	}
+	if len(g.accessToken) > 0 && time.Now().Add(time.Minute).Before(g.tokenExpiry) {
+		return g.accessToken, nil
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, g.timeout)
+	defer cancel()
+
+	var req *http.Request
+	var err error
+	if g.key != nil {
+		req, err = g.key.tokenRequest(ctx, time.Now())
+	} else {
+		req, err = http.NewRequestWithContext(ctx, http.MethodGet, "http://"+g.metadata+"/computeMetadata/v1/instance/service-accounts/default/token", nil)
+		if err == nil {
+			req.Header.Set("Metadata-Flavor", "Google")
+		}
+	}
+	if err != nil {
+		return "", err
+	}
+
+	resp, err := g.client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	var tok struct {
+		AccessToken string `json:"access_token"`
+		ExpiresIn   int    `json:"expires_in"`
+		Error       string `json:"error"`
+		Description string `json:"error_description"`
+	}
+	_ = json.NewDecoder(resp.Body).Decode(&tok)
+	switch {
+	case len(tok.Error) > 0:
+		return "", fmt.Errorf("access token: %s: %s", tok.Error, tok.Description)
+	case resp.StatusCode >= 300:
+		return "", fmt.Errorf("access token: %s", resp.Status)
+	case len(tok.AccessToken) == 0:
+		return "", errors.New("access token: response has no token")
+	}
+
+	g.accessToken = tok.AccessToken
+	g.tokenExpiry = time.Now().Add(time.Duration(tok.ExpiresIn) * time.Second)
+
+	return g.accessToken, nil
+}
+
+// tokenRequest returns a request that exchanges a JWT, signed with the key at
+// time t, for an access token.
+func (k *gcpKey) tokenRequest(ctx context.Context, t time.Time) (*http.Request, error) {
+	header, err := json.Marshal(map[string]string{"alg": "RS256", "typ": "JWT", "kid": k.PrivateKeyID})
+	if err != nil {
+		return nil, err
+	}
+	claims, err := json.Marshal(map[string]any{
+		"iss":   k.ClientEmail,
+		"scope": gcpScope,
+		"aud":   k.TokenURI,
+		"iat":   t.Unix(),
+		"exp":   t.Add(time.Hour).Unix(),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	enc := base64.RawURLEncoding
+	unsigned := enc.EncodeToString(header) + "." + enc.EncodeToString(claims)
+	sum := sha256.Sum256([]byte(unsigned))
+	sig, err := rsa.SignPKCS1v15(rand.Reader, k.rsa, crypto.SHA256, sum[:])
+	if err != nil {
+		return nil, err
+	}
+
+	form := url.Values{
+		"grant_type": {"urn:ietf:params:oauth:grant-type:jwt-bearer"},
+		"assertion":  {unsigned + "." + enc.EncodeToString(sig)},
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, k.TokenURI, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	return req, nil
+}
+
+// Lookup retrieves the secret that matches name, reading every secret under the
+// prefix unless they have been read.
+func (g *GCPSecretSource) Lookup(name string) (string, bool, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if g.values == nil {
+		if err := g.load(context.Background()); err != nil {
+			return "", false, err
+		}
+	}
+
+	return matchName(g.values, name)
+}
+
+// Sensitive reports true, so that the secrets are redacted by Dump. See
+// SensitiveSource.
+func (g *GCPSecretSource) Sensitive() bool {
+	return true
+}
+
+// Forget removes the secrets read, so that they are read again by the next
+// lookup.
+func (g *GCPSecretSource) Forget() {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	g.values = nil
+}
+
+// String returns the name of g, as in "gcp:my-project/app_".
+func (g *GCPSecretSource) String() string {
+	return "gcp:" + g.project + "/" + g.prefix
+}
@@ -0,0 +1,250 @@
+package env_test
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/christgf/env"
+)
+
+// fakeGCP is a stand-in for the Secret Manager API and the OAuth 2.0 token
+// endpoint of Google Cloud, which checks the signature of JWT assertions.
+type fakeGCP struct {
+	t       *testing.T
+	key     *rsa.PrivateKey
+	secrets map[string]string
+
+	mu       sync.Mutex
+	tokens   int
+	accesses int
+}
+
+func newFakeGCP(t *testing.T, secrets map[string]string) (*fakeGCP, *httptest.Server) {
+	t.Helper()
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("GenerateKey(): %v", err)
+	}
+	fg := &fakeGCP{t: t, key: key, secrets: secrets}
+	srv := httptest.NewServer(fg)
+	t.Cleanup(srv.Close)
+
+	return fg, srv
+}
+
+// keyJSON returns a service account key for fg, whose token endpoint is srv.
+func (fg *fakeGCP) keyJSON(srv *httptest.Server) []byte {
+	der, err := x509.MarshalPKCS8PrivateKey(fg.key)
+	if err != nil {
+		fg.t.Fatalf("MarshalPKCS8PrivateKey(): %v", err)
+	}
+	b, _ := json.Marshal(map[string]string{
+		"type":           "service_account",
+		"client_email":   "app@my-project.iam.gserviceaccount.com",
+		"private_key_id": "k1",
+		"private_key":    string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der})),
+		"token_uri":      srv.URL + "/token",
+	})
+
+	return b
+}
+
+func (fg *fakeGCP) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	fg.mu.Lock()
+	defer fg.mu.Unlock()
+
+	if r.URL.Path == "/token" {
+		fg.token(w, r)
+		return
+	}
+	if r.URL.Path == "/computeMetadata/v1/instance/service-accounts/default/token" && r.Header.Get("Metadata-Flavor") == "Google" {
+		fg.tokens++
+		fmt.Fprint(w, `{"access_token":"metadata-token","expires_in":3599,"token_type":"Bearer"}`)
+		return
+	}
+
+	switch r.Header.Get("Authorization") {
+	case "Bearer sa-token", "Bearer metadata-token":
+	default:
+		w.WriteHeader(http.StatusUnauthorized)
+		fmt.Fprint(w, `{"error":{"code":401,"message":"Request had invalid authentication credentials.","status":"UNAUTHENTICATED"}}`)
+		return
+	}
+
+	const list = "/v1/projects/my-project/secrets"
+	switch {
+	case r.URL.Path == list:
+		var ids []string
+		for id := range fg.secrets {
+			ids = append(ids, id)
+		}
+		sort.Strings(ids)
+
+		// Serve the secrets in two pages.
+		half := len(ids) / 2
+		page, next := ids[:half], "p2"
+		if r.URL.Query().Get("pageToken") == "p2" {
+			page, next = ids[half:], ""
+		}
+		var secrets []map[string]string
+		for _, id := range page {
+			secrets = append(secrets, map[string]string{"name": "projects/123/secrets/" + id})
+		}
+		_ = json.NewEncoder(w).Encode(map[string]any{"secrets": secrets, "nextPageToken": next})
+	case strings.HasPrefix(r.URL.Path, "/v1/projects/123/secrets/") && strings.HasSuffix(r.URL.Path, "/versions/latest:access"):
+		fg.accesses++
+		id := strings.TrimSuffix(strings.TrimPrefix(r.URL.Path, "/v1/projects/123/secrets/"), "/versions/latest:access")
+		value, ok := fg.secrets[id]
+		if !ok || value == "" {
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprint(w, `{"error":{"code":404,"message":"Secret has no versions.","status":"NOT_FOUND"}}`)
+			return
+		}
+		fmt.Fprintf(w, `{"name":%q,"payload":{"data":%q}}`, id, base64.StdEncoding.EncodeToString([]byte(value)))
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+// token exchanges a JWT assertion signed with the key of fg for an access
+// token.
+func (fg *fakeGCP) token(w http.ResponseWriter, r *http.Request) {
+	fg.tokens++
+
+	fail := func(msg string) {
+		w.WriteHeader(http.StatusBadRequest)
+		fmt.Fprintf(w, `{"error":"invalid_grant","error_description":%q}`, msg)
+	}
+	if r.PostFormValue("grant_type") != "urn:ietf:params:oauth:grant-type:jwt-bearer" {
+		fail("unexpected grant type")
+		return
+	}
+	parts := strings.Split(r.PostFormValue("assertion"), ".")
+	if len(parts) != 3 {
+		fail("malformed assertion")
+		return
+	}
+	sig, _ := base64.RawURLEncoding.DecodeString(parts[2])
+	sum := sha256.Sum256([]byte(parts[0] + "." + parts[1]))
+	if err := rsa.VerifyPKCS1v15(&fg.key.PublicKey, crypto.SHA256, sum[:], sig); err != nil {
+		fail("invalid signature")
+		return
+	}
+	claims, _ := base64.RawURLEncoding.DecodeString(parts[1])
+	var c struct {
+		Iss   string `json:"iss"`
+		Scope string `json:"scope"`
+	}
+	if err := json.Unmarshal(claims, &c); err != nil || c.Iss != "app@my-project.iam.gserviceaccount.com" || c.Scope == "" {
+		fail("invalid claims")
+		return
+	}
+
+	fmt.Fprint(w, `{"access_token":"sa-token","expires_in":3599,"token_type":"Bearer"}`)
+}
+
+func TestGCPSecretSource(t *testing.T) {
+	fg, srv := newFakeGCP(t, map[string]string{
+		"app_db-password": "hunter2",
+		"app_PORT":        "9000",
+		"app_API_KEY":     "k3y",
+		"app_disabled":    "",
+		"other_secret":    "nope",
+	})
+
+	src, err := env.NewGCPSecretSource("my-project", "app_", env.GCPServiceAccountKey(fg.keyJSON(srv)), env.GCPEndpoint(srv.URL))
+	if err != nil {
+		t.Fatalf("NewGCPSecretSource(): %v", err)
+	}
+	if err := src.Load(context.Background()); err != nil {
+		t.Fatalf("Load(): %v", err)
+	}
+
+	var vs env.VarSet
+	vs.SetSources(src)
+	if got, want := vs.String("DB_PASSWORD", ""), "hunter2"; got != want {
+		t.Errorf("String(%q): got %q, want %q", "DB_PASSWORD", got, want)
+	}
+	if got, want := vs.Int("PORT", 0), 9000; got != want {
+		t.Errorf("Int(%q): got %d, want %d", "PORT", got, want)
+	}
+	if got, want := vs.String("API_KEY", ""), "k3y"; got != want {
+		t.Errorf("String(%q): got %q, want %q", "API_KEY", got, want)
+	}
+	for _, key := range []string{"DISABLED", "SECRET", "OTHER_SECRET"} {
+		if got, want := vs.String(key, "none"), "none"; got != want {
+			t.Errorf("String(%q): got %q, want %q", key, got, want)
+		}
+	}
+	checkRedacted(t, &vs, "DB_PASSWORD", "hunter2")
+
+	// Loading again reuses the access token.
+	if err := src.Load(context.Background()); err != nil {
+		t.Fatalf("Load(): %v", err)
+	}
+	fg.mu.Lock()
+	tokens, accesses := fg.tokens, fg.accesses
+	fg.mu.Unlock()
+	if tokens != 1 || accesses != 8 {
+		t.Errorf("got %d tokens and %d accesses, want 1 and 8", tokens, accesses)
+	}
+	if got, want := src.String(), "gcp:my-project/app_"; got != want {
+		t.Errorf("String(): got %q, want %q", got, want)
+	}
+}
+
+func TestGCPSecretSource_metadata(t *testing.T) {
+	t.Setenv("GOOGLE_APPLICATION_CREDENTIALS", "")
+	_, srv := newFakeGCP(t, map[string]string{"PORT": "9000"})
+
+	src, err := env.NewGCPSecretSource("my-project", "", env.GCPMetadataHost(srv.Listener.Addr().String()), env.GCPEndpoint(srv.URL))
+	if err != nil {
+		t.Fatalf("NewGCPSecretSource(): %v", err)
+	}
+	if value, ok, err := src.Lookup("PORT"); value != "9000" || !ok || err != nil {
+		t.Errorf("Lookup(%q): got %q, %v, %v, want %q", "PORT", value, ok, err, "9000")
+	}
+}
+
+func TestGCPSecretSource_unauthenticated(t *testing.T) {
+	_, srv := newFakeGCP(t, map[string]string{"PORT": "9000"})
+
+	src, err := env.NewGCPSecretSource("my-project", "", env.GCPToken("expired"), env.GCPEndpoint(srv.URL))
+	if err != nil {
+		t.Fatalf("NewGCPSecretSource(): %v", err)
+	}
+	_, ok, err := src.Lookup("PORT")
+	if ok || err == nil || !strings.Contains(err.Error(), "UNAUTHENTICATED") {
+		t.Errorf("Lookup(): got %v, %v, want UNAUTHENTICATED", ok, err)
+	}
+
+	if _, err := env.NewGCPSecretSource("my-project", "", env.GCPServiceAccountKey([]byte(`{"type":"authorized_user"}`))); err == nil {
+		t.Errorf("NewGCPSecretSource(): got no error for a key that is not a service account key")
+	}
+}
+
+func TestGCPSecretSource_timeout(t *testing.T) {
+	srv := newHangingServer(t)
+
+	src, err := env.NewGCPSecretSource("my-project", "", env.GCPToken("token"), env.GCPEndpoint(srv.URL), env.GCPTimeout(50*time.Millisecond))
+	if err != nil {
+		t.Fatalf("NewGCPSecretSource(): %v", err)
+	}
+	checkTimeout(t, src)
+}
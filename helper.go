@@ -0,0 +1,166 @@
+package env
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// Defaults of a HelperSource.
+const (
+	defaultHelperTimeout  = 10 * time.Second
+	defaultHelperCacheTTL = 5 * time.Minute
+)
+
+// HelperSource is a Source that obtains values by running a local helper
+// program, in the manner of git or Docker credential helpers, so that secrets
+// can be retrieved from a password manager without being exported into the
+// environment. For every lookup, the helper is run with its arguments followed
+// by "get", and it is given a JSON object naming the variable on its standard
+// input:
+//
+//	{"key": "GITHUB_TOKEN"}
+//
+// It must write a JSON object to its standard output, holding the value of the
+// variable if it knows it, and no value otherwise:
+//
+//	{"value": "ghp_..."}
+//	{}
+//
+// It may report a failure with an object such as {"error": "vault is locked"},
+// or with an exit status other than zero and a message on its standard error.
+// Results, including unknown variables, are cached for a while; failures are
+// not. A HelperSource is safe for concurrent use.
+type HelperSource struct {
+	command string
+	args    []string
+	timeout time.Duration
+	ttl     time.Duration
+
+	mu    sync.Mutex
+	cache map[string]helperResult
+}
+
+// helperResult is a cached result of a HelperSource.
+type helperResult struct {
+	value   string
+	ok      bool
+	expires time.Time
+}
+
+// HelperOption configures a HelperSource.
+type HelperOption func(*HelperSource)
+
+// HelperTimeout sets the time the helper is given to respond to a lookup, after
+// which it is killed. The default is 10 seconds.
+func HelperTimeout(d time.Duration) HelperOption {
+	return func(h *HelperSource) {
+		h.timeout = d
+	}
+}
+
+// HelperCacheTTL sets the time results are cached for. The default is 5
+// minutes, and zero disables caching.
+func HelperCacheTTL(d time.Duration) HelperOption {
+	return func(h *HelperSource) {
+		h.ttl = d
+	}
+}
+
+// NewHelperSource returns a HelperSource that runs command with args. The
+// command is not run by a shell.
+func NewHelperSource(command string, args []string, opts ...HelperOption) *HelperSource {
+	h := &HelperSource{
+		command: command,
+		args:    args,
+		timeout: defaultHelperTimeout,
+		ttl:     defaultHelperCacheTTL,
+		cache:   make(map[string]helperResult),
+	}
+	for _, opt := range opts {
+		opt(h)
+	}
+
+	return h
+}
+
+// Lookup runs the helper to retrieve the value of the variable named by name,
+// unless a result for name is cached.
+func (h *HelperSource) Lookup(name string) (string, bool, error) {
+	h.mu.Lock()
+	res, cached := h.cache[name]
+	h.mu.Unlock()
+
+	if cached && time.Now().Before(res.expires) {
+		return res.value, res.ok, nil
+	}
+
+	value, ok, err := h.get(name)
+	if err != nil {
+		return "", false, err
+	}
+
+	if h.ttl > 0 {
+		h.mu.Lock()
+		h.cache[name] = helperResult{value: value, ok: ok, expires: time.Now().Add(h.ttl)}
+		h.mu.Unlock()
+	}
+
+	return value, ok, nil
+}
+
+// get runs the helper for name.
+func (h *HelperSource) get(name string) (string, bool, error) {
+	req, err := json.Marshal(struct {
+		Key string `json:"key"`
+	}{Key: name})
+	if err != nil {
+		return "", false, err
+	}
+
+	args := append(append([]string(nil), h.args...), "get")
+	out, err := runCommand(h.timeout, bytes.NewReader(req), h.command, args...)
+	if err != nil {
+		return "", false, err
+	}
+
+	var resp struct {
+		Value *string `json:"value"`
+		Error string  `json:"error"`
+	}
+	if err := json.Unmarshal(out, &resp); err != nil {
+		return "", false, fmt.Errorf("invalid response from helper: %w", err)
+	}
+	if len(resp.Error) > 0 {
+		return "", false, errors.New(resp.Error)
+	}
+	if resp.Value == nil {
+		return "", false, nil
+	}
+
+	return *resp.Value, true, nil
+}
+
+// Sensitive reports true, so that the values provided by the helper are
+// redacted by Dump. See SensitiveSource.
+func (h *HelperSource) Sensitive() bool {
+	return true
+}
+
+// Forget removes every cached result, so that the helper is run again for the
+// next lookups.
+func (h *HelperSource) Forget() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.cache = make(map[string]helperResult)
+}
+
+// String returns the name of h, as in "helper:pass-helper".
+func (h *HelperSource) String() string {
+	return "helper:" + filepath.Base(h.command)
+}
@@ -0,0 +1,140 @@
+package env_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/christgf/env"
+)
+
+func newHelperSource(t *testing.T, opts ...env.HelperOption) (*env.HelperSource, func() int) {
+	t.Helper()
+
+	log := filepath.Join(t.TempDir(), "calls")
+	t.Setenv("ENV_TEST_HELPER_SOURCE", log)
+
+	calls := func() int {
+		b, err := os.ReadFile(log)
+		if err != nil {
+			return 0
+		}
+		return strings.Count(string(b), "\n")
+	}
+
+	return env.NewHelperSource(os.Args[0], []string{"-test.run=TestHelperSourceProcess", "--"}, opts...), calls
+}
+
+func TestHelperSource(t *testing.T) {
+	h, calls := newHelperSource(t)
+
+	var vs env.VarSet
+	vs.SetSources(h)
+	if got, want := vs.String("GITHUB_TOKEN", ""), "ghp_s3cret"; got != want {
+		t.Errorf("String(%q): got %q, want %q", "GITHUB_TOKEN", got, want)
+	}
+	if got, want := vs.String("GITHUB_TOKEN", ""), "ghp_s3cret"; got != want {
+		t.Errorf("String(%q): got %q, want %q", "GITHUB_TOKEN", got, want)
+	}
+	if got, want := vs.String("UNKNOWN", "fallback"), "fallback"; got != want {
+		t.Errorf("String(%q): got %q, want %q", "UNKNOWN", got, want)
+	}
+	vs.String("UNKNOWN", "fallback")
+	if got, want := calls(), 2; got != want {
+		t.Errorf("helper called %d times, want %d", got, want)
+	}
+	checkRedacted(t, &vs, "GITHUB_TOKEN", "ghp_s3cret")
+
+	h.Forget()
+	vs.String("GITHUB_TOKEN", "")
+	if got, want := calls(), 3; got != want {
+		t.Errorf("helper called %d times, want %d", got, want)
+	}
+
+	if got, want := h.String(), "helper:"+filepath.Base(os.Args[0]); got != want {
+		t.Errorf("String(): got %q, want %q", got, want)
+	}
+}
+
+func TestHelperSource_errors(t *testing.T) {
+	h, calls := newHelperSource(t, env.HelperCacheTTL(0))
+
+	tests := map[string]string{
+		"LOCKED":  "vault is locked",
+		"CRASH":   "exit status 3: helper crashed",
+		"GARBAGE": "invalid response from helper",
+	}
+	for name, wantErr := range tests {
+		_, ok, err := h.Lookup(name)
+		if ok || err == nil || !strings.Contains(err.Error(), wantErr) {
+			t.Errorf("Lookup(%q): got %v, %v, want error %q", name, ok, err, wantErr)
+		}
+	}
+
+	h.Lookup("GITHUB_TOKEN")
+	h.Lookup("GITHUB_TOKEN")
+	if got, want := calls(), len(tests)+2; got != want {
+		t.Errorf("helper called %d times, want %d", got, want)
+	}
+}
+
+func TestHelperSource_timeout(t *testing.T) {
+	h, _ := newHelperSource(t, env.HelperTimeout(100*time.Millisecond))
+
+	start := time.Now()
+	if _, _, err := h.Lookup("SLOW"); err == nil || !strings.Contains(err.Error(), "deadline exceeded") {
+		t.Errorf("Lookup(%q): got error %v, want deadline exceeded", "SLOW", err)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("Lookup(%q): took %v", "SLOW", elapsed)
+	}
+}
+
+// TestHelperSourceProcess is run as a credential helper by the tests of
+// HelperSource.
+func TestHelperSourceProcess(t *testing.T) {
+	log := os.Getenv("ENV_TEST_HELPER_SOURCE")
+	if len(log) == 0 {
+		t.Skip("helper process")
+	}
+
+	if args := os.Args; args[len(args)-1] != "get" {
+		fmt.Fprintf(os.Stderr, "unexpected arguments %q\n", args)
+		os.Exit(2)
+	}
+	var req struct {
+		Key string `json:"key"`
+	}
+	if err := json.NewDecoder(os.Stdin).Decode(&req); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+
+	f, err := os.OpenFile(log, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+	if err != nil {
+		os.Exit(2)
+	}
+	fmt.Fprintln(f, req.Key)
+	f.Close()
+
+	switch req.Key {
+	case "GITHUB_TOKEN":
+		fmt.Println(`{"value": "ghp_s3cret"}`)
+	case "LOCKED":
+		fmt.Println(`{"error": "vault is locked"}`)
+	case "CRASH":
+		fmt.Fprintln(os.Stderr, "helper crashed")
+		os.Exit(3)
+	case "GARBAGE":
+		fmt.Println("ghp_s3cret")
+	case "SLOW":
+		time.Sleep(time.Minute)
+	default:
+		fmt.Println(`{}`)
+	}
+	os.Exit(0)
+}
@@ -0,0 +1,294 @@
+package env
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+)
+
+// Defaults of an HTTPSource.
+const (
+	defaultHTTPPollInterval = 30 * time.Second
+	defaultHTTPCacheTTL     = 5 * time.Minute
+)
+
+// HTTPSource is a Source backed by a JSON object served over HTTP, such as
+// {"DB_HOST": "db.internal", "PORT": 5432}. Every member of the object provides
+// the variable of the same name; names are matched case-insensitively, and
+// values that are not strings are used as JSON text. Members whose value is
+// null are not present.
+//
+// The object is read once and cached for the cache TTL, unless the source is
+// watched: Watch polls the server, using the ETag of the last response in an
+// If-None-Match header, so that an unchanged object is not sent again. An
+// HTTPSource is safe for concurrent use.
+type HTTPSource struct {
+	url      *url.URL
+	header   http.Header
+	interval time.Duration
+	ttl      time.Duration
+	timeout  time.Duration
+	client   *http.Client
+
+	mu       sync.Mutex
+	values   map[string]string
+	etag     string
+	till     time.Time
+	watching int
+}
+
+// HTTPOption configures an HTTPSource.
+type HTTPOption func(*HTTPSource)
+
+// HTTPHeader makes an HTTPSource send the header name with value in every
+// request, such as an Authorization header.
+func HTTPHeader(name, value string) HTTPOption {
+	return func(h *HTTPSource) {
+		h.header.Add(name, value)
+	}
+}
+
+// HTTPPollInterval sets the time Watch waits between requests. The default is
+// 30 seconds.
+func HTTPPollInterval(d time.Duration) HTTPOption {
+	return func(h *HTTPSource) {
+		h.interval = d
+	}
+}
+
+// HTTPCacheTTL sets the time the object is cached for while the source is not
+// watched. The default is 5 minutes, and zero disables caching.
+func HTTPCacheTTL(d time.Duration) HTTPOption {
+	return func(h *HTTPSource) {
+		h.ttl = d
+	}
+}
+
+// HTTPTimeout sets the time a request is given to complete, after which it
+// fails. The default is 10 seconds.
+func HTTPTimeout(d time.Duration) HTTPOption {
+	return func(h *HTTPSource) {
+		h.timeout = d
+	}
+}
+
+// HTTPClient makes an HTTPSource use c to send requests. The default is
+// http.DefaultClient; either way, requests are limited by HTTPTimeout.
+func HTTPClient(c *http.Client) HTTPOption {
+	return func(h *HTTPSource) {
+		h.client = c
+	}
+}
+
+// NewHTTPSource returns an HTTPSource for the JSON object served at rawURL,
+// which must be an absolute http or https URL.
+func NewHTTPSource(rawURL string, opts ...HTTPOption) (*HTTPSource, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || len(u.Host) == 0 {
+		return nil, fmt.Errorf("env: invalid source URL %q", rawURL)
+	}
+
+	h := &HTTPSource{
+		url:      u,
+		header:   make(http.Header),
+		interval: defaultHTTPPollInterval,
+		ttl:      defaultHTTPCacheTTL,
+		timeout:  defaultRequestTimeout,
+		client:   http.DefaultClient,
+	}
+	for _, opt := range opts {
+		opt(h)
+	}
+
+	return h, nil
+}
+
+// Lookup retrieves the member of the object that matches name, reading the
+// object unless it is cached or watched.
+func (h *HTTPSource) Lookup(name string) (string, bool, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.values == nil || (h.watching == 0 && !time.Now().Before(h.till)) {
+		if _, err := h.refresh(context.Background()); err != nil {
+			return "", false, err
+		}
+	}
+
+	return matchName(h.values, name)
+}
+
+// Watch keeps the object of h up to date until ctx is done, polling the server
+// and calling fn with the variables that changed whenever the object changes.
+// An object read before Watch was called is compared against the first object
+// it reads; otherwise, the first object is not reported as changes. Failed
+// requests are retried with increasing delays, up to a minute. Watch returns
+// ctx.Err().
+func (h *HTTPSource) Watch(ctx context.Context, fn func([]Change)) error {
+	h.mu.Lock()
+	h.watching++
+	h.mu.Unlock()
+
+	defer func() {
+		h.mu.Lock()
+		h.watching--
+		h.till = time.Now().Add(h.ttl)
+		h.mu.Unlock()
+	}()
+
+	var delay time.Duration
+	for {
+		// The object is read without h.mu held, so that lookups are not
+		// blocked by the request.
+		h.mu.Lock()
+		etag := h.currentETag()
+		h.mu.Unlock()
+
+		var changes []Change
+		values, etag, modified, err := h.fetch(ctx, etag)
+		if err == nil {
+			h.mu.Lock()
+			changes = h.update(values, etag, modified)
+			h.mu.Unlock()
+		}
+
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		wait := h.interval
+		if err != nil {
+			delay = retryDelay(delay)
+			wait = delay
+		} else {
+			delay = 0
+			if len(changes) > 0 {
+				fn(changes)
+			}
+		}
+
+		if !sleep(ctx, wait) {
+			return ctx.Err()
+		}
+	}
+}
+
+// Forget removes the cached object, so that it is read again by the next
+// lookup.
+func (h *HTTPSource) Forget() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.values, h.etag = nil, ""
+}
+
+// String returns the name of h, as in "http:config.internal/app.json".
+func (h *HTTPSource) String() string {
+	return "http:" + h.url.Host + h.url.Path
+}
+
+// refresh reads the object, unless it has not changed since it was last read,
+// and returns the changes from the object read before, if any. It must be
+// called with h.mu held.
+func (h *HTTPSource) refresh(ctx context.Context) ([]Change, error) {
+	values, etag, modified, err := h.fetch(ctx, h.currentETag())
+	if err != nil {
+		return nil, err
+	}
+
+	return h.update(values, etag, modified), nil
+}
+
+// currentETag returns the ETag of the object read last, if it is still kept.
+// It must be called with h.mu held.
+func (h *HTTPSource) currentETag() string {
+	if h.values == nil {
+		return ""
+	}
+
+	return h.etag
+}
+
+// update stores the object returned by fetch, unless it was not modified, and
+// returns the changes from the object read before, if any. It must be called
+// with h.mu held.
+func (h *HTTPSource) update(values map[string]string, etag string, modified bool) []Change {
+	h.till = time.Now().Add(h.ttl)
+	if !modified {
+		return nil
+	}
+
+	old := h.values
+	h.values, h.etag = values, etag
+	if old == nil {
+		return nil
+	}
+
+	return diffValues(old, values)
+}
+
+// fetch reads the object along with its ETag. If etag is not empty, it is sent
+// in an If-None-Match header, and fetch reports whether the object was
+// modified; if it was not, no values are returned.
+func (h *HTTPSource) fetch(ctx context.Context, etag string) (map[string]string, string, bool, error) {
+	ctx, cancel := context.WithTimeout(ctx, h.timeout)
+	defer cancel()
+
+	endpoint := h.url.Redacted()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, h.url.String(), nil)
+	if err != nil {
+		return nil, "", false, err
+	}
+	for name, values := range h.header {
+		req.Header[name] = values
+	}
+	req.Header.Set("Accept", "application/json")
+	if len(etag) > 0 {
+		req.Header.Set("If-None-Match", etag)
+	}
+
+	resp, err := h.client.Do(req)
+	if err != nil {
+		return nil, "", false, err
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode == http.StatusNotModified && len(etag) > 0:
+		return nil, etag, false, nil
+	case resp.StatusCode >= 300:
+		return nil, "", false, fmt.Errorf("GET %s: %s", endpoint, resp.Status)
+	}
+
+	var object map[string]json.RawMessage
+	if err := json.NewDecoder(resp.Body).Decode(&object); err != nil {
+		return nil, "", false, fmt.Errorf("GET %s: invalid response: %w", endpoint, err)
+	}
+	if object == nil {
+		return nil, "", false, fmt.Errorf("GET %s: invalid response: not an object", endpoint)
+	}
+
+	return jsonFields(object), resp.Header.Get("ETag"), true, nil
+}
+
+// jsonFields converts the members of a JSON object to values. Strings are
+// unquoted, members whose value is null are left out, and any other value is
+// kept as JSON text.
+func jsonFields(object map[string]json.RawMessage) map[string]string {
+	values := make(map[string]string, len(object))
+	for name, raw := range object {
+		if string(raw) == "null" {
+			continue
+		}
+		var s string
+		if err := json.Unmarshal(raw, &s); err != nil {
+			s = string(raw)
+		}
+		values[name] = s
+	}
+
+	return values
+}
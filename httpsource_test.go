@@ -0,0 +1,234 @@
+package env_test
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/christgf/env"
+)
+
+// fakeConfig serves a JSON object with an ETag, counting full and conditional
+// responses.
+type fakeConfig struct {
+	mu          sync.Mutex
+	body        string
+	version     int
+	sent        int
+	notModified int
+}
+
+func (fc *fakeConfig) set(body string) {
+	fc.mu.Lock()
+	defer fc.mu.Unlock()
+
+	fc.body = body
+	fc.version++
+}
+
+func (fc *fakeConfig) counts() (sent, notModified int) {
+	fc.mu.Lock()
+	defer fc.mu.Unlock()
+
+	return fc.sent, fc.notModified
+}
+
+func (fc *fakeConfig) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	fc.mu.Lock()
+	defer fc.mu.Unlock()
+
+	if r.Header.Get("Authorization") != "Bearer t0ken" {
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+
+	etag := fmt.Sprintf(`"v%d"`, fc.version)
+	if r.Header.Get("If-None-Match") == etag {
+		fc.notModified++
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+	fc.sent++
+	w.Header().Set("ETag", etag)
+	fmt.Fprint(w, fc.body)
+}
+
+func TestHTTPSource(t *testing.T) {
+	fc := &fakeConfig{body: `{"DB_HOST": "db.internal", "PORT": 5432, "debug": true, "REPLICA": null}`}
+	srv := httptest.NewServer(fc)
+	defer srv.Close()
+
+	src, err := env.NewHTTPSource(srv.URL+"/app.json", env.HTTPHeader("Authorization", "Bearer t0ken"), env.HTTPCacheTTL(0))
+	if err != nil {
+		t.Fatalf("NewHTTPSource(): %v", err)
+	}
+
+	var vs env.VarSet
+	vs.SetSources(src)
+	if got, want := vs.String("DB_HOST", ""), "db.internal"; got != want {
+		t.Errorf("String(%q): got %q, want %q", "DB_HOST", got, want)
+	}
+	if got, want := vs.Int("PORT", 0), 5432; got != want {
+		t.Errorf("Int(%q): got %d, want %d", "PORT", got, want)
+	}
+	if got, want := vs.Bool("DEBUG", false), true; got != want {
+		t.Errorf("Bool(%q): got %v, want %v", "DEBUG", got, want)
+	}
+	if got, want := vs.String("REPLICA", "none"), "none"; got != want {
+		t.Errorf("String(%q): got %q, want %q", "REPLICA", got, want)
+	}
+
+	// Without caching, every lookup sends a conditional request.
+	if sent, notModified := fc.counts(); sent != 1 || notModified != 3 {
+		t.Errorf("got %d full and %d conditional responses, want 1 and 3", sent, notModified)
+	}
+	if got, want := src.String(), "http:"+srv.Listener.Addr().String()+"/app.json"; got != want {
+		t.Errorf("String(): got %q, want %q", got, want)
+	}
+}
+
+func TestHTTPSource_errors(t *testing.T) {
+	fc := &fakeConfig{body: `["not", "an", "object"]`}
+	srv := httptest.NewServer(fc)
+	defer srv.Close()
+
+	tests := []struct {
+		name string
+		opts []env.HTTPOption
+	}{
+		{name: "unauthorized"},
+		{name: "not an object", opts: []env.HTTPOption{env.HTTPHeader("Authorization", "Bearer t0ken")}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			src, err := env.NewHTTPSource(srv.URL, tt.opts...)
+			if err != nil {
+				t.Fatalf("NewHTTPSource(): %v", err)
+			}
+			if _, ok, err := src.Lookup("PORT"); ok || err == nil {
+				t.Errorf("Lookup(): got %v, %v, want an error", ok, err)
+			}
+		})
+	}
+
+	for _, rawURL := range []string{"", "config.json", "ftp://example.com/config.json"} {
+		if _, err := env.NewHTTPSource(rawURL); err == nil {
+			t.Errorf("NewHTTPSource(%q): got no error", rawURL)
+		}
+	}
+}
+
+func TestHTTPSource_Watch(t *testing.T) {
+	fc := &fakeConfig{body: `{"PORT": "9000", "DEBUG": "false"}`}
+	srv := httptest.NewServer(fc)
+	defer srv.Close()
+
+	src, err := env.NewHTTPSource(srv.URL, env.HTTPHeader("Authorization", "Bearer t0ken"), env.HTTPPollInterval(20*time.Millisecond))
+	if err != nil {
+		t.Fatalf("NewHTTPSource(): %v", err)
+	}
+	if _, _, err := src.Lookup("PORT"); err != nil {
+		t.Fatalf("Lookup(): %v", err)
+	}
+
+	changes := make(chan []env.Change, 1)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		_ = src.Watch(ctx, func(c []env.Change) { changes <- c })
+	}()
+
+	time.Sleep(100 * time.Millisecond)
+	if sent, notModified := fc.counts(); sent != 1 || notModified == 0 {
+		t.Errorf("got %d full and %d conditional responses, want 1 and more than 0", sent, notModified)
+	}
+
+	fc.set(`{"PORT": "9001", "LOG_LEVEL": "debug"}`)
+	select {
+	case got := <-changes:
+		want := []env.Change{
+			{Name: "DEBUG", Deleted: true},
+			{Name: "LOG_LEVEL", Value: "debug"},
+			{Name: "PORT", Value: "9001"},
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("Watch(): got changes %v, want %v", got, want)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatalf("Watch(): got no changes")
+	}
+
+	if value, ok, err := src.Lookup("LOG_LEVEL"); value != "debug" || !ok || err != nil {
+		t.Errorf("Lookup(%q): got %q, %v, %v, want %q", "LOG_LEVEL", value, ok, err, "debug")
+	}
+}
+
+func TestHTTPSource_timeout(t *testing.T) {
+	srv := newHangingServer(t)
+
+	src, err := env.NewHTTPSource(srv.URL, env.HTTPTimeout(50*time.Millisecond))
+	if err != nil {
+		t.Fatalf("NewHTTPSource(): %v", err)
+	}
+	checkTimeout(t, src)
+}
+
+func TestHTTPSource_Watch_lookup(t *testing.T) {
+	polling := make(chan struct{}, 1)
+	release := make(chan struct{})
+	var once sync.Once
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// The first request is answered at once, and the polls of Watch are
+		// held until the test ends.
+		first := false
+		once.Do(func() { first = true })
+		if !first {
+			select {
+			case polling <- struct{}{}:
+			default:
+			}
+			select {
+			case <-release:
+			case <-r.Context().Done():
+			}
+			return
+		}
+		w.Header().Set("ETag", `"v1"`)
+		fmt.Fprint(w, `{"PORT": "9000"}`)
+	}))
+	defer srv.Close()
+	defer close(release)
+
+	src, err := env.NewHTTPSource(srv.URL, env.HTTPPollInterval(time.Millisecond))
+	if err != nil {
+		t.Fatalf("NewHTTPSource(): %v", err)
+	}
+	if _, _, err := src.Lookup("PORT"); err != nil {
+		t.Fatalf("Lookup(): %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		_ = src.Watch(ctx, func([]env.Change) {})
+	}()
+	<-polling
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		if value, ok, err := src.Lookup("PORT"); value != "9000" || !ok || err != nil {
+			t.Errorf("Lookup(%q): got %q, %v, %v, want %q", "PORT", value, ok, err, "9000")
+		}
+	}()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatalf("Lookup(): blocked by the poll of Watch")
+	}
+}
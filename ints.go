@@ -0,0 +1,258 @@
+package env
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+)
+
+// IntFormat controls the syntax of the integers parsed by ParseInt and
+// ParseUint, and by Int, Int64, Uint, Uint64 and the other integer getters of a
+// VarSet. The zero IntFormat accepts plain decimal integers only. Formats can be
+// combined, as in IntBasePrefix|IntMultipliers.
+type IntFormat uint8
+
+const (
+	// IntBasePrefix accepts integers with a base prefix and underscores, as in
+	// Go integer literals: "0x1F" is hexadecimal, "0o755" and "0755" are octal,
+	// "0b1010" is binary, and "1_000_000" is decimal.
+	IntBasePrefix IntFormat = 1 << iota
+
+	// IntMultipliers accepts integers followed by a decimal multiplier: "k" or
+	// "K" for 10^3, "M" for 10^6, "G" for 10^9, "T" for 10^12, "P" for 10^15
+	// and "E" for 10^18, as in "10k". Multipliers cannot follow hexadecimal
+	// integers.
+	IntMultipliers
+)
+
+// intMultipliers maps the suffixes accepted with IntMultipliers to the values
+// they stand for.
+var intMultipliers = map[byte]uint64{
+	'k': 1e3,
+	'K': 1e3,
+	'M': 1e6,
+	'G': 1e9,
+	'T': 1e12,
+	'P': 1e15,
+	'E': 1e18,
+}
+
+// splitMultiplier splits a multiplier suffix off s, if it has one.
+func splitMultiplier(s string) (string, uint64) {
+	if len(s) < 2 || strings.HasPrefix(strings.TrimLeft(s, "+-"), "0x") || strings.HasPrefix(strings.TrimLeft(s, "+-"), "0X") {
+		return s, 1
+	}
+	if m, ok := intMultipliers[s[len(s)-1]]; ok {
+		return s[:len(s)-1], m
+	}
+
+	return s, 1
+}
+
+// ParseInt is like strconv.ParseInt, but parses s according to the IntFormat f
+// rather than in a given base. The bitSize argument specifies the integer type
+// that the result must fit into, as with strconv.ParseInt; values that do not
+// fit result in an error wrapping strconv.ErrRange.
+func ParseInt(s string, f IntFormat, bitSize int) (int64, error) {
+	num, mult := s, uint64(1)
+	if f&IntMultipliers != 0 {
+		num, mult = splitMultiplier(s)
+	}
+
+	base := 10
+	if f&IntBasePrefix != 0 {
+		base = 0
+	}
+
+	n, err := strconv.ParseInt(num, base, bitSize)
+	if err != nil {
+		return n, numError("ParseInt", s, err)
+	}
+	if mult == 1 {
+		return n, nil
+	}
+
+	if bitSize == 0 {
+		bitSize = strconv.IntSize
+	}
+	max := int64(1)<<(bitSize-1) - 1
+	min := -max - 1
+	if n > max/int64(mult) || n < min/int64(mult) {
+		return 0, numError("ParseInt", s, strconv.ErrRange)
+	}
+
+	return n * int64(mult), nil
+}
+
+// ParseUint is like strconv.ParseUint, but parses s according to the IntFormat
+// f rather than in a given base. The bitSize argument specifies the integer type
+// that the result must fit into, as with strconv.ParseUint; values that do not
+// fit result in an error wrapping strconv.ErrRange.
+func ParseUint(s string, f IntFormat, bitSize int) (uint64, error) {
+	num, mult := s, uint64(1)
+	if f&IntMultipliers != 0 {
+		num, mult = splitMultiplier(s)
+	}
+
+	base := 10
+	if f&IntBasePrefix != 0 {
+		base = 0
+	}
+
+	n, err := strconv.ParseUint(num, base, bitSize)
+	if err != nil {
+		return n, numError("ParseUint", s, err)
+	}
+	if mult == 1 {
+		return n, nil
+	}
+
+	if bitSize == 0 {
+		bitSize = strconv.IntSize
+	}
+	max := uint64(1)<<(bitSize-1)<<1 - 1
+	if n > max/mult {
+		return 0, numError("ParseUint", s, strconv.ErrRange)
+	}
+
+	return n * mult, nil
+}
+
+// numError returns a *strconv.NumError reporting that s could not be parsed by
+// fn because of err.
+func numError(fn, s string, err error) error {
+	var ne *strconv.NumError
+	if errors.As(err, &ne) {
+		err = ne.Err
+	}
+
+	return &strconv.NumError{Func: fn, Num: s, Err: err}
+}
+
+// SetIntFormat makes this VarSet parse integers according to f, in Int, Int64,
+// Uint, Uint64 and the other integer getters. See IntFormat for the available
+// formats. The default is plain decimal integers.
+func (vs *VarSet) SetIntFormat(f IntFormat) {
+	vs.intFormat = f
+}
+
+// IntFormat returns the IntFormat for this VarSet.
+func (vs *VarSet) IntFormat() IntFormat {
+	return vs.intFormat
+}
+
+// Ints retrieves the value of the environment variable named by the key, parses
+// the value as a comma-separated list of integers, and returns the result. If
+// the variable is not present or any element cannot be parsed, fallback is
+// returned.
+func (vs *VarSet) Ints(key string, fallback []int) []int {
+	return get(vs, key, fallback, func(s string) ([]int, error) {
+		return parseList(s, func(s string) (int, error) {
+			n, err := ParseInt(s, vs.intFormat, strconv.IntSize)
+			return int(n), err
+		})
+	})
+}
+
+// Int64s retrieves the value of the environment variable named by the key,
+// parses the value as a comma-separated list of 64-bit integers, and returns the
+// result. If the variable is not present or any element cannot be parsed,
+// fallback is returned.
+func (vs *VarSet) Int64s(key string, fallback []int64) []int64 {
+	return get(vs, key, fallback, func(s string) ([]int64, error) {
+		return parseList(s, func(s string) (int64, error) {
+			return ParseInt(s, vs.intFormat, 64)
+		})
+	})
+}
+
+// Uints retrieves the value of the environment variable named by the key, parses
+// the value as a comma-separated list of unsigned integers, and returns the
+// result. If the variable is not present or any element cannot be parsed,
+// fallback is returned.
+func (vs *VarSet) Uints(key string, fallback []uint) []uint {
+	return get(vs, key, fallback, func(s string) ([]uint, error) {
+		return parseList(s, func(s string) (uint, error) {
+			n, err := ParseUint(s, vs.intFormat, strconv.IntSize)
+			return uint(n), err
+		})
+	})
+}
+
+// Uint64s retrieves the value of the environment variable named by the key,
+// parses the value as a comma-separated list of unsigned 64-bit integers, and
+// returns the result. If the variable is not present or any element cannot be
+// parsed, fallback is returned.
+func (vs *VarSet) Uint64s(key string, fallback []uint64) []uint64 {
+	return get(vs, key, fallback, func(s string) ([]uint64, error) {
+		return parseList(s, func(s string) (uint64, error) {
+			return ParseUint(s, vs.intFormat, 64)
+		})
+	})
+}
+
+// SetIntFormat makes the default VarSet parse integers according to f. See
+// VarSet.SetIntFormat.
+func SetIntFormat(f IntFormat) {
+	osVarSet.SetIntFormat(f)
+}
+
+// Ints retrieves the value of the environment variable named by the key, parses
+// the value as a comma-separated list of integers, and returns the result. If
+// the variable is not present or any element cannot be parsed, fallback is
+// returned.
+func Ints(key string, fallback []int) []int {
+	return osVarSet.Ints(key, fallback)
+}
+
+// Int64s retrieves the value of the environment variable named by the key,
+// parses the value as a comma-separated list of 64-bit integers, and returns the
+// result. If the variable is not present or any element cannot be parsed,
+// fallback is returned.
+func Int64s(key string, fallback []int64) []int64 {
+	return osVarSet.Int64s(key, fallback)
+}
+
+// Uints retrieves the value of the environment variable named by the key, parses
+// the value as a comma-separated list of unsigned integers, and returns the
+// result. If the variable is not present or any element cannot be parsed,
+// fallback is returned.
+func Uints(key string, fallback []uint) []uint {
+	return osVarSet.Uints(key, fallback)
+}
+
+// Uint64s retrieves the value of the environment variable named by the key,
+// parses the value as a comma-separated list of unsigned 64-bit integers, and
+// returns the result. If the variable is not present or any element cannot be
+// parsed, fallback is returned.
+func Uint64s(key string, fallback []uint64) []uint64 {
+	return osVarSet.Uint64s(key, fallback)
+}
+
+// IntsVar retrieves the value of the environment variable named by the key,
+// parses the value as a comma-separated list of integers, and stores the result
+// into the variable pointed by p.
+func IntsVar(p *[]int, key string, fallback []int) {
+	*p = osVarSet.Ints(key, fallback)
+}
+
+// Int64sVar retrieves the value of the environment variable named by the key,
+// parses the value as a comma-separated list of 64-bit integers, and stores the
+// result into the variable pointed by p.
+func Int64sVar(p *[]int64, key string, fallback []int64) {
+	*p = osVarSet.Int64s(key, fallback)
+}
+
+// UintsVar retrieves the value of the environment variable named by the key,
+// parses the value as a comma-separated list of unsigned integers, and stores
+// the result into the variable pointed by p.
+func UintsVar(p *[]uint, key string, fallback []uint) {
+	*p = osVarSet.Uints(key, fallback)
+}
+
+// Uint64sVar retrieves the value of the environment variable named by the key,
+// parses the value as a comma-separated list of unsigned 64-bit integers, and
+// stores the result into the variable pointed by p.
+func Uint64sVar(p *[]uint64, key string, fallback []uint64) {
+	*p = osVarSet.Uint64s(key, fallback)
+}
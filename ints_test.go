@@ -0,0 +1,209 @@
+package env_test
+
+import (
+	"errors"
+	"reflect"
+	"strconv"
+	"testing"
+
+	"github.com/christgf/env"
+)
+
+func TestParseInt(t *testing.T) {
+	tests := []struct {
+		s         string
+		format    env.IntFormat
+		bitSize   int
+		want      int64
+		wantErr   bool
+		wantRange bool
+	}{
+		{s: "42", bitSize: 64, want: 42},
+		{s: "-42", bitSize: 64, want: -42},
+		{s: "0x1F", bitSize: 64, wantErr: true},
+		{s: "1_000", bitSize: 64, wantErr: true},
+		{s: "10k", bitSize: 64, wantErr: true},
+		{s: "0x1F", format: env.IntBasePrefix, bitSize: 64, want: 31},
+		{s: "0o755", format: env.IntBasePrefix, bitSize: 64, want: 0o755},
+		{s: "0755", format: env.IntBasePrefix, bitSize: 64, want: 0o755},
+		{s: "0b1010", format: env.IntBasePrefix, bitSize: 64, want: 10},
+		{s: "1_000_000", format: env.IntBasePrefix, bitSize: 64, want: 1000000},
+		{s: "-0x10", format: env.IntBasePrefix, bitSize: 64, want: -16},
+		{s: "10k", format: env.IntMultipliers, bitSize: 64, want: 10000},
+		{s: "10K", format: env.IntMultipliers, bitSize: 64, want: 10000},
+		{s: "-2M", format: env.IntMultipliers, bitSize: 64, want: -2000000},
+		{s: "3G", format: env.IntMultipliers, bitSize: 64, want: 3e9},
+		{s: "9E", format: env.IntMultipliers, bitSize: 64, want: 9e18},
+		{s: "10E", format: env.IntMultipliers, bitSize: 64, wantErr: true, wantRange: true},
+		{s: "1k", format: env.IntMultipliers, bitSize: 8, wantErr: true, wantRange: true},
+		{s: "1_000k", format: env.IntBasePrefix | env.IntMultipliers, bitSize: 64, want: 1e6},
+		{s: "0x1E", format: env.IntBasePrefix | env.IntMultipliers, bitSize: 64, want: 30},
+		{s: "0b11k", format: env.IntBasePrefix | env.IntMultipliers, bitSize: 64, want: 3000},
+		{s: "k", format: env.IntMultipliers, bitSize: 64, wantErr: true},
+		{s: "128", bitSize: 8, wantErr: true, wantRange: true},
+	}
+	for _, tt := range tests {
+		got, err := env.ParseInt(tt.s, tt.format, tt.bitSize)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("ParseInt(%q, %d, %d): want error", tt.s, tt.format, tt.bitSize)
+			}
+			if tt.wantRange && !errors.Is(err, strconv.ErrRange) {
+				t.Errorf("ParseInt(%q, %d, %d): got error %v, want %v", tt.s, tt.format, tt.bitSize, err, strconv.ErrRange)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("ParseInt(%q, %d, %d): %v", tt.s, tt.format, tt.bitSize, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("ParseInt(%q, %d, %d): got %d, want %d", tt.s, tt.format, tt.bitSize, got, tt.want)
+		}
+	}
+}
+
+func TestParseUint(t *testing.T) {
+	tests := []struct {
+		s       string
+		format  env.IntFormat
+		bitSize int
+		want    uint64
+		wantErr bool
+	}{
+		{s: "42", bitSize: 64, want: 42},
+		{s: "-42", bitSize: 64, wantErr: true},
+		{s: "0xFF", format: env.IntBasePrefix, bitSize: 8, want: 255},
+		{s: "0x100", format: env.IntBasePrefix, bitSize: 8, wantErr: true},
+		{s: "18E", format: env.IntMultipliers, bitSize: 64, want: 18e18},
+		{s: "19E", format: env.IntMultipliers, bitSize: 64, wantErr: true},
+		{s: "65k", format: env.IntMultipliers, bitSize: 16, want: 65000},
+		{s: "66k", format: env.IntMultipliers, bitSize: 16, wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := env.ParseUint(tt.s, tt.format, tt.bitSize)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("ParseUint(%q, %d, %d): want error", tt.s, tt.format, tt.bitSize)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("ParseUint(%q, %d, %d): %v", tt.s, tt.format, tt.bitSize, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("ParseUint(%q, %d, %d): got %d, want %d", tt.s, tt.format, tt.bitSize, got, tt.want)
+		}
+	}
+}
+
+func TestVarSet_SetIntFormat(t *testing.T) {
+	t.Setenv("ENV_TEST_INT_FORMAT_HEX", "0x1F")
+	t.Setenv("ENV_TEST_INT_FORMAT_MULT", "10k")
+
+	var vs env.VarSet
+	if got, want := vs.Int("ENV_TEST_INT_FORMAT_HEX", 42), 42; got != want {
+		t.Errorf("Int(%q): got %d, want %d", "ENV_TEST_INT_FORMAT_HEX", got, want)
+	}
+
+	vs.SetIntFormat(env.IntBasePrefix | env.IntMultipliers)
+	if got, want := vs.IntFormat(), env.IntBasePrefix|env.IntMultipliers; got != want {
+		t.Fatalf("IntFormat(): got %d, want %d", got, want)
+	}
+	if got, want := vs.Int("ENV_TEST_INT_FORMAT_HEX", 42), 31; got != want {
+		t.Errorf("Int(%q): got %d, want %d", "ENV_TEST_INT_FORMAT_HEX", got, want)
+	}
+	if got, want := vs.Int64("ENV_TEST_INT_FORMAT_MULT", 42), int64(10000); got != want {
+		t.Errorf("Int64(%q): got %d, want %d", "ENV_TEST_INT_FORMAT_MULT", got, want)
+	}
+	if got, want := vs.Uint("ENV_TEST_INT_FORMAT_HEX", 42), uint(31); got != want {
+		t.Errorf("Uint(%q): got %d, want %d", "ENV_TEST_INT_FORMAT_HEX", got, want)
+	}
+	if got, want := vs.Uint64("ENV_TEST_INT_FORMAT_MULT", 42), uint64(10000); got != want {
+		t.Errorf("Uint64(%q): got %d, want %d", "ENV_TEST_INT_FORMAT_MULT", got, want)
+	}
+}
+
+func TestInts(t *testing.T) {
+	const envKey = "ENV_TEST_INTS"
+
+	tests := []struct {
+		name      string
+		envValue  string
+		fallback  []int
+		wantValue []int
+	}{
+		{
+			name:      "value is empty",
+			envValue:  "",
+			fallback:  []int{42},
+			wantValue: []int{},
+		},
+		{
+			name:      "value is list",
+			envValue:  "1, -2,3",
+			fallback:  []int{42},
+			wantValue: []int{1, -2, 3},
+		},
+		{
+			name:      "element is invalid",
+			envValue:  "1,foo",
+			fallback:  []int{42},
+			wantValue: []int{42},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv(envKey, tt.envValue)
+			if got, want := env.Ints(envKey, tt.fallback), tt.wantValue; !reflect.DeepEqual(got, want) {
+				t.Errorf("Ints(%q): got %v, want %v", envKey, got, want)
+			}
+
+			var p []int
+			env.IntsVar(&p, envKey, tt.fallback)
+			if got, want := p, tt.wantValue; !reflect.DeepEqual(got, want) {
+				t.Errorf("IntsVar(%q): got %v, want %v", envKey, got, want)
+			}
+		})
+	}
+}
+
+func TestIntsLists(t *testing.T) {
+	t.Setenv("ENV_TEST_INTS_LIST", "0x10, 1k")
+
+	var vs env.VarSet
+	if got, want := vs.Int64s("ENV_TEST_INTS_LIST", nil), []int64(nil); !reflect.DeepEqual(got, want) {
+		t.Errorf("Int64s(%q): got %v, want %v", "ENV_TEST_INTS_LIST", got, want)
+	}
+
+	vs.SetIntFormat(env.IntBasePrefix | env.IntMultipliers)
+	if got, want := vs.Int64s("ENV_TEST_INTS_LIST", nil), []int64{16, 1000}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Int64s(%q): got %v, want %v", "ENV_TEST_INTS_LIST", got, want)
+	}
+	if got, want := vs.Uints("ENV_TEST_INTS_LIST", nil), []uint{16, 1000}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Uints(%q): got %v, want %v", "ENV_TEST_INTS_LIST", got, want)
+	}
+	if got, want := vs.Uint64s("ENV_TEST_INTS_LIST", nil), []uint64{16, 1000}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Uint64s(%q): got %v, want %v", "ENV_TEST_INTS_LIST", got, want)
+	}
+
+	t.Setenv("ENV_TEST_INTS_LIST", "1,2")
+	var p64 []int64
+	env.Int64sVar(&p64, "ENV_TEST_INTS_LIST", nil)
+	if got, want := p64, []int64{1, 2}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Int64sVar(%q): got %v, want %v", "ENV_TEST_INTS_LIST", got, want)
+	}
+
+	var pu []uint
+	env.UintsVar(&pu, "ENV_TEST_INTS_LIST", nil)
+	if got, want := pu, []uint{1, 2}; !reflect.DeepEqual(got, want) {
+		t.Errorf("UintsVar(%q): got %v, want %v", "ENV_TEST_INTS_LIST", got, want)
+	}
+
+	var pu64 []uint64
+	env.Uint64sVar(&pu64, "ENV_TEST_INTS_LIST", nil)
+	if got, want := pu64, []uint64{1, 2}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Uint64sVar(%q): got %v, want %v", "ENV_TEST_INTS_LIST", got, want)
+	}
+}
@@ -0,0 +1,131 @@
+package env
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// JSONError is returned by JSON and StrictJSON when the value of an environment
+// variable cannot be decoded.
+type JSONError struct {
+	// Key is the key the value was retrieved with.
+	Key string
+	// Offset is the offset of the error in the value, in bytes, or -1 if the
+	// error is not tied to a position, such as an unknown field.
+	Offset int64
+	// Err is the error returned by encoding/json.
+	Err error
+
+	sensitive bool
+}
+
+// Error implements the error interface. If the key was marked as sensitive, the
+// message of Err is omitted, since it may quote part of the value.
+func (e *JSONError) Error() string {
+	msg := fmt.Sprintf("env: %s: invalid JSON", e.Key)
+	if e.Offset >= 0 {
+		msg += fmt.Sprintf(" at offset %d", e.Offset)
+	}
+	if e.sensitive {
+		return msg
+	}
+
+	return fmt.Sprintf("%s: %v", msg, e.Err)
+}
+
+// Unwrap returns the underlying encoding/json error.
+func (e *JSONError) Unwrap() error {
+	return e.Err
+}
+
+// JSON retrieves the value of the environment variable named by the key, and
+// decodes it into the value pointed to by v using encoding/json. If the variable
+// is not present, v is left untouched and JSON returns nil, so that v can be
+// initialised with defaults. For example:
+//
+//	rules := []Rule{{Path: "/", Upstream: "default"}}
+//	if err := vs.JSON("ROUTING_RULES", &rules); err != nil {
+//		log.Fatal(err)
+//	}
+//
+// If the value cannot be decoded, the error is a *JSONError. If it cannot be
+// expanded or resolved, that error is returned.
+func (vs *VarSet) JSON(key string, v any) error {
+	return vs.decodeJSON(key, v, false)
+}
+
+// StrictJSON is like JSON, but the value must not contain object keys that do
+// not match any exported field of the destination struct.
+func (vs *VarSet) StrictJSON(key string, v any) error {
+	return vs.decodeJSON(key, v, true)
+}
+
+func (vs *VarSet) decodeJSON(key string, v any, strict bool) error {
+	value, origin, err := vs.lookup(key)
+	if err != nil {
+		vs.record(key, nil, nil, originDefault, err)
+		return err
+	}
+	if len(origin) == 0 {
+		vs.record(key, nil, nil, originDefault, nil)
+		return nil
+	}
+
+	dec := json.NewDecoder(strings.NewReader(value))
+	if strict {
+		dec.DisallowUnknownFields()
+	}
+	if err := dec.Decode(v); err != nil {
+		offset := jsonErrorOffset(err)
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
+			err, offset = io.ErrUnexpectedEOF, int64(len(value))
+		}
+		jsonErr := &JSONError{Key: plainKey(key), Offset: offset, Err: err, sensitive: vs.IsSensitive(key)}
+		vs.record(key, nil, nil, originDefault, jsonErr)
+		return jsonErr
+	}
+	if _, err := dec.Token(); err != io.EOF {
+		offset := dec.InputOffset()
+		if err == nil {
+			err = errors.New("unexpected data after top-level value")
+		}
+		jsonErr := &JSONError{Key: plainKey(key), Offset: offset, Err: err, sensitive: vs.IsSensitive(key)}
+		vs.record(key, nil, nil, originDefault, jsonErr)
+		return jsonErr
+	}
+
+	vs.record(key, value, nil, origin, nil)
+	return nil
+}
+
+// jsonErrorOffset returns the offset of the error err returned by encoding/json,
+// or -1 if the error is not tied to a position.
+func jsonErrorOffset(err error) int64 {
+	var syntaxErr *json.SyntaxError
+	if errors.As(err, &syntaxErr) {
+		return syntaxErr.Offset
+	}
+	var typeErr *json.UnmarshalTypeError
+	if errors.As(err, &typeErr) {
+		return typeErr.Offset
+	}
+
+	return -1
+}
+
+// JSON retrieves the value of the environment variable named by the key, and
+// decodes it into the value pointed to by v using encoding/json. If the variable
+// is not present, v is left untouched and JSON returns nil. If the value cannot
+// be decoded, the error is a *JSONError.
+func JSON(key string, v any) error {
+	return osVarSet.JSON(key, v)
+}
+
+// StrictJSON is like JSON, but the value must not contain object keys that do
+// not match any exported field of the destination struct.
+func StrictJSON(key string, v any) error {
+	return osVarSet.StrictJSON(key, v)
+}
@@ -0,0 +1,120 @@
+package env_test
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/christgf/env"
+)
+
+type routingRule struct {
+	Path     string `json:"path"`
+	Upstream string `json:"upstream"`
+}
+
+func TestJSON(t *testing.T) {
+	const envKey = "ENV_TEST_ROUTING_RULES"
+
+	t.Run("value is not set", func(t *testing.T) {
+		rules := []routingRule{{Path: "/", Upstream: "default"}}
+		if err := env.JSON(envKey, &rules); err != nil {
+			t.Fatalf("JSON(%q): %v", envKey, err)
+		}
+		if want := []routingRule{{Path: "/", Upstream: "default"}}; !reflect.DeepEqual(rules, want) {
+			t.Errorf("JSON(%q): got %v, want %v", envKey, rules, want)
+		}
+	})
+
+	t.Run("value is valid", func(t *testing.T) {
+		t.Setenv(envKey, `[{"path":"/a","upstream":"x"},{"path":"/b","upstream":"y","weight":2}]`)
+		var rules []routingRule
+		if err := env.JSON(envKey, &rules); err != nil {
+			t.Fatalf("JSON(%q): %v", envKey, err)
+		}
+		want := []routingRule{{Path: "/a", Upstream: "x"}, {Path: "/b", Upstream: "y"}}
+		if !reflect.DeepEqual(rules, want) {
+			t.Errorf("JSON(%q): got %v, want %v", envKey, rules, want)
+		}
+	})
+
+	t.Run("prefix is set", func(t *testing.T) {
+		t.Setenv(envKey, `{"path":"/a"}`)
+		var vs env.VarSet
+		vs.SetPrefix("ENV_")
+		var rule routingRule
+		if err := vs.JSON("TEST_ROUTING_RULES", &rule); err != nil {
+			t.Fatalf("JSON(%q): %v", "TEST_ROUTING_RULES", err)
+		}
+		if want := (routingRule{Path: "/a"}); rule != want {
+			t.Errorf("JSON(%q): got %v, want %v", "TEST_ROUTING_RULES", rule, want)
+		}
+	})
+}
+
+func TestJSON_errors(t *testing.T) {
+	const envKey = "ENV_TEST_JSON_ERROR"
+
+	tests := map[string]struct {
+		value      string
+		strict     bool
+		wantOffset int64
+		wantErr    string
+	}{
+		"syntax error": {
+			value:      `{"path":/a}`,
+			wantOffset: 9,
+			wantErr:    "env: ENV_TEST_JSON_ERROR: invalid JSON at offset 9: invalid character '/' looking for beginning of value",
+		},
+		"type mismatch": {
+			value:      `{"path":1}`,
+			wantOffset: 9,
+			wantErr:    "env: ENV_TEST_JSON_ERROR: invalid JSON at offset 9: json: cannot unmarshal number into Go struct field routingRule.path of type string",
+		},
+		"value is empty": {
+			value:      ``,
+			wantOffset: 0,
+			wantErr:    "env: ENV_TEST_JSON_ERROR: invalid JSON at offset 0: unexpected EOF",
+		},
+		"value is truncated": {
+			value:      `{"path":"/a"`,
+			wantOffset: 12,
+			wantErr:    "env: ENV_TEST_JSON_ERROR: invalid JSON at offset 12: unexpected EOF",
+		},
+		"trailing data": {
+			value:      `{"path":"/a"} {}`,
+			wantOffset: 15,
+			wantErr:    "env: ENV_TEST_JSON_ERROR: invalid JSON at offset 15: unexpected data after top-level value",
+		},
+		"unknown field in strict mode": {
+			value:      `{"path":"/a","weight":2}`,
+			strict:     true,
+			wantOffset: -1,
+			wantErr:    `env: ENV_TEST_JSON_ERROR: invalid JSON: json: unknown field "weight"`,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Setenv(envKey, tc.value)
+
+			var rule routingRule
+			decode := env.JSON
+			if tc.strict {
+				decode = env.StrictJSON
+			}
+			err := decode(envKey, &rule)
+
+			var jsonErr *env.JSONError
+			if !errors.As(err, &jsonErr) {
+				t.Fatalf("JSON(%q): got error %v, want *JSONError", envKey, err)
+			}
+			if jsonErr.Key != envKey || jsonErr.Offset != tc.wantOffset {
+				t.Errorf("JSON(%q): got key %q, offset %d, want %q, %d", envKey, jsonErr.Key, jsonErr.Offset, envKey, tc.wantOffset)
+			}
+			if got := err.Error(); got != tc.wantErr {
+				t.Errorf("JSON(%q): got error %q, want %q", envKey, got, tc.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,108 @@
+package env
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// KeyMapper maps a key, such as the name of a struct field or a key read from a
+// configuration file, to the name of an environment variable.
+type KeyMapper func(key string) string
+
+// NormalizeKey replaces every '.' and '-' in key with '_', so that "db.pool-max"
+// becomes "db_pool_max". The case of key is preserved.
+func NormalizeKey(key string) string {
+	return strings.Map(func(r rune) rune {
+		if r == '.' || r == '-' {
+			return '_'
+		}
+		return r
+	}, key)
+}
+
+// UpperSnakeCase maps key to upper snake case, the conventional form of
+// environment variable names. Camel case words are separated, so that
+// "MaxIdleConns" becomes "MAX_IDLE_CONNS" and "HTTPServer" becomes
+// "HTTP_SERVER", and separators are normalized as with NormalizeKey, so that
+// "db.pool-max" becomes "DB_POOL_MAX".
+func UpperSnakeCase(key string) string {
+	runes := []rune(NormalizeKey(key))
+
+	var b strings.Builder
+	b.Grow(len(key) + 4)
+	for i, r := range runes {
+		if i > 0 && unicode.IsUpper(r) {
+			prev := runes[i-1]
+			next := i+1 < len(runes) && unicode.IsLower(runes[i+1])
+			if unicode.IsLower(prev) || unicode.IsDigit(prev) || (unicode.IsUpper(prev) && next) {
+				b.WriteRune('_')
+			}
+		}
+		b.WriteRune(unicode.ToUpper(r))
+	}
+
+	return b.String()
+}
+
+// SetKeyMapper makes this VarSet map every key using m before it applies the
+// prefix and looks the key up in the environment. Use nil to reset.
+func (vs *VarSet) SetKeyMapper(m KeyMapper) {
+	vs.mapper = m
+}
+
+// KeyMapper returns the KeyMapper for this VarSet, if any.
+func (vs *VarSet) KeyMapper() KeyMapper {
+	return vs.mapper
+}
+
+// SetIgnoreCase makes this VarSet match keys against the names of environment
+// variables case-insensitively, whenever a variable with the exact name is not
+// present in the environment. If more than one variable matches, the key is
+// treated as if it were not present, and the error is reported by Err.
+func (vs *VarSet) SetIgnoreCase(ignoreCase bool) {
+	vs.ignoreCase = ignoreCase
+}
+
+// IgnoreCase reports whether this VarSet matches keys case-insensitively.
+func (vs *VarSet) IgnoreCase() bool {
+	return vs.ignoreCase
+}
+
+// lookupFold retrieves the value of the environment variable whose name is
+// equal to key under Unicode case-folding. If more than one variable matches,
+// such as "p_case" and "P_CASE", it returns an error rather than pick one.
+func lookupFold(key string) (string, bool, error) {
+	var names, values []string
+	for _, kv := range os.Environ() {
+		name, value, ok := strings.Cut(kv, "=")
+		if ok && strings.EqualFold(name, key) {
+			names, values = append(names, name), append(values, value)
+		}
+	}
+
+	switch len(names) {
+	case 0:
+		return "", false, nil
+	case 1:
+		return values[0], true, nil
+	default:
+		sort.Strings(names)
+		return "", false, fmt.Errorf("env: %s: variables %s match it case-insensitively", key, strings.Join(names, ", "))
+	}
+}
+
+// SetKeyMapper makes the default VarSet map every key using m before it applies
+// the prefix and looks the key up in the environment. Use nil to reset.
+func SetKeyMapper(m KeyMapper) {
+	osVarSet.SetKeyMapper(m)
+}
+
+// SetIgnoreCase makes the default VarSet match keys against the names of
+// environment variables case-insensitively, whenever a variable with the exact
+// name is not present in the environment.
+func SetIgnoreCase(ignoreCase bool) {
+	osVarSet.SetIgnoreCase(ignoreCase)
+}
@@ -0,0 +1,119 @@
+package env_test
+
+import (
+	"testing"
+
+	"github.com/christgf/env"
+)
+
+func TestNormalizeKey(t *testing.T) {
+	tests := []struct {
+		key  string
+		want string
+	}{
+		{key: "", want: ""},
+		{key: "PORT", want: "PORT"},
+		{key: "db.pool-max", want: "db_pool_max"},
+		{key: "DB_POOL.MAX", want: "DB_POOL_MAX"},
+	}
+	for _, tt := range tests {
+		if got := env.NormalizeKey(tt.key); got != tt.want {
+			t.Errorf("NormalizeKey(%q): got %q, want %q", tt.key, got, tt.want)
+		}
+	}
+}
+
+func TestUpperSnakeCase(t *testing.T) {
+	tests := []struct {
+		key  string
+		want string
+	}{
+		{key: "", want: ""},
+		{key: "Port", want: "PORT"},
+		{key: "MaxIdleConns", want: "MAX_IDLE_CONNS"},
+		{key: "maxIdleConns", want: "MAX_IDLE_CONNS"},
+		{key: "HTTPServer", want: "HTTP_SERVER"},
+		{key: "ServerID", want: "SERVER_ID"},
+		{key: "TLS13Enabled", want: "TLS13_ENABLED"},
+		{key: "db.pool-max", want: "DB_POOL_MAX"},
+		{key: "DB_POOL_MAX", want: "DB_POOL_MAX"},
+		{key: "db.MaxConns", want: "DB_MAX_CONNS"},
+	}
+	for _, tt := range tests {
+		if got := env.UpperSnakeCase(tt.key); got != tt.want {
+			t.Errorf("UpperSnakeCase(%q): got %q, want %q", tt.key, got, tt.want)
+		}
+	}
+}
+
+func TestVarSet_SetKeyMapper(t *testing.T) {
+	t.Setenv("ENV_TEST_MAX_IDLE_CONNS", "8")
+
+	var vs env.VarSet
+	if got, want := vs.Int("MaxIdleConns", 2), 2; got != want {
+		t.Errorf("Int(%q): got %d, want %d", "MaxIdleConns", got, want)
+	}
+
+	vs.SetKeyMapper(env.UpperSnakeCase)
+	if vs.KeyMapper() == nil {
+		t.Fatalf("KeyMapper(): got nil")
+	}
+
+	vs.SetPrefix("ENV_TEST_")
+	if got, want := vs.Int("MaxIdleConns", 2), 8; got != want {
+		t.Errorf("Int(Prefix=%q, Key=%q): got %d, want %d", vs.Prefix(), "MaxIdleConns", got, want)
+	}
+
+	vs.SetKeyMapper(nil)
+	if got, want := vs.Int("MaxIdleConns", 2), 2; got != want {
+		t.Errorf("Int(Prefix=%q, Key=%q): got %d, want %d", vs.Prefix(), "MaxIdleConns", got, want)
+	}
+}
+
+func TestVarSet_SetIgnoreCase(t *testing.T) {
+	t.Setenv("ENV_TEST_IGNORE_CASE", "foo")
+
+	var vs env.VarSet
+	if _, ok := vs.Lookup("env_test_ignore_case"); ok {
+		t.Errorf("Lookup(%q): got true", "env_test_ignore_case")
+	}
+
+	vs.SetIgnoreCase(true)
+	if !vs.IgnoreCase() {
+		t.Fatalf("IgnoreCase(): got false")
+	}
+
+	value, ok := vs.Lookup("env_test_ignore_case")
+	if !ok {
+		t.Errorf("Lookup(%q): want true", "env_test_ignore_case")
+	}
+	if got, want := value, "foo"; got != want {
+		t.Errorf("Lookup(%q): got %q, want %q", "env_test_ignore_case", got, want)
+	}
+
+	vs.SetPrefix("Env_Test_")
+	vs.SetKeyMapper(env.NormalizeKey)
+	if got, want := vs.String("ignore-case", "fallback"), "foo"; got != want {
+		t.Errorf("String(Prefix=%q, Key=%q): got %q, want %q", vs.Prefix(), "ignore-case", got, want)
+	}
+}
+
+func TestVarSet_SetIgnoreCase_ambiguous(t *testing.T) {
+	t.Setenv("ENV_TEST_P_CASE", "upper")
+	t.Setenv("env_test_p_case", "lower")
+
+	var vs env.VarSet
+	vs.SetIgnoreCase(true)
+	if got, want := vs.String("Env_Test_P_Case", "fallback"), "fallback"; got != want {
+		t.Errorf("String(%q): got %q, want %q", "Env_Test_P_Case", got, want)
+	}
+	want := "env: Env_Test_P_Case: variables ENV_TEST_P_CASE, env_test_p_case match it case-insensitively"
+	if err := vs.Err(); err == nil || err.Error() != want {
+		t.Errorf("Err(): got %v, want %q", err, want)
+	}
+
+	// A variable with the exact name is preferred.
+	if got, want := vs.String("env_test_p_case", "fallback"), "lower"; got != want {
+		t.Errorf("String(%q): got %q, want %q", "env_test_p_case", got, want)
+	}
+}
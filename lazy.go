@@ -0,0 +1,161 @@
+package env
+
+import "time"
+
+// OrElseFunc returns the value of o if o is set. Otherwise it calls fallback and
+// returns its result, so that fallback is only computed when it is needed.
+func (o Optional[T]) OrElseFunc(fallback func() (T, error)) (T, error) {
+	if !o.set {
+		return fallback()
+	}
+
+	return o.value, nil
+}
+
+// getFunc returns the value of o if o is set. Otherwise it calls fallback, and
+// records its result as the default value of key for Dump.
+func getFunc[T any](vs *VarSet, key string, o Optional[T], fallback func() (T, error)) (T, error) {
+	res, err := o.OrElseFunc(fallback)
+	if err == nil && !o.set {
+		vs.recordDefault(key, res)
+	}
+
+	return res, err
+}
+
+// StringFunc retrieves the value of the environment variable named by the key.
+// If the variable is present in the environment, its value (which may be empty)
+// is returned, otherwise fallback is called and its result is returned.
+//
+// Since fallback is only called when needed, it may be expensive, or it may
+// derive the value from other variables of the VarSet. For example, a metrics
+// address can default to the port of the main server plus one:
+//
+//	addr, err := vs.StringFunc("METRICS_ADDR", func() (string, error) {
+//		return fmt.Sprintf(":%d", vs.Int("PORT", 8080)+1), nil
+//	})
+func (vs *VarSet) StringFunc(key string, fallback func() (string, error)) (string, error) {
+	return getFunc(vs, key, vs.OptionalString(key), fallback)
+}
+
+// BoolFunc retrieves the value of the environment variable named by the key,
+// parses the value as a boolean, and returns the result. If the variable is not
+// present or its value cannot be parsed, fallback is called and its result is
+// returned.
+func (vs *VarSet) BoolFunc(key string, fallback func() (bool, error)) (bool, error) {
+	return getFunc(vs, key, vs.OptionalBool(key), fallback)
+}
+
+// IntFunc retrieves the value of the environment variable named by the key,
+// parses the value as an integer, and returns the result. If the variable is not
+// present or its value cannot be parsed, fallback is called and its result is
+// returned.
+func (vs *VarSet) IntFunc(key string, fallback func() (int, error)) (int, error) {
+	return getFunc(vs, key, vs.OptionalInt(key), fallback)
+}
+
+// Int64Func retrieves the value of the environment variable named by the key,
+// parses the value as a 64-bit integer, and returns the result. If the variable
+// is not present or its value cannot be parsed, fallback is called and its
+// result is returned.
+func (vs *VarSet) Int64Func(key string, fallback func() (int64, error)) (int64, error) {
+	return getFunc(vs, key, vs.OptionalInt64(key), fallback)
+}
+
+// UintFunc retrieves the value of the environment variable named by the key,
+// parses the value as an unsigned integer, and returns the result. If the
+// variable is not present or its value cannot be parsed, fallback is called and
+// its result is returned.
+func (vs *VarSet) UintFunc(key string, fallback func() (uint, error)) (uint, error) {
+	return getFunc(vs, key, vs.OptionalUint(key), fallback)
+}
+
+// Uint64Func retrieves the value of the environment variable named by the key,
+// parses the value as an unsigned 64-bit integer, and returns the result. If the
+// variable is not present or its value cannot be parsed, fallback is called and
+// its result is returned.
+func (vs *VarSet) Uint64Func(key string, fallback func() (uint64, error)) (uint64, error) {
+	return getFunc(vs, key, vs.OptionalUint64(key), fallback)
+}
+
+// Float64Func retrieves the value of the environment variable named by the key,
+// parses the value as a 64-bit floating-point number, and returns the result. If
+// the variable is not present or its value cannot be parsed, fallback is called
+// and its result is returned.
+func (vs *VarSet) Float64Func(key string, fallback func() (float64, error)) (float64, error) {
+	return getFunc(vs, key, vs.OptionalFloat64(key), fallback)
+}
+
+// DurationFunc retrieves the value of the environment variable named by the key,
+// parses the value as time.Duration, and returns the result. If the variable is
+// not present or its value cannot be parsed, fallback is called and its result
+// is returned.
+func (vs *VarSet) DurationFunc(key string, fallback func() (time.Duration, error)) (time.Duration, error) {
+	return getFunc(vs, key, vs.OptionalDuration(key), fallback)
+}
+
+// StringFunc retrieves the value of the environment variable named by the key.
+// If the variable is present in the environment, its value (which may be empty)
+// is returned, otherwise fallback is called and its result is returned. For
+// example, the host name of the machine can be used as a default:
+//
+//	name, err := env.StringFunc("HOSTNAME", os.Hostname)
+func StringFunc(key string, fallback func() (string, error)) (string, error) {
+	return osVarSet.StringFunc(key, fallback)
+}
+
+// BoolFunc retrieves the value of the environment variable named by the key,
+// parses the value as a boolean, and returns the result. If the variable is not
+// present or its value cannot be parsed, fallback is called and its result is
+// returned.
+func BoolFunc(key string, fallback func() (bool, error)) (bool, error) {
+	return osVarSet.BoolFunc(key, fallback)
+}
+
+// IntFunc retrieves the value of the environment variable named by the key,
+// parses the value as an integer, and returns the result. If the variable is not
+// present or its value cannot be parsed, fallback is called and its result is
+// returned.
+func IntFunc(key string, fallback func() (int, error)) (int, error) {
+	return osVarSet.IntFunc(key, fallback)
+}
+
+// Int64Func retrieves the value of the environment variable named by the key,
+// parses the value as a 64-bit integer, and returns the result. If the variable
+// is not present or its value cannot be parsed, fallback is called and its
+// result is returned.
+func Int64Func(key string, fallback func() (int64, error)) (int64, error) {
+	return osVarSet.Int64Func(key, fallback)
+}
+
+// UintFunc retrieves the value of the environment variable named by the key,
+// parses the value as an unsigned integer, and returns the result. If the
+// variable is not present or its value cannot be parsed, fallback is called and
+// its result is returned.
+func UintFunc(key string, fallback func() (uint, error)) (uint, error) {
+	return osVarSet.UintFunc(key, fallback)
+}
+
+// Uint64Func retrieves the value of the environment variable named by the key,
+// parses the value as an unsigned 64-bit integer, and returns the result. If the
+// variable is not present or its value cannot be parsed, fallback is called and
+// its result is returned.
+func Uint64Func(key string, fallback func() (uint64, error)) (uint64, error) {
+	return osVarSet.Uint64Func(key, fallback)
+}
+
+// Float64Func retrieves the value of the environment variable named by the key,
+// parses the value as a 64-bit floating-point number, and returns the result. If
+// the variable is not present or its value cannot be parsed, fallback is called
+// and its result is returned.
+func Float64Func(key string, fallback func() (float64, error)) (float64, error) {
+	return osVarSet.Float64Func(key, fallback)
+}
+
+// DurationFunc retrieves the value of the environment variable named by the key,
+// parses the value as time.Duration, and returns the result. If the variable is
+// not present or its value cannot be parsed, fallback is called and its result
+// is returned.
+func DurationFunc(key string, fallback func() (time.Duration, error)) (time.Duration, error) {
+	return osVarSet.DurationFunc(key, fallback)
+}
@@ -0,0 +1,117 @@
+package env_test
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/christgf/env"
+)
+
+func TestStringFunc(t *testing.T) {
+	const envKey = "ENV_TEST_STRING_FUNC"
+
+	calls := 0
+	fallback := func() (string, error) {
+		calls++
+		return "fallback", nil
+	}
+
+	got, err := env.StringFunc(envKey, fallback)
+	if err != nil {
+		t.Fatalf("StringFunc(%q): %v", envKey, err)
+	}
+	if want := "fallback"; got != want {
+		t.Errorf("StringFunc(%q): got %q, want %q", envKey, got, want)
+	}
+	if calls != 1 {
+		t.Errorf("StringFunc(%q): fallback called %d times, want 1", envKey, calls)
+	}
+
+	t.Setenv(envKey, "")
+	got, err = env.StringFunc(envKey, fallback)
+	if err != nil {
+		t.Fatalf("StringFunc(%q): %v", envKey, err)
+	}
+	if want := ""; got != want {
+		t.Errorf("StringFunc(%q): got %q, want %q", envKey, got, want)
+	}
+	if calls != 1 {
+		t.Errorf("StringFunc(%q): fallback called %d times, want 1", envKey, calls)
+	}
+
+	errFallback := errors.New("no fallback")
+	if _, err := env.StringFunc("ENV_TEST_STRING_FUNC_UNSET", func() (string, error) { return "", errFallback }); !errors.Is(err, errFallback) {
+		t.Errorf("StringFunc(%q): got error %v, want %v", "ENV_TEST_STRING_FUNC_UNSET", err, errFallback)
+	}
+}
+
+func TestVarSet_StringFunc_derived(t *testing.T) {
+	t.Setenv("ENV_TEST_PORT", "9000")
+
+	var vs env.VarSet
+	vs.SetPrefix("ENV_TEST_")
+	metricsAddr := func() (string, error) {
+		return fmt.Sprintf(":%d", vs.Int("PORT", 8080)+1), nil
+	}
+
+	addr, err := vs.StringFunc("METRICS_ADDR", metricsAddr)
+	if err != nil {
+		t.Fatalf("StringFunc(%q): %v", "METRICS_ADDR", err)
+	}
+	if want := ":9001"; addr != want {
+		t.Errorf("StringFunc(%q): got %q, want %q", "METRICS_ADDR", addr, want)
+	}
+
+	t.Setenv("ENV_TEST_METRICS_ADDR", ":9100")
+	addr, err = vs.StringFunc("METRICS_ADDR", metricsAddr)
+	if err != nil {
+		t.Fatalf("StringFunc(%q): %v", "METRICS_ADDR", err)
+	}
+	if want := ":9100"; addr != want {
+		t.Errorf("StringFunc(%q): got %q, want %q", "METRICS_ADDR", addr, want)
+	}
+}
+
+func TestTypedFunc(t *testing.T) {
+	t.Setenv("ENV_TEST_FUNC_BOOL", "true")
+	t.Setenv("ENV_TEST_FUNC_INT", "7")
+	t.Setenv("ENV_TEST_FUNC_FLOAT", "0.5")
+	t.Setenv("ENV_TEST_FUNC_DURATION", "1m")
+	t.Setenv("ENV_TEST_FUNC_INVALID", "foobar")
+
+	mustNotCall := func() {
+		t.Helper()
+		t.Errorf("fallback called")
+	}
+
+	if got, err := env.BoolFunc("ENV_TEST_FUNC_BOOL", func() (bool, error) { mustNotCall(); return false, nil }); err != nil || got != true {
+		t.Errorf("BoolFunc(): got %v, %v", got, err)
+	}
+	if got, err := env.IntFunc("ENV_TEST_FUNC_INT", func() (int, error) { mustNotCall(); return 0, nil }); err != nil || got != 7 {
+		t.Errorf("IntFunc(): got %v, %v", got, err)
+	}
+	if got, err := env.Int64Func("ENV_TEST_FUNC_INT", func() (int64, error) { mustNotCall(); return 0, nil }); err != nil || got != 7 {
+		t.Errorf("Int64Func(): got %v, %v", got, err)
+	}
+	if got, err := env.UintFunc("ENV_TEST_FUNC_INT", func() (uint, error) { mustNotCall(); return 0, nil }); err != nil || got != 7 {
+		t.Errorf("UintFunc(): got %v, %v", got, err)
+	}
+	if got, err := env.Uint64Func("ENV_TEST_FUNC_INT", func() (uint64, error) { mustNotCall(); return 0, nil }); err != nil || got != 7 {
+		t.Errorf("Uint64Func(): got %v, %v", got, err)
+	}
+	if got, err := env.Float64Func("ENV_TEST_FUNC_FLOAT", func() (float64, error) { mustNotCall(); return 0, nil }); err != nil || got != 0.5 {
+		t.Errorf("Float64Func(): got %v, %v", got, err)
+	}
+	if got, err := env.DurationFunc("ENV_TEST_FUNC_DURATION", func() (time.Duration, error) { mustNotCall(); return 0, nil }); err != nil || got != time.Minute {
+		t.Errorf("DurationFunc(): got %v, %v", got, err)
+	}
+
+	if got, err := env.IntFunc("ENV_TEST_FUNC_INVALID", func() (int, error) { return 42, nil }); err != nil || got != 42 {
+		t.Errorf("IntFunc(): got %v, %v", got, err)
+	}
+	if got, err := env.DurationFunc("ENV_TEST_FUNC_UNSET", func() (time.Duration, error) { return time.Hour, nil }); err != nil || got != time.Hour {
+		t.Errorf("DurationFunc(): got %v, %v", got, err)
+	}
+}
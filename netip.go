@@ -0,0 +1,272 @@
+package env
+
+import (
+	"fmt"
+	"net"
+	"net/netip"
+	"strconv"
+	"strings"
+)
+
+// Address is a network address made of a host and a port, such as
+// "db.internal:5432" or "127.0.0.1:8080". Unlike netip.AddrPort, the host may be
+// a host name, and it may be empty to denote all local addresses, as in ":8080".
+type Address struct {
+	Host string
+	Port uint16
+}
+
+// String returns the address in "host:port" form, with IPv6 hosts enclosed in
+// square brackets.
+func (a Address) String() string {
+	return net.JoinHostPort(a.Host, strconv.FormatUint(uint64(a.Port), 10))
+}
+
+// ParseAddress parses s as a "host:port" network address. The host may be an IP
+// address, a host name or empty, and the port must be numeric.
+func ParseAddress(s string) (Address, error) {
+	host, port, err := net.SplitHostPort(s)
+	if err != nil {
+		return Address{}, err
+	}
+
+	p, err := strconv.ParseUint(port, 10, 16)
+	if err != nil {
+		return Address{}, fmt.Errorf("env: invalid port %q in address %q", port, s)
+	}
+
+	if _, err := netip.ParseAddr(host); err != nil && !isHostname(host) {
+		return Address{}, fmt.Errorf("env: invalid host %q in address %q", host, s)
+	}
+
+	return Address{Host: host, Port: uint16(p)}, nil
+}
+
+// isHostname reports whether s is empty or a syntactically valid host name.
+func isHostname(s string) bool {
+	if len(s) > 253 {
+		return false
+	}
+
+	for _, label := range strings.Split(strings.TrimSuffix(s, "."), ".") {
+		if len(label) == 0 && len(s) > 0 || len(label) > 63 {
+			return false
+		}
+		if strings.HasPrefix(label, "-") || strings.HasSuffix(label, "-") {
+			return false
+		}
+		for _, c := range label {
+			if !(c == '-' || c == '_' || '0' <= c && c <= '9' || 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z') {
+				return false
+			}
+		}
+	}
+
+	return true
+}
+
+// parseList splits s into comma-separated elements and parses each one using
+// parse. Whitespace around elements is ignored, and so are empty elements.
+func parseList[T any](s string, parse func(string) (T, error)) ([]T, error) {
+	res := make([]T, 0)
+	for _, elem := range strings.Split(s, ",") {
+		elem = strings.TrimSpace(elem)
+		if len(elem) == 0 {
+			continue
+		}
+
+		v, err := parse(elem)
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, v)
+	}
+
+	return res, nil
+}
+
+// Addr retrieves the value of the environment variable named by the key, parses
+// the value as an IP address, and returns the result. If the variable is not
+// present or its value cannot be parsed, fallback is returned.
+func (vs *VarSet) Addr(key string, fallback netip.Addr) netip.Addr {
+	return get(vs, key, fallback, netip.ParseAddr)
+}
+
+// CIDR retrieves the value of the environment variable named by the key, parses
+// the value as an IP network in CIDR notation, and returns the result. If the
+// variable is not present or its value cannot be parsed, fallback is returned.
+func (vs *VarSet) CIDR(key string, fallback netip.Prefix) netip.Prefix {
+	return get(vs, key, fallback, netip.ParsePrefix)
+}
+
+// AddrPort retrieves the value of the environment variable named by the key,
+// parses the value as an IP address and port, and returns the result. If the
+// variable is not present or its value cannot be parsed, fallback is returned.
+func (vs *VarSet) AddrPort(key string, fallback netip.AddrPort) netip.AddrPort {
+	return get(vs, key, fallback, netip.ParseAddrPort)
+}
+
+// HostPort retrieves the value of the environment variable named by the key,
+// parses the value as a "host:port" network address, and returns the result. If
+// the variable is not present or its value cannot be parsed, fallback is
+// returned.
+func (vs *VarSet) HostPort(key string, fallback Address) Address {
+	return get(vs, key, fallback, ParseAddress)
+}
+
+// Addrs retrieves the value of the environment variable named by the key, parses
+// the value as a comma-separated list of IP addresses, and returns the result.
+// If the variable is not present or any element cannot be parsed, fallback is
+// returned.
+func (vs *VarSet) Addrs(key string, fallback []netip.Addr) []netip.Addr {
+	return get(vs, key, fallback, func(s string) ([]netip.Addr, error) {
+		return parseList(s, netip.ParseAddr)
+	})
+}
+
+// CIDRs retrieves the value of the environment variable named by the key, parses
+// the value as a comma-separated list of IP networks in CIDR notation, and
+// returns the result. If the variable is not present or any element cannot be
+// parsed, fallback is returned.
+func (vs *VarSet) CIDRs(key string, fallback []netip.Prefix) []netip.Prefix {
+	return get(vs, key, fallback, func(s string) ([]netip.Prefix, error) {
+		return parseList(s, netip.ParsePrefix)
+	})
+}
+
+// AddrPorts retrieves the value of the environment variable named by the key,
+// parses the value as a comma-separated list of IP addresses and ports, and
+// returns the result. If the variable is not present or any element cannot be
+// parsed, fallback is returned.
+func (vs *VarSet) AddrPorts(key string, fallback []netip.AddrPort) []netip.AddrPort {
+	return get(vs, key, fallback, func(s string) ([]netip.AddrPort, error) {
+		return parseList(s, netip.ParseAddrPort)
+	})
+}
+
+// HostPorts retrieves the value of the environment variable named by the key,
+// parses the value as a comma-separated list of "host:port" network addresses,
+// and returns the result. If the variable is not present or any element cannot
+// be parsed, fallback is returned.
+func (vs *VarSet) HostPorts(key string, fallback []Address) []Address {
+	return get(vs, key, fallback, func(s string) ([]Address, error) {
+		return parseList(s, ParseAddress)
+	})
+}
+
+// Addr retrieves the value of the environment variable named by the key, parses
+// the value as an IP address, and returns the result. If the variable is not
+// present or its value cannot be parsed, fallback is returned.
+func Addr(key string, fallback netip.Addr) netip.Addr {
+	return osVarSet.Addr(key, fallback)
+}
+
+// CIDR retrieves the value of the environment variable named by the key, parses
+// the value as an IP network in CIDR notation, and returns the result. If the
+// variable is not present or its value cannot be parsed, fallback is returned.
+func CIDR(key string, fallback netip.Prefix) netip.Prefix {
+	return osVarSet.CIDR(key, fallback)
+}
+
+// AddrPort retrieves the value of the environment variable named by the key,
+// parses the value as an IP address and port, and returns the result. If the
+// variable is not present or its value cannot be parsed, fallback is returned.
+func AddrPort(key string, fallback netip.AddrPort) netip.AddrPort {
+	return osVarSet.AddrPort(key, fallback)
+}
+
+// HostPort retrieves the value of the environment variable named by the key,
+// parses the value as a "host:port" network address, and returns the result. If
+// the variable is not present or its value cannot be parsed, fallback is
+// returned.
+func HostPort(key string, fallback Address) Address {
+	return osVarSet.HostPort(key, fallback)
+}
+
+// Addrs retrieves the value of the environment variable named by the key, parses
+// the value as a comma-separated list of IP addresses, and returns the result.
+// If the variable is not present or any element cannot be parsed, fallback is
+// returned.
+func Addrs(key string, fallback []netip.Addr) []netip.Addr {
+	return osVarSet.Addrs(key, fallback)
+}
+
+// CIDRs retrieves the value of the environment variable named by the key, parses
+// the value as a comma-separated list of IP networks in CIDR notation, and
+// returns the result. If the variable is not present or any element cannot be
+// parsed, fallback is returned.
+func CIDRs(key string, fallback []netip.Prefix) []netip.Prefix {
+	return osVarSet.CIDRs(key, fallback)
+}
+
+// AddrPorts retrieves the value of the environment variable named by the key,
+// parses the value as a comma-separated list of IP addresses and ports, and
+// returns the result. If the variable is not present or any element cannot be
+// parsed, fallback is returned.
+func AddrPorts(key string, fallback []netip.AddrPort) []netip.AddrPort {
+	return osVarSet.AddrPorts(key, fallback)
+}
+
+// HostPorts retrieves the value of the environment variable named by the key,
+// parses the value as a comma-separated list of "host:port" network addresses,
+// and returns the result. If the variable is not present or any element cannot
+// be parsed, fallback is returned.
+func HostPorts(key string, fallback []Address) []Address {
+	return osVarSet.HostPorts(key, fallback)
+}
+
+// AddrVar retrieves the value of the environment variable named by the key,
+// parses the value as an IP address, and stores the result into the variable
+// pointed by p.
+func AddrVar(p *netip.Addr, key string, fallback netip.Addr) {
+	*p = osVarSet.Addr(key, fallback)
+}
+
+// CIDRVar retrieves the value of the environment variable named by the key,
+// parses the value as an IP network in CIDR notation, and stores the result into
+// the variable pointed by p.
+func CIDRVar(p *netip.Prefix, key string, fallback netip.Prefix) {
+	*p = osVarSet.CIDR(key, fallback)
+}
+
+// AddrPortVar retrieves the value of the environment variable named by the key,
+// parses the value as an IP address and port, and stores the result into the
+// variable pointed by p.
+func AddrPortVar(p *netip.AddrPort, key string, fallback netip.AddrPort) {
+	*p = osVarSet.AddrPort(key, fallback)
+}
+
+// HostPortVar retrieves the value of the environment variable named by the key,
+// parses the value as a "host:port" network address, and stores the result into
+// the variable pointed by p.
+func HostPortVar(p *Address, key string, fallback Address) {
+	*p = osVarSet.HostPort(key, fallback)
+}
+
+// AddrsVar retrieves the value of the environment variable named by the key,
+// parses the value as a comma-separated list of IP addresses, and stores the
+// result into the variable pointed by p.
+func AddrsVar(p *[]netip.Addr, key string, fallback []netip.Addr) {
+	*p = osVarSet.Addrs(key, fallback)
+}
+
+// CIDRsVar retrieves the value of the environment variable named by the key,
+// parses the value as a comma-separated list of IP networks in CIDR notation,
+// and stores the result into the variable pointed by p.
+func CIDRsVar(p *[]netip.Prefix, key string, fallback []netip.Prefix) {
+	*p = osVarSet.CIDRs(key, fallback)
+}
+
+// AddrPortsVar retrieves the value of the environment variable named by the key,
+// parses the value as a comma-separated list of IP addresses and ports, and
+// stores the result into the variable pointed by p.
+func AddrPortsVar(p *[]netip.AddrPort, key string, fallback []netip.AddrPort) {
+	*p = osVarSet.AddrPorts(key, fallback)
+}
+
+// HostPortsVar retrieves the value of the environment variable named by the key,
+// parses the value as a comma-separated list of "host:port" network addresses,
+// and stores the result into the variable pointed by p.
+func HostPortsVar(p *[]Address, key string, fallback []Address) {
+	*p = osVarSet.HostPorts(key, fallback)
+}
@@ -0,0 +1,319 @@
+package env_test
+
+import (
+	"net/netip"
+	"reflect"
+	"testing"
+
+	"github.com/christgf/env"
+)
+
+func TestAddr(t *testing.T) {
+	const envKey = "ENV_TEST_ADDR"
+
+	fallback := netip.MustParseAddr("127.0.0.1")
+	if got := env.Addr(envKey, fallback); got != fallback {
+		t.Errorf("Addr(%q): got %v, want %v", envKey, got, fallback)
+	}
+
+	tests := []struct {
+		name      string
+		envValue  string
+		wantValue netip.Addr
+	}{
+		{
+			name:      "value is empty",
+			envValue:  "",
+			wantValue: fallback,
+		},
+		{
+			name:      "value is IPv4",
+			envValue:  "10.0.0.1",
+			wantValue: netip.MustParseAddr("10.0.0.1"),
+		},
+		{
+			name:      "value is IPv6",
+			envValue:  "::1",
+			wantValue: netip.IPv6Loopback(),
+		},
+		{
+			name:      "value is CIDR",
+			envValue:  "10.0.0.0/8",
+			wantValue: fallback,
+		},
+		{
+			name:      "value is foobar",
+			envValue:  "foobar",
+			wantValue: fallback,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv(envKey, tt.envValue)
+			if got, want := env.Addr(envKey, fallback), tt.wantValue; got != want {
+				t.Errorf("Addr(%q): got %v, want %v", envKey, got, want)
+			}
+
+			var p netip.Addr
+			env.AddrVar(&p, envKey, fallback)
+			if got, want := p, tt.wantValue; got != want {
+				t.Errorf("AddrVar(%q): got %v, want %v", envKey, got, want)
+			}
+
+			prefix, key := "ENV_", "TEST_ADDR"
+			env.SetPrefix(prefix)
+			if got, want := env.Addr(key, fallback), tt.wantValue; got != want {
+				t.Errorf("Addr(Prefix=%q, Key=%q): got %v, want %v", prefix, key, got, want)
+			}
+
+			env.SetPrefix("")
+			if got, want := env.Addr(key, fallback), fallback; got != want {
+				t.Errorf("Addr(Prefix=%q, Key=%q): got %v, want %v", prefix, key, got, want)
+			}
+		})
+	}
+}
+
+func TestCIDR(t *testing.T) {
+	const envKey = "ENV_TEST_CIDR"
+
+	fallback := netip.MustParsePrefix("127.0.0.0/8")
+	tests := []struct {
+		name      string
+		envValue  string
+		wantValue netip.Prefix
+	}{
+		{
+			name:      "value is empty",
+			envValue:  "",
+			wantValue: fallback,
+		},
+		{
+			name:      "value is IPv4 network",
+			envValue:  "10.0.0.0/8",
+			wantValue: netip.MustParsePrefix("10.0.0.0/8"),
+		},
+		{
+			name:      "value is IPv6 network",
+			envValue:  "fd00::/8",
+			wantValue: netip.MustParsePrefix("fd00::/8"),
+		},
+		{
+			name:      "value is address",
+			envValue:  "10.0.0.1",
+			wantValue: fallback,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv(envKey, tt.envValue)
+			if got, want := env.CIDR(envKey, fallback), tt.wantValue; got != want {
+				t.Errorf("CIDR(%q): got %v, want %v", envKey, got, want)
+			}
+
+			var p netip.Prefix
+			env.CIDRVar(&p, envKey, fallback)
+			if got, want := p, tt.wantValue; got != want {
+				t.Errorf("CIDRVar(%q): got %v, want %v", envKey, got, want)
+			}
+		})
+	}
+}
+
+func TestAddrPort(t *testing.T) {
+	const envKey = "ENV_TEST_ADDR_PORT"
+
+	fallback := netip.MustParseAddrPort("127.0.0.1:8080")
+	tests := []struct {
+		name      string
+		envValue  string
+		wantValue netip.AddrPort
+	}{
+		{
+			name:      "value is empty",
+			envValue:  "",
+			wantValue: fallback,
+		},
+		{
+			name:      "value is IPv4",
+			envValue:  "10.0.0.1:80",
+			wantValue: netip.MustParseAddrPort("10.0.0.1:80"),
+		},
+		{
+			name:      "value is IPv6",
+			envValue:  "[::1]:443",
+			wantValue: netip.MustParseAddrPort("[::1]:443"),
+		},
+		{
+			name:      "value is host name",
+			envValue:  "localhost:80",
+			wantValue: fallback,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv(envKey, tt.envValue)
+			if got, want := env.AddrPort(envKey, fallback), tt.wantValue; got != want {
+				t.Errorf("AddrPort(%q): got %v, want %v", envKey, got, want)
+			}
+
+			var p netip.AddrPort
+			env.AddrPortVar(&p, envKey, fallback)
+			if got, want := p, tt.wantValue; got != want {
+				t.Errorf("AddrPortVar(%q): got %v, want %v", envKey, got, want)
+			}
+		})
+	}
+}
+
+func TestHostPort(t *testing.T) {
+	const envKey = "ENV_TEST_HOST_PORT"
+
+	fallback := env.Address{Host: "localhost", Port: 8080}
+	tests := []struct {
+		name      string
+		envValue  string
+		wantValue env.Address
+	}{
+		{
+			name:      "value is empty",
+			envValue:  "",
+			wantValue: fallback,
+		},
+		{
+			name:      "value is host name",
+			envValue:  "db.internal:5432",
+			wantValue: env.Address{Host: "db.internal", Port: 5432},
+		},
+		{
+			name:      "value is IPv6",
+			envValue:  "[::1]:443",
+			wantValue: env.Address{Host: "::1", Port: 443},
+		},
+		{
+			name:      "host is empty",
+			envValue:  ":9090",
+			wantValue: env.Address{Port: 9090},
+		},
+		{
+			name:      "port is missing",
+			envValue:  "db.internal",
+			wantValue: fallback,
+		},
+		{
+			name:      "port is out of range",
+			envValue:  "db.internal:65536",
+			wantValue: fallback,
+		},
+		{
+			name:      "port is named",
+			envValue:  "db.internal:http",
+			wantValue: fallback,
+		},
+		{
+			name:      "host is invalid",
+			envValue:  "db/internal:80",
+			wantValue: fallback,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv(envKey, tt.envValue)
+			if got, want := env.HostPort(envKey, fallback), tt.wantValue; got != want {
+				t.Errorf("HostPort(%q): got %v, want %v", envKey, got, want)
+			}
+
+			var p env.Address
+			env.HostPortVar(&p, envKey, fallback)
+			if got, want := p, tt.wantValue; got != want {
+				t.Errorf("HostPortVar(%q): got %v, want %v", envKey, got, want)
+			}
+		})
+	}
+
+	if got, want := (env.Address{Host: "::1", Port: 443}).String(), "[::1]:443"; got != want {
+		t.Errorf("String(): got %q, want %q", got, want)
+	}
+}
+
+func TestCIDRs(t *testing.T) {
+	const envKey = "ENV_TEST_CIDRS"
+
+	fallback := []netip.Prefix{netip.MustParsePrefix("127.0.0.0/8")}
+	tests := []struct {
+		name      string
+		envValue  string
+		wantValue []netip.Prefix
+	}{
+		{
+			name:      "value is empty",
+			envValue:  "",
+			wantValue: []netip.Prefix{},
+		},
+		{
+			name:      "value is single network",
+			envValue:  "10.0.0.0/8",
+			wantValue: []netip.Prefix{netip.MustParsePrefix("10.0.0.0/8")},
+		},
+		{
+			name:      "value is list",
+			envValue:  "10.0.0.0/8, 172.16.0.0/12,,fd00::/8",
+			wantValue: []netip.Prefix{netip.MustParsePrefix("10.0.0.0/8"), netip.MustParsePrefix("172.16.0.0/12"), netip.MustParsePrefix("fd00::/8")},
+		},
+		{
+			name:      "element is invalid",
+			envValue:  "10.0.0.0/8,foobar",
+			wantValue: fallback,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv(envKey, tt.envValue)
+			if got, want := env.CIDRs(envKey, fallback), tt.wantValue; !reflect.DeepEqual(got, want) {
+				t.Errorf("CIDRs(%q): got %v, want %v", envKey, got, want)
+			}
+
+			var p []netip.Prefix
+			env.CIDRsVar(&p, envKey, fallback)
+			if got, want := p, tt.wantValue; !reflect.DeepEqual(got, want) {
+				t.Errorf("CIDRsVar(%q): got %v, want %v", envKey, got, want)
+			}
+		})
+	}
+}
+
+func TestAddrsLists(t *testing.T) {
+	t.Setenv("ENV_TEST_ADDRS", "10.0.0.1, ::1")
+	t.Setenv("ENV_TEST_ADDR_PORTS", "10.0.0.1:80,[::1]:443")
+	t.Setenv("ENV_TEST_HOST_PORTS", "kafka-0:9092,kafka-1:9092")
+
+	if got, want := env.Addrs("ENV_TEST_ADDRS", nil), []netip.Addr{netip.MustParseAddr("10.0.0.1"), netip.IPv6Loopback()}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Addrs(%q): got %v, want %v", "ENV_TEST_ADDRS", got, want)
+	}
+
+	if got, want := env.AddrPorts("ENV_TEST_ADDR_PORTS", nil), []netip.AddrPort{netip.MustParseAddrPort("10.0.0.1:80"), netip.MustParseAddrPort("[::1]:443")}; !reflect.DeepEqual(got, want) {
+		t.Errorf("AddrPorts(%q): got %v, want %v", "ENV_TEST_ADDR_PORTS", got, want)
+	}
+
+	if got, want := env.HostPorts("ENV_TEST_HOST_PORTS", nil), []env.Address{{Host: "kafka-0", Port: 9092}, {Host: "kafka-1", Port: 9092}}; !reflect.DeepEqual(got, want) {
+		t.Errorf("HostPorts(%q): got %v, want %v", "ENV_TEST_HOST_PORTS", got, want)
+	}
+
+	var addrs []netip.Addr
+	env.AddrsVar(&addrs, "ENV_TEST_ADDRS_UNSET", nil)
+	if addrs != nil {
+		t.Errorf("AddrsVar(%q): got %v, want nil", "ENV_TEST_ADDRS_UNSET", addrs)
+	}
+
+	var addrPorts []netip.AddrPort
+	env.AddrPortsVar(&addrPorts, "ENV_TEST_ADDR_PORTS", nil)
+	if got, want := len(addrPorts), 2; got != want {
+		t.Errorf("AddrPortsVar(%q): got %d elements, want %d", "ENV_TEST_ADDR_PORTS", got, want)
+	}
+
+	var hostPorts []env.Address
+	env.HostPortsVar(&hostPorts, "ENV_TEST_HOST_PORTS", nil)
+	if got, want := len(hostPorts), 2; got != want {
+		t.Errorf("HostPortsVar(%q): got %d elements, want %d", "ENV_TEST_HOST_PORTS", got, want)
+	}
+}
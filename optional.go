@@ -0,0 +1,278 @@
+package env
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// Optional is a value that remembers whether it was set. The zero Optional is
+// not set. Optional values are returned by OptionalString, OptionalInt, et al.,
+// to tell a variable that is not present in the environment apart from one that
+// is set to the zero value of its type, so that layered configuration can be
+// merged without sentinel values.
+type Optional[T any] struct {
+	value T
+	set   bool
+}
+
+// Some returns an Optional that is set to value.
+func Some[T any](value T) Optional[T] {
+	return Optional[T]{value: value, set: true}
+}
+
+// Value returns the value of o, or the zero value of T if o is not set.
+func (o Optional[T]) Value() T {
+	return o.value
+}
+
+// IsSet reports whether o is set.
+func (o Optional[T]) IsSet() bool {
+	return o.set
+}
+
+// OrElse returns the value of o if o is set, otherwise it returns fallback.
+func (o Optional[T]) OrElse(fallback T) T {
+	if !o.set {
+		return fallback
+	}
+
+	return o.value
+}
+
+// Or returns o if o is set, otherwise it returns other. It can be used to merge
+// layers of configuration, from the most to the least specific, as in
+// override.Or(file).Or(defaults).
+func (o Optional[T]) Or(other Optional[T]) Optional[T] {
+	if !o.set {
+		return other
+	}
+
+	return o
+}
+
+// String formats the value of o, or returns "unset" if o is not set.
+func (o Optional[T]) String() string {
+	if !o.set {
+		return "unset"
+	}
+
+	return fmt.Sprint(o.value)
+}
+
+// OptionalOf retrieves the value of the environment variable named by the key
+// from vs, parses the value using parse, and returns the result as a set
+// Optional. If the variable is not present or its value cannot be parsed, the
+// returned Optional is not set. Parse functions of this package, such as
+// ParseByteSize or ParseExtendedDuration, can be used with OptionalOf.
+func OptionalOf[T any](vs *VarSet, key string, parse func(string) (T, error)) Optional[T] {
+	value, origin, err := vs.lookup(key)
+	if len(origin) == 0 {
+		vs.record(key, nil, nil, originDefault, err)
+		return Optional[T]{}
+	}
+
+	res, err := parse(value)
+	if err != nil {
+		vs.record(key, nil, nil, originDefault, parseErr(err, value))
+		return Optional[T]{}
+	}
+
+	vs.record(key, res, nil, origin, nil)
+	return Some(res)
+}
+
+// OptionalString retrieves the value of the environment variable named by the
+// key, and returns it as a set Optional. If the variable is not present, the
+// returned Optional is not set.
+func (vs *VarSet) OptionalString(key string) Optional[string] {
+	return OptionalOf(vs, key, func(s string) (string, error) {
+		return s, nil
+	})
+}
+
+// OptionalBool retrieves the value of the environment variable named by the
+// key, parses the value as a boolean, and returns the result as a set Optional.
+// If the variable is not present or its value cannot be parsed, the returned
+// Optional is not set.
+func (vs *VarSet) OptionalBool(key string) Optional[bool] {
+	return OptionalOf(vs, key, vs.boolParser())
+}
+
+// OptionalInt retrieves the value of the environment variable named by the key,
+// parses the value as an integer, and returns the result as a set Optional. If
+// the variable is not present or its value cannot be parsed, the returned
+// Optional is not set.
+func (vs *VarSet) OptionalInt(key string) Optional[int] {
+	return OptionalOf(vs, key, func(s string) (int, error) {
+		n, err := ParseInt(s, vs.intFormat, strconv.IntSize)
+		return int(n), err
+	})
+}
+
+// OptionalInt64 retrieves the value of the environment variable named by the
+// key, parses the value as a 64-bit integer, and returns the result as a set
+// Optional. If the variable is not present or its value cannot be parsed, the
+// returned Optional is not set.
+func (vs *VarSet) OptionalInt64(key string) Optional[int64] {
+	return OptionalOf(vs, key, func(s string) (int64, error) {
+		return ParseInt(s, vs.intFormat, 64)
+	})
+}
+
+// OptionalUint retrieves the value of the environment variable named by the
+// key, parses the value as an unsigned integer, and returns the result as a set
+// Optional. If the variable is not present or its value cannot be parsed, the
+// returned Optional is not set.
+func (vs *VarSet) OptionalUint(key string) Optional[uint] {
+	return OptionalOf(vs, key, func(s string) (uint, error) {
+		n, err := ParseUint(s, vs.intFormat, strconv.IntSize)
+		return uint(n), err
+	})
+}
+
+// OptionalUint64 retrieves the value of the environment variable named by the
+// key, parses the value as an unsigned 64-bit integer, and returns the result as
+// a set Optional. If the variable is not present or its value cannot be parsed,
+// the returned Optional is not set.
+func (vs *VarSet) OptionalUint64(key string) Optional[uint64] {
+	return OptionalOf(vs, key, func(s string) (uint64, error) {
+		return ParseUint(s, vs.intFormat, 64)
+	})
+}
+
+// OptionalFloat64 retrieves the value of the environment variable named by the
+// key, parses the value as a 64-bit floating-point number, and returns the
+// result as a set Optional. If the variable is not present or its value cannot
+// be parsed, the returned Optional is not set.
+func (vs *VarSet) OptionalFloat64(key string) Optional[float64] {
+	return OptionalOf(vs, key, func(s string) (float64, error) {
+		return strconv.ParseFloat(s, 64)
+	})
+}
+
+// OptionalDuration retrieves the value of the environment variable named by the
+// key, parses the value as time.Duration, and returns the result as a set
+// Optional. If the variable is not present or its value cannot be parsed, the
+// returned Optional is not set.
+func (vs *VarSet) OptionalDuration(key string) Optional[time.Duration] {
+	return OptionalOf(vs, key, time.ParseDuration)
+}
+
+// OptionalString retrieves the value of the environment variable named by the
+// key, and returns it as a set Optional. If the variable is not present, the
+// returned Optional is not set.
+func OptionalString(key string) Optional[string] {
+	return osVarSet.OptionalString(key)
+}
+
+// OptionalBool retrieves the value of the environment variable named by the
+// key, parses the value as a boolean, and returns the result as a set Optional.
+// If the variable is not present or its value cannot be parsed, the returned
+// Optional is not set.
+func OptionalBool(key string) Optional[bool] {
+	return osVarSet.OptionalBool(key)
+}
+
+// OptionalInt retrieves the value of the environment variable named by the key,
+// parses the value as an integer, and returns the result as a set Optional. If
+// the variable is not present or its value cannot be parsed, the returned
+// Optional is not set.
+func OptionalInt(key string) Optional[int] {
+	return osVarSet.OptionalInt(key)
+}
+
+// OptionalInt64 retrieves the value of the environment variable named by the
+// key, parses the value as a 64-bit integer, and returns the result as a set
+// Optional. If the variable is not present or its value cannot be parsed, the
+// returned Optional is not set.
+func OptionalInt64(key string) Optional[int64] {
+	return osVarSet.OptionalInt64(key)
+}
+
+// OptionalUint retrieves the value of the environment variable named by the
+// key, parses the value as an unsigned integer, and returns the result as a set
+// Optional. If the variable is not present or its value cannot be parsed, the
+// returned Optional is not set.
+func OptionalUint(key string) Optional[uint] {
+	return osVarSet.OptionalUint(key)
+}
+
+// OptionalUint64 retrieves the value of the environment variable named by the
+// key, parses the value as an unsigned 64-bit integer, and returns the result as
+// a set Optional. If the variable is not present or its value cannot be parsed,
+// the returned Optional is not set.
+func OptionalUint64(key string) Optional[uint64] {
+	return osVarSet.OptionalUint64(key)
+}
+
+// OptionalFloat64 retrieves the value of the environment variable named by the
+// key, parses the value as a 64-bit floating-point number, and returns the
+// result as a set Optional. If the variable is not present or its value cannot
+// be parsed, the returned Optional is not set.
+func OptionalFloat64(key string) Optional[float64] {
+	return osVarSet.OptionalFloat64(key)
+}
+
+// OptionalDuration retrieves the value of the environment variable named by the
+// key, parses the value as time.Duration, and returns the result as a set
+// Optional. If the variable is not present or its value cannot be parsed, the
+// returned Optional is not set.
+func OptionalDuration(key string) Optional[time.Duration] {
+	return osVarSet.OptionalDuration(key)
+}
+
+// OptionalStringVar retrieves the value of the environment variable named by
+// the key, and stores it as an Optional into the variable pointed by p.
+func OptionalStringVar(p *Optional[string], key string) {
+	*p = osVarSet.OptionalString(key)
+}
+
+// OptionalBoolVar retrieves the value of the environment variable named by the
+// key, parses the value as a boolean, and stores the result as an Optional into
+// the variable pointed by p.
+func OptionalBoolVar(p *Optional[bool], key string) {
+	*p = osVarSet.OptionalBool(key)
+}
+
+// OptionalIntVar retrieves the value of the environment variable named by the
+// key, parses the value as an integer, and stores the result as an Optional into
+// the variable pointed by p.
+func OptionalIntVar(p *Optional[int], key string) {
+	*p = osVarSet.OptionalInt(key)
+}
+
+// OptionalInt64Var retrieves the value of the environment variable named by the
+// key, parses the value as a 64-bit integer, and stores the result as an
+// Optional into the variable pointed by p.
+func OptionalInt64Var(p *Optional[int64], key string) {
+	*p = osVarSet.OptionalInt64(key)
+}
+
+// OptionalUintVar retrieves the value of the environment variable named by the
+// key, parses the value as an unsigned integer, and stores the result as an
+// Optional into the variable pointed by p.
+func OptionalUintVar(p *Optional[uint], key string) {
+	*p = osVarSet.OptionalUint(key)
+}
+
+// OptionalUint64Var retrieves the value of the environment variable named by
+// the key, parses the value as an unsigned 64-bit integer, and stores the result
+// as an Optional into the variable pointed by p.
+func OptionalUint64Var(p *Optional[uint64], key string) {
+	*p = osVarSet.OptionalUint64(key)
+}
+
+// OptionalFloat64Var retrieves the value of the environment variable named by
+// the key, parses the value as a 64-bit floating-point number, and stores the
+// result as an Optional into the variable pointed by p.
+func OptionalFloat64Var(p *Optional[float64], key string) {
+	*p = osVarSet.OptionalFloat64(key)
+}
+
+// OptionalDurationVar retrieves the value of the environment variable named by
+// the key, parses the value as time.Duration, and stores the result as an
+// Optional into the variable pointed by p.
+func OptionalDurationVar(p *Optional[time.Duration], key string) {
+	*p = osVarSet.OptionalDuration(key)
+}
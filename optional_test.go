@@ -0,0 +1,150 @@
+package env_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/christgf/env"
+)
+
+func TestOptional(t *testing.T) {
+	var unset env.Optional[int]
+	if unset.IsSet() {
+		t.Errorf("IsSet(): got true")
+	}
+	if got, want := unset.Value(), 0; got != want {
+		t.Errorf("Value(): got %d, want %d", got, want)
+	}
+	if got, want := unset.OrElse(42), 42; got != want {
+		t.Errorf("OrElse(42): got %d, want %d", got, want)
+	}
+	if got, want := unset.String(), "unset"; got != want {
+		t.Errorf("String(): got %q, want %q", got, want)
+	}
+
+	zero := env.Some(0)
+	if !zero.IsSet() {
+		t.Errorf("IsSet(): got false")
+	}
+	if got, want := zero.OrElse(42), 0; got != want {
+		t.Errorf("OrElse(42): got %d, want %d", got, want)
+	}
+	if got, want := zero.String(), "0"; got != want {
+		t.Errorf("String(): got %q, want %q", got, want)
+	}
+
+	defaults := env.Some(10)
+	if got, want := unset.Or(zero).Or(defaults), zero; got != want {
+		t.Errorf("Or(): got %v, want %v", got, want)
+	}
+	if got, want := unset.Or(unset).Or(defaults), defaults; got != want {
+		t.Errorf("Or(): got %v, want %v", got, want)
+	}
+}
+
+func TestOptionalString(t *testing.T) {
+	const envKey = "ENV_TEST_OPTIONAL_STRING"
+
+	if got := env.OptionalString(envKey); got.IsSet() {
+		t.Errorf("OptionalString(%q): got %v", envKey, got)
+	}
+
+	t.Setenv(envKey, "")
+	if got, want := env.OptionalString(envKey), env.Some(""); got != want {
+		t.Errorf("OptionalString(%q): got %v, want %v", envKey, got, want)
+	}
+
+	var p env.Optional[string]
+	env.OptionalStringVar(&p, envKey)
+	if got, want := p, env.Some(""); got != want {
+		t.Errorf("OptionalStringVar(%q): got %v, want %v", envKey, got, want)
+	}
+
+	prefix, key := "ENV_", "TEST_OPTIONAL_STRING"
+	env.SetPrefix(prefix)
+	if got, want := env.OptionalString(key), env.Some(""); got != want {
+		t.Errorf("OptionalString(Prefix=%q, Key=%q): got %v, want %v", prefix, key, got, want)
+	}
+
+	env.SetPrefix("")
+	if got := env.OptionalString(key); got.IsSet() {
+		t.Errorf("OptionalString(Prefix=%q, Key=%q): got %v", prefix, key, got)
+	}
+}
+
+func TestOptionalTyped(t *testing.T) {
+	t.Setenv("ENV_TEST_OPTIONAL_BOOL", "false")
+	t.Setenv("ENV_TEST_OPTIONAL_INT", "0")
+	t.Setenv("ENV_TEST_OPTIONAL_FLOAT", "0.5")
+	t.Setenv("ENV_TEST_OPTIONAL_DURATION", "0s")
+	t.Setenv("ENV_TEST_OPTIONAL_INVALID", "foobar")
+
+	if got, want := env.OptionalBool("ENV_TEST_OPTIONAL_BOOL"), env.Some(false); got != want {
+		t.Errorf("OptionalBool(): got %v, want %v", got, want)
+	}
+	if got, want := env.OptionalInt("ENV_TEST_OPTIONAL_INT"), env.Some(0); got != want {
+		t.Errorf("OptionalInt(): got %v, want %v", got, want)
+	}
+	if got, want := env.OptionalInt64("ENV_TEST_OPTIONAL_INT"), env.Some(int64(0)); got != want {
+		t.Errorf("OptionalInt64(): got %v, want %v", got, want)
+	}
+	if got, want := env.OptionalUint("ENV_TEST_OPTIONAL_INT"), env.Some(uint(0)); got != want {
+		t.Errorf("OptionalUint(): got %v, want %v", got, want)
+	}
+	if got, want := env.OptionalUint64("ENV_TEST_OPTIONAL_INT"), env.Some(uint64(0)); got != want {
+		t.Errorf("OptionalUint64(): got %v, want %v", got, want)
+	}
+	if got, want := env.OptionalFloat64("ENV_TEST_OPTIONAL_FLOAT"), env.Some(0.5); got != want {
+		t.Errorf("OptionalFloat64(): got %v, want %v", got, want)
+	}
+	if got, want := env.OptionalDuration("ENV_TEST_OPTIONAL_DURATION"), env.Some(time.Duration(0)); got != want {
+		t.Errorf("OptionalDuration(): got %v, want %v", got, want)
+	}
+
+	if got := env.OptionalBool("ENV_TEST_OPTIONAL_INVALID"); got.IsSet() {
+		t.Errorf("OptionalBool(): got %v", got)
+	}
+	if got := env.OptionalInt("ENV_TEST_OPTIONAL_INVALID"); got.IsSet() {
+		t.Errorf("OptionalInt(): got %v", got)
+	}
+	if got := env.OptionalDuration("ENV_TEST_OPTIONAL_UNSET"); got.IsSet() {
+		t.Errorf("OptionalDuration(): got %v", got)
+	}
+
+	var pb env.Optional[bool]
+	env.OptionalBoolVar(&pb, "ENV_TEST_OPTIONAL_BOOL")
+	var pi env.Optional[int]
+	env.OptionalIntVar(&pi, "ENV_TEST_OPTIONAL_INT")
+	var pi64 env.Optional[int64]
+	env.OptionalInt64Var(&pi64, "ENV_TEST_OPTIONAL_INT")
+	var pu env.Optional[uint]
+	env.OptionalUintVar(&pu, "ENV_TEST_OPTIONAL_INT")
+	var pu64 env.Optional[uint64]
+	env.OptionalUint64Var(&pu64, "ENV_TEST_OPTIONAL_INT")
+	var pf env.Optional[float64]
+	env.OptionalFloat64Var(&pf, "ENV_TEST_OPTIONAL_FLOAT")
+	var pd env.Optional[time.Duration]
+	env.OptionalDurationVar(&pd, "ENV_TEST_OPTIONAL_DURATION")
+	if !pb.IsSet() || !pi.IsSet() || !pi64.IsSet() || !pu.IsSet() || !pu64.IsSet() || !pf.IsSet() || !pd.IsSet() {
+		t.Errorf("Optional*Var(): got unset value")
+	}
+}
+
+func TestOptionalOf(t *testing.T) {
+	const envKey = "ENV_TEST_OPTIONAL_OF"
+
+	var vs env.VarSet
+	if got := env.OptionalOf(&vs, envKey, env.ParseByteSize); got.IsSet() {
+		t.Errorf("OptionalOf(%q): got %v", envKey, got)
+	}
+
+	t.Setenv(envKey, "10MiB")
+	if got, want := env.OptionalOf(&vs, envKey, env.ParseByteSize), env.Some(10*env.MiB); got != want {
+		t.Errorf("OptionalOf(%q): got %v, want %v", envKey, got, want)
+	}
+
+	t.Setenv(envKey, "foobar")
+	if got := env.OptionalOf(&vs, envKey, env.ParseByteSize); got.IsSet() {
+		t.Errorf("OptionalOf(%q): got %v", envKey, got)
+	}
+}
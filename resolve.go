@@ -0,0 +1,224 @@
+package env
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+)
+
+// maxResolveDepth limits the number of references that are followed to resolve
+// a single value.
+const maxResolveDepth = 16
+
+// Resolver resolves references to values that are kept outside the environment,
+// such as "file:///run/secrets/db". A VarSet resolves a value with a Resolver if
+// the value has the form "scheme://ref" and a Resolver is registered for the
+// scheme using RegisterResolver; Resolve is called with ref. No resolvers are
+// registered by default.
+type Resolver interface {
+	Resolve(ref string) (string, error)
+}
+
+// ResolverFunc is an adapter to allow the use of ordinary functions as
+// resolvers.
+type ResolverFunc func(ref string) (string, error)
+
+// Resolve calls f(ref).
+func (f ResolverFunc) Resolve(ref string) (string, error) {
+	return f(ref)
+}
+
+// FileResolver returns a Resolver that reads the file named by the reference,
+// such as "/run/secrets/db" in "file:///run/secrets/db". A single trailing
+// newline is removed from the contents of the file. To enable it, use:
+//
+//	vs.RegisterResolver("file", env.FileResolver())
+func FileResolver() Resolver {
+	return ResolverFunc(func(ref string) (string, error) {
+		b, err := os.ReadFile(ref)
+		if err != nil {
+			return "", fmt.Errorf("env: %w", err)
+		}
+
+		return trimNewline(string(b)), nil
+	})
+}
+
+// EnvResolver returns a Resolver that retrieves the environment variable named
+// by the reference, such as "LEGACY_API_KEY" in "env://LEGACY_API_KEY". The name
+// is used as it is, without the key mapper or the prefix of the VarSet. To
+// enable it, use:
+//
+//	vs.RegisterResolver("env", env.EnvResolver())
+func EnvResolver() Resolver {
+	return ResolverFunc(func(ref string) (string, error) {
+		value, ok := os.LookupEnv(ref)
+		if !ok {
+			return "", fmt.Errorf("env: referenced variable %s is not set", ref)
+		}
+
+		return value, nil
+	})
+}
+
+// ExecResolver returns a Resolver that runs the command given by the reference,
+// such as "/usr/local/bin/vault-helper db-password" in
+// "exec:///usr/local/bin/vault-helper db-password", and returns its standard
+// output, without a single trailing newline. The command is split into fields
+// separated by white space, and it is not run by a shell. It is killed if it
+// does not exit within timeout, and an exit status other than zero results in
+// an error.
+//
+// Since it runs arbitrary commands named by the environment, ExecResolver
+// should only be registered when the environment is trusted. To enable it, use:
+//
+//	vs.RegisterResolver("exec", env.ExecResolver(5*time.Second))
+func ExecResolver(timeout time.Duration) Resolver {
+	return ResolverFunc(func(ref string) (string, error) {
+		args := strings.Fields(ref)
+		if len(args) == 0 {
+			return "", errors.New("env: empty command")
+		}
+
+		out, err := runCommand(timeout, nil, args[0], args[1:]...)
+		if err != nil {
+			return "", fmt.Errorf("env: %w", err)
+		}
+
+		return trimNewline(string(out)), nil
+	})
+}
+
+// runCommand runs the command name with the arguments and standard input
+// provided, and returns its standard output. The command is killed if it does
+// not exit within timeout. If it fails, the error includes its standard error.
+func runCommand(timeout time.Duration, stdin io.Reader, name string, args ...string) ([]byte, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, name, args...)
+	cmd.Stdin = stdin
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		if ctx.Err() != nil {
+			err = ctx.Err()
+		}
+		if msg := strings.TrimSpace(stderr.String()); len(msg) > 0 {
+			return nil, fmt.Errorf("command %s: %w: %s", name, err, msg)
+		}
+		return nil, fmt.Errorf("command %s: %w", name, err)
+	}
+
+	return stdout.Bytes(), nil
+}
+
+// trimNewline removes a single trailing "\n" or "\r\n" from s.
+func trimNewline(s string) string {
+	s = strings.TrimSuffix(s, "\n")
+	return strings.TrimSuffix(s, "\r")
+}
+
+// RegisterResolver makes this VarSet resolve values of the form "scheme://ref"
+// using r, before they are parsed by String, Bool, Int, et al. Schemes are
+// case-insensitive. A nil r removes the resolver for scheme, so that values
+// with that scheme are used as they are. No resolvers are registered by
+// default, so values such as file URLs are used as they are unless this VarSet
+// is configured otherwise. For example:
+//
+//	vs.RegisterResolver("file", env.FileResolver())
+//	vs.RegisterResolver("env", env.EnvResolver())
+//
+// Values are expanded first, if this VarSet is configured to do so, and then
+// resolved. The value a reference resolves to is resolved in turn, and a
+// reference that leads back to itself results in an error. If a value cannot
+// be resolved, getters return their fallback and the error is reported by Err.
+func (vs *VarSet) RegisterResolver(scheme string, r Resolver) {
+	vs.mu.Lock()
+	defer vs.mu.Unlock()
+
+	if vs.resolvers == nil {
+		vs.resolvers = make(map[string]Resolver)
+	}
+	scheme = strings.ToLower(scheme)
+	if r == nil {
+		delete(vs.resolvers, scheme)
+		return
+	}
+	vs.resolvers[scheme] = r
+}
+
+// resolver returns the Resolver registered for scheme, if any.
+func (vs *VarSet) resolver(scheme string) (Resolver, bool) {
+	vs.mu.Lock()
+	defer vs.mu.Unlock()
+
+	r, ok := vs.resolvers[strings.ToLower(scheme)]
+	return r, ok
+}
+
+// resolve resolves value if it is a reference with a registered scheme, and
+// returns it unchanged otherwise.
+func (vs *VarSet) resolve(value string) (string, error) {
+	var seen []string
+	for {
+		scheme, ref, ok := splitReference(value)
+		if !ok {
+			return value, nil
+		}
+		r, ok := vs.resolver(scheme)
+		if !ok {
+			return value, nil
+		}
+
+		for _, s := range seen {
+			if s == value {
+				return "", fmt.Errorf("env: cyclic reference: %s -> %s", strings.Join(seen, " -> "), value)
+			}
+		}
+		if len(seen) == maxResolveDepth {
+			return "", fmt.Errorf("env: too many references: %s", strings.Join(seen, " -> "))
+		}
+		seen = append(seen, value)
+
+		res, err := r.Resolve(ref)
+		if err != nil {
+			return "", err
+		}
+		value = res
+	}
+}
+
+// splitReference splits a value of the form "scheme://ref" into its scheme and
+// reference. The scheme must be a valid URL scheme.
+func splitReference(value string) (scheme, ref string, ok bool) {
+	i := strings.Index(value, "://")
+	if i <= 0 {
+		return "", "", false
+	}
+
+	scheme = value[:i]
+	for j, c := range scheme {
+		switch {
+		case 'a' <= c && c <= 'z', 'A' <= c && c <= 'Z':
+		case j > 0 && ('0' <= c && c <= '9' || c == '+' || c == '-' || c == '.'):
+		default:
+			return "", "", false
+		}
+	}
+
+	return scheme, value[i+len("://"):], true
+}
+
+// RegisterResolver makes the default VarSet resolve values of the form
+// "scheme://ref" using r. See VarSet.RegisterResolver.
+func RegisterResolver(scheme string, r Resolver) {
+	osVarSet.RegisterResolver(scheme, r)
+}
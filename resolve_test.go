@@ -0,0 +1,185 @@
+package env_test
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/christgf/env"
+)
+
+func TestVarSet_resolveFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "db")
+	if err := os.WriteFile(path, []byte("hunter2\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	const envKey = "ENV_TEST_DB_PASSWORD"
+	t.Setenv(envKey, "file://"+path)
+
+	var vs env.VarSet
+	if got, want := vs.String(envKey, ""), "file://"+path; got != want {
+		t.Errorf("String(%q): got %q, want %q", envKey, got, want)
+	}
+
+	vs.RegisterResolver("file", env.FileResolver())
+	if got, want := vs.String(envKey, ""), "hunter2"; got != want {
+		t.Errorf("String(%q): got %q, want %q", envKey, got, want)
+	}
+	if err := vs.Err(); err != nil {
+		t.Fatalf("Err(): got %v, want nil", err)
+	}
+
+	t.Setenv(envKey, "file://"+path+".missing")
+	if got, want := vs.String(envKey, "fallback"), "fallback"; got != want {
+		t.Errorf("String(%q): got %q, want %q", envKey, got, want)
+	}
+	if records := vs.Records(); len(records) != 1 || !errors.Is(records[0].Err, os.ErrNotExist) {
+		t.Errorf("Records(): got %+v", records)
+	}
+	if err := vs.Err(); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("Err(): got %v, want %v", err, os.ErrNotExist)
+	}
+
+	// Lookup reports the variable as present, with the value it has in the
+	// environment.
+	if got, ok := vs.Lookup(envKey); !ok || got != "file://"+path+".missing" {
+		t.Errorf("Lookup(%q): got %q, %t, want %q, true", envKey, got, ok, "file://"+path+".missing")
+	}
+
+	vs.RegisterResolver("file", nil)
+	if got, want := vs.String(envKey, ""), "file://"+path+".missing"; got != want {
+		t.Errorf("String(%q): got %q, want %q", envKey, got, want)
+	}
+}
+
+func TestVarSet_resolveEnv(t *testing.T) {
+	t.Setenv("ENV_TEST_LEGACY_API_KEY", "42")
+	t.Setenv("ENV_TEST_API_KEY", "env://ENV_TEST_LEGACY_API_KEY")
+	t.Setenv("ENV_TEST_CHAINED", "ENV://ENV_TEST_API_KEY")
+	t.Setenv("ENV_TEST_CYCLE_A", "env://ENV_TEST_CYCLE_B")
+	t.Setenv("ENV_TEST_CYCLE_B", "env://ENV_TEST_CYCLE_A")
+
+	var vs env.VarSet
+	vs.SetPrefix("ENV_TEST_")
+	vs.RegisterResolver("env", env.EnvResolver())
+	if got, want := vs.Int("API_KEY", 0), 42; got != want {
+		t.Errorf("Int(%q): got %d, want %d", "API_KEY", got, want)
+	}
+	if got, want := vs.Int("CHAINED", 0), 42; got != want {
+		t.Errorf("Int(%q): got %d, want %d", "CHAINED", got, want)
+	}
+
+	if got, want := vs.String("CYCLE_A", "fallback"), "fallback"; got != want {
+		t.Errorf("String(%q): got %q, want %q", "CYCLE_A", got, want)
+	}
+	_, err := vs.StringFunc("CYCLE_A", func() (string, error) { return "", nil })
+	if err != nil {
+		t.Fatalf("StringFunc(%q): %v", "CYCLE_A", err)
+	}
+	for _, r := range vs.Records() {
+		if r.Key != "CYCLE_A" {
+			continue
+		}
+		want := "env: cyclic reference: env://ENV_TEST_CYCLE_B -> env://ENV_TEST_CYCLE_A -> env://ENV_TEST_CYCLE_B"
+		if r.Err == nil || r.Err.Error() != want {
+			t.Errorf("Records(): got error %v, want %q", r.Err, want)
+		}
+	}
+}
+
+func TestVarSet_RegisterResolver(t *testing.T) {
+	t.Setenv("ENV_TEST_TOKEN", "vault://secret/app#token")
+	t.Setenv("ENV_TEST_HOMEPAGE", "https://example.com")
+
+	var vs env.VarSet
+	vs.RegisterResolver("Vault", env.ResolverFunc(func(ref string) (string, error) {
+		return "resolved:" + ref, nil
+	}))
+	if got, want := vs.String("ENV_TEST_TOKEN", ""), "resolved:secret/app#token"; got != want {
+		t.Errorf("String(%q): got %q, want %q", "ENV_TEST_TOKEN", got, want)
+	}
+	if got, want := vs.String("ENV_TEST_HOMEPAGE", ""), "https://example.com"; got != want {
+		t.Errorf("String(%q): got %q, want %q", "ENV_TEST_HOMEPAGE", got, want)
+	}
+}
+
+func TestVarSet_resolveDisabled(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "upload")
+	t.Setenv("ENV_TEST_UPLOAD_URL", "file://"+path)
+
+	// Without resolvers, file URLs are values like any other.
+	var vs env.VarSet
+	u := vs.URL("ENV_TEST_UPLOAD_URL", nil, env.AllowSchemes("file"))
+	if u == nil || u.Path != path {
+		t.Errorf("URL(%q): got %v, want %q", "ENV_TEST_UPLOAD_URL", u, "file://"+path)
+	}
+	if got, want := env.String("ENV_TEST_UPLOAD_URL", ""), "file://"+path; got != want {
+		t.Errorf("String(%q): got %q, want %q", "ENV_TEST_UPLOAD_URL", got, want)
+	}
+}
+
+func TestVarSet_resolveExpanded(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "db"), []byte("hunter2"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("ENV_TEST_SECRETS_DIR", dir)
+	t.Setenv("ENV_TEST_DB_PASSWORD", "file://${SECRETS_DIR}/db")
+
+	var vs env.VarSet
+	vs.SetPrefix("ENV_TEST_")
+	vs.SetExpand(true)
+	vs.RegisterResolver("file", env.FileResolver())
+	if got, want := vs.String("DB_PASSWORD", ""), "hunter2"; got != want {
+		t.Errorf("String(%q): got %q, want %q", "DB_PASSWORD", got, want)
+	}
+}
+
+func TestExecResolver(t *testing.T) {
+	t.Setenv("ENV_TEST_HELPER_PROCESS", "1")
+	command := fmt.Sprintf("exec://%s -test.run=TestExecResolverHelper --", os.Args[0])
+	t.Setenv("ENV_TEST_EXEC", command+" hunter2")
+	t.Setenv("ENV_TEST_EXEC_FAIL", command+" fail")
+
+	var vs env.VarSet
+	if got, want := vs.String("ENV_TEST_EXEC", ""), command+" hunter2"; got != want {
+		t.Errorf("String(%q): got %q, want %q", "ENV_TEST_EXEC", got, want)
+	}
+
+	vs.RegisterResolver("exec", env.ExecResolver(10*time.Second))
+	if got, want := vs.String("ENV_TEST_EXEC", ""), "hunter2"; got != want {
+		t.Errorf("String(%q): got %q, want %q", "ENV_TEST_EXEC", got, want)
+	}
+
+	if got, want := vs.String("ENV_TEST_EXEC_FAIL", "fallback"), "fallback"; got != want {
+		t.Errorf("String(%q): got %q, want %q", "ENV_TEST_EXEC_FAIL", got, want)
+	}
+	for _, r := range vs.Records() {
+		if r.Key == "ENV_TEST_EXEC_FAIL" && (r.Err == nil || !strings.Contains(r.Err.Error(), "no such secret")) {
+			t.Errorf("Records(): got error %v", r.Err)
+		}
+	}
+}
+
+// TestExecResolverHelper is run as a credential helper by TestExecResolver.
+func TestExecResolverHelper(t *testing.T) {
+	if os.Getenv("ENV_TEST_HELPER_PROCESS") != "1" {
+		t.Skip("helper process")
+	}
+
+	args := os.Args
+	for len(args) > 0 && args[0] != "--" {
+		args = args[1:]
+	}
+	if len(args) == 2 && args[1] != "fail" {
+		fmt.Println(args[1])
+		os.Exit(0)
+	}
+	fmt.Fprintln(os.Stderr, "no such secret")
+	os.Exit(1)
+}
@@ -0,0 +1,124 @@
+package env
+
+import (
+	"fmt"
+	"io"
+)
+
+// redacted replaces the value of secrets and sensitive variables wherever this
+// package prints them.
+const redacted = "[REDACTED]"
+
+// Secret is a string that is redacted when it is printed, logged or encoded, so
+// that passwords and tokens do not leak by accident. Use Reveal to retrieve the
+// value itself. The zero Secret is empty.
+type Secret struct {
+	value string
+}
+
+// NewSecret returns a Secret holding value.
+func NewSecret(value string) Secret {
+	return Secret{value: value}
+}
+
+// Reveal returns the value of s.
+func (s Secret) Reveal() string {
+	return s.value
+}
+
+// IsEmpty reports whether the value of s is empty.
+func (s Secret) IsEmpty() bool {
+	return len(s.value) == 0
+}
+
+// String returns "[REDACTED]".
+func (s Secret) String() string {
+	return redacted
+}
+
+// GoString returns "[REDACTED]".
+func (s Secret) GoString() string {
+	return redacted
+}
+
+// Format writes "[REDACTED]" for every verb, so that s cannot be printed using
+// verbs such as %d or %x either.
+func (s Secret) Format(f fmt.State, verb rune) {
+	_, _ = io.WriteString(f, redacted)
+}
+
+// MarshalText returns "[REDACTED]".
+func (s Secret) MarshalText() ([]byte, error) {
+	return []byte(redacted), nil
+}
+
+// MarshalJSON returns "[REDACTED]" as a JSON string.
+func (s Secret) MarshalJSON() ([]byte, error) {
+	return []byte(`"` + redacted + `"`), nil
+}
+
+// UnmarshalText sets the value of s to text, so that secrets can be decoded from
+// JSON values using JSON, or from other text encodings.
+func (s *Secret) UnmarshalText(text []byte) error {
+	s.value = string(text)
+	return nil
+}
+
+// MarkSensitive marks the keys provided as sensitive. Values of sensitive
+// variables are never included in the output or in the errors of this VarSet.
+// Marking a key also marks the same key wrapped in NoPrefix, and vice versa.
+func (vs *VarSet) MarkSensitive(keys ...string) {
+	vs.mu.Lock()
+	defer vs.mu.Unlock()
+
+	if vs.sensitive == nil {
+		vs.sensitive = make(map[string]bool, len(keys))
+	}
+	for _, key := range keys {
+		vs.sensitive[plainKey(key)] = true
+	}
+}
+
+// IsSensitive reports whether key was marked as sensitive, either using
+// MarkSensitive or by retrieving it using Secret.
+func (vs *VarSet) IsSensitive(key string) bool {
+	vs.mu.Lock()
+	defer vs.mu.Unlock()
+
+	return vs.sensitive[plainKey(key)]
+}
+
+// Secret marks the key as sensitive, retrieves the value of the environment
+// variable named by the key, and returns it as a Secret. If the variable is not
+// present, an empty Secret is returned.
+func (vs *VarSet) Secret(key string) Secret {
+	vs.MarkSensitive(key)
+
+	value, origin, err := vs.lookup(key)
+	if len(origin) == 0 {
+		vs.record(key, nil, nil, originDefault, err)
+		return Secret{}
+	}
+
+	vs.record(key, redacted, nil, origin, nil)
+	return Secret{value: value}
+}
+
+// MarkSensitive marks the keys provided as sensitive. Values of sensitive
+// variables are never included in the output or in the errors of this package.
+func MarkSensitive(keys ...string) {
+	osVarSet.MarkSensitive(keys...)
+}
+
+// IsSensitive reports whether key was marked as sensitive, either using
+// MarkSensitive or by retrieving it using SecretVar.
+func IsSensitive(key string) bool {
+	return osVarSet.IsSensitive(key)
+}
+
+// SecretVar marks the key as sensitive, retrieves the value of the environment
+// variable named by the key, and stores it as a Secret into the variable pointed
+// by p.
+func SecretVar(p *Secret, key string) {
+	*p = osVarSet.Secret(key)
+}
@@ -0,0 +1,10 @@
+//go:build go1.21
+
+package env
+
+import "log/slog"
+
+// LogValue implements slog.LogValuer, so that s is logged as "[REDACTED]".
+func (s Secret) LogValue() slog.Value {
+	return slog.StringValue(redacted)
+}
@@ -0,0 +1,22 @@
+//go:build go1.21
+
+package env_test
+
+import (
+	"bytes"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"github.com/christgf/env"
+)
+
+func TestSecret_LogValue(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewJSONHandler(&buf, nil))
+	logger.Info("connecting", "password", env.NewSecret("hunter2"))
+
+	if got := buf.String(); strings.Contains(got, "hunter2") || !strings.Contains(got, `"password":"[REDACTED]"`) {
+		t.Errorf("Info(): got %s", got)
+	}
+}
@@ -0,0 +1,105 @@
+package env_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/christgf/env"
+)
+
+func TestSecret_redaction(t *testing.T) {
+	const plaintext = "hunter2"
+	s := env.NewSecret(plaintext)
+
+	if got := s.Reveal(); got != plaintext {
+		t.Errorf("Reveal(): got %q, want %q", got, plaintext)
+	}
+
+	for _, format := range []string{"%v", "%+v", "%#v", "%s", "%q", "%x", "%d", "%10s"} {
+		if got := fmt.Sprintf(format, s); strings.Contains(got, plaintext) || !strings.Contains(got, "[REDACTED]") {
+			t.Errorf("Sprintf(%q): got %q", format, got)
+		}
+	}
+
+	config := struct {
+		User     string
+		Password env.Secret
+	}{User: "admin", Password: s}
+	if got := fmt.Sprintf("%+v", config); strings.Contains(got, plaintext) {
+		t.Errorf("Sprintf(%%+v): got %q", got)
+	}
+
+	b, err := json.Marshal(config)
+	if err != nil {
+		t.Fatalf("json.Marshal(): %v", err)
+	}
+	if got, want := string(b), `{"User":"admin","Password":"[REDACTED]"}`; got != want {
+		t.Errorf("json.Marshal(): got %s, want %s", got, want)
+	}
+
+	text, err := s.MarshalText()
+	if err != nil {
+		t.Fatalf("MarshalText(): %v", err)
+	}
+	if got, want := string(text), "[REDACTED]"; got != want {
+		t.Errorf("MarshalText(): got %q, want %q", got, want)
+	}
+}
+
+func TestSecret(t *testing.T) {
+	const envKey = "ENV_TEST_DB_PASSWORD"
+
+	var vs env.VarSet
+	if vs.IsSensitive(envKey) {
+		t.Errorf("IsSensitive(%q): got true", envKey)
+	}
+	if got := vs.Secret(envKey); !got.IsEmpty() {
+		t.Errorf("Secret(%q): got non-empty secret", envKey)
+	}
+	if !vs.IsSensitive(envKey) {
+		t.Errorf("IsSensitive(%q): got false", envKey)
+	}
+
+	t.Setenv(envKey, "hunter2")
+	if got, want := vs.Secret(envKey).Reveal(), "hunter2"; got != want {
+		t.Errorf("Secret(%q): got %q, want %q", envKey, got, want)
+	}
+
+	var p env.Secret
+	env.SecretVar(&p, envKey)
+	if got, want := p.Reveal(), "hunter2"; got != want {
+		t.Errorf("SecretVar(%q): got %q, want %q", envKey, got, want)
+	}
+	if !env.IsSensitive(envKey) {
+		t.Errorf("IsSensitive(%q): got false", envKey)
+	}
+}
+
+func TestSecret_JSON(t *testing.T) {
+	const envKey = "ENV_TEST_DB_CONFIG"
+
+	var vs env.VarSet
+	t.Setenv(envKey, `{"user":"admin","password":"hunter2"}`)
+	var config struct {
+		User     string     `json:"user"`
+		Password env.Secret `json:"password"`
+	}
+	if err := vs.JSON(envKey, &config); err != nil {
+		t.Fatalf("JSON(%q): %v", envKey, err)
+	}
+	if got, want := config.Password.Reveal(), "hunter2"; got != want {
+		t.Errorf("JSON(%q): got password %q, want %q", envKey, got, want)
+	}
+
+	t.Setenv(envKey, `{"user":"admin","password":hunter2}`)
+	vs.MarkSensitive(envKey)
+	err := vs.JSON(envKey, &config)
+	if err == nil {
+		t.Fatalf("JSON(%q): got no error", envKey)
+	}
+	if got, want := err.Error(), "env: ENV_TEST_DB_CONFIG: invalid JSON at offset 28"; got != want {
+		t.Errorf("JSON(%q): got error %q, want %q", envKey, got, want)
+	}
+}
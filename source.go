@@ -0,0 +1,168 @@
+package env
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Delays between attempts of a Watcher to retry failed requests.
+const (
+	minRetryDelay = time.Second
+	maxRetryDelay = time.Minute
+)
+
+// defaultRequestTimeout is the time the sources of this package give a request
+// to complete by default, so that a server that does not respond cannot block
+// lookups forever.
+const defaultRequestTimeout = 10 * time.Second
+
+// Source provides the values of variables that are not present in the
+// environment, such as secrets kept by a password manager or a secret store.
+// Lookup is called with the name of the variable, after the key mapper and the
+// prefix of the VarSet have been applied, and reports whether the variable is
+// present. The VarSet passes the name as it is, even if it ignores case. The
+// sources of this package match names exactly, and then case-insensitively if
+// no name matches exactly; if more than one name matches case-insensitively,
+// such as "db_password" and "DB_Password", Lookup fails rather than pick one.
+//
+// A Source that implements fmt.Stringer is reported by that name in errors and
+// in the origin of Dump records.
+type Source interface {
+	Lookup(name string) (value string, ok bool, err error)
+}
+
+// Change describes a variable whose value was changed in a Source.
+type Change struct {
+	// Name is the name of the variable, as passed to Lookup.
+	Name string
+	// Value is the new value of the variable, or empty if it was deleted.
+	Value string
+	// Deleted reports whether the variable is no longer present.
+	Deleted bool
+}
+
+// Watcher is implemented by sources that can notify of changes to their values,
+// such as ConsulSource and HTTPSource. Watch blocks until ctx is done, calling
+// fn with the variables that changed every time the values of the source
+// change, and then returns ctx.Err(). Lookups made while a source is watched
+// observe its latest values. Since a VarSet does not cache values, a program
+// that watches its sources may read them again whenever fn is called.
+type Watcher interface {
+	Watch(ctx context.Context, fn func([]Change)) error
+}
+
+// SensitiveSource is implemented by sources that keep secrets, such as
+// VaultSource and HelperSource. If Sensitive reports true, keys whose values
+// are retrieved from the source are marked as sensitive, so that their values
+// are redacted by Records and Dump.
+type SensitiveSource interface {
+	Sensitive() bool
+}
+
+// SetSources makes this VarSet look up variables that are not present in the
+// environment in the sources provided, in order, so that values set in the
+// environment always take precedence. Values retrieved from sources are
+// expanded, resolved and decrypted like any other. If a source fails, the
+// variable is treated as if it were not present, and the error is reported by
+// Err. Use no sources to reset.
+func (vs *VarSet) SetSources(sources ...Source) {
+	vs.mu.Lock()
+	defer vs.mu.Unlock()
+
+	vs.sources = sources
+}
+
+// isSensitive reports whether the values of src are secrets.
+func isSensitive(src Source) bool {
+	s, ok := src.(SensitiveSource)
+	return ok && s.Sensitive()
+}
+
+// sourceName returns the name of src for errors and Dump records.
+func sourceName(src Source) string {
+	if s, ok := src.(fmt.Stringer); ok {
+		return s.String()
+	}
+
+	return "source"
+}
+
+// SetSources makes the default VarSet look up variables that are not present in
+// the environment in the sources provided. See VarSet.SetSources.
+func SetSources(sources ...Source) {
+	osVarSet.SetSources(sources...)
+}
+
+// matchName returns the value named name in values, comparing names exactly
+// and then case-insensitively. If more than one name matches
+// case-insensitively, it returns an error.
+func matchName(values map[string]string, name string) (string, bool, error) {
+	if value, ok := values[name]; ok {
+		return value, true, nil
+	}
+
+	var matches []string
+	for n := range values {
+		if strings.EqualFold(n, name) {
+			matches = append(matches, n)
+		}
+	}
+	switch len(matches) {
+	case 0:
+		return "", false, nil
+	case 1:
+		return values[matches[0]], true, nil
+	default:
+		sort.Strings(matches)
+		return "", false, fmt.Errorf("names %s match %s", strings.Join(matches, ", "), name)
+	}
+}
+
+// diffValues returns the changes that turn old into new, sorted by name.
+func diffValues(old, new map[string]string) []Change {
+	var changes []Change
+	for name, value := range new {
+		if prev, ok := old[name]; !ok || prev != value {
+			changes = append(changes, Change{Name: name, Value: value})
+		}
+	}
+	for name := range old {
+		if _, ok := new[name]; !ok {
+			changes = append(changes, Change{Name: name, Deleted: true})
+		}
+	}
+	sort.Slice(changes, func(i, j int) bool {
+		return changes[i].Name < changes[j].Name
+	})
+
+	return changes
+}
+
+// retryDelay returns the delay before retrying a request that failed after a
+// delay of d, doubling it up to a minute.
+func retryDelay(d time.Duration) time.Duration {
+	switch {
+	case d < minRetryDelay:
+		return minRetryDelay
+	case d*2 > maxRetryDelay:
+		return maxRetryDelay
+	default:
+		return d * 2
+	}
+}
+
+// sleep waits for d, and reports whether it did so before ctx was done.
+func sleep(ctx context.Context, d time.Duration) bool {
+	t := time.NewTimer(d)
+	defer t.Stop()
+
+	select {
+	case <-t.C:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
@@ -0,0 +1,168 @@
+package env_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/christgf/env"
+)
+
+// mapSource is a Source backed by a map.
+type mapSource map[string]string
+
+func (m mapSource) Lookup(name string) (string, bool, error) {
+	if name == "ENV_TEST_BROKEN" {
+		return "", false, errors.New("broken")
+	}
+	value, ok := m[name]
+	return value, ok, nil
+}
+
+func (m mapSource) String() string {
+	return "map"
+}
+
+// secretSource is a mapSource that keeps secrets.
+type secretSource struct {
+	mapSource
+}
+
+func (s secretSource) Sensitive() bool {
+	return true
+}
+
+// checkRedacted checks that key was marked as sensitive by vs, and that Dump
+// does not include secret.
+func checkRedacted(t *testing.T, vs *env.VarSet, key, secret string) {
+	t.Helper()
+
+	if !vs.IsSensitive(key) {
+		t.Errorf("IsSensitive(%q): got false, want true", key)
+	}
+	var b bytes.Buffer
+	if err := vs.Dump(&b, env.DumpText); err != nil {
+		t.Fatalf("Dump(): %v", err)
+	}
+	if got := b.String(); strings.Contains(got, secret) || !strings.Contains(got, key+`="[REDACTED]"`) {
+		t.Errorf("Dump(): got %q, want the value of %s redacted", got, key)
+	}
+}
+
+// newHangingServer returns a server that never responds, until the test ends.
+func newHangingServer(t *testing.T) *httptest.Server {
+	t.Helper()
+
+	done := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-done:
+		}
+	}))
+	t.Cleanup(func() {
+		close(done)
+		srv.Close()
+	})
+
+	return srv
+}
+
+// checkTimeout checks that a lookup from src, which sends requests to a server
+// that never responds, fails once the request times out.
+func checkTimeout(t *testing.T, src env.Source) {
+	t.Helper()
+
+	start := time.Now()
+	value, ok, err := src.Lookup("DB_PASSWORD")
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Lookup(%q): got %q, %v, %v, want %v", "DB_PASSWORD", value, ok, err, context.DeadlineExceeded)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("Lookup(%q): took %v", "DB_PASSWORD", elapsed)
+	}
+}
+
+func TestVarSet_SetSources(t *testing.T) {
+	t.Setenv("ENV_TEST_PORT", "9000")
+
+	var vs env.VarSet
+	vs.SetPrefix("ENV_TEST_")
+	vs.SetSources(
+		mapSource{"ENV_TEST_PORT": "8000", "ENV_TEST_WORKERS": "4"},
+		mapSource{"ENV_TEST_WORKERS": "8", "ENV_TEST_HOST": "localhost"},
+	)
+
+	if got, want := vs.Int("PORT", 0), 9000; got != want {
+		t.Errorf("Int(%q): got %d, want %d", "PORT", got, want)
+	}
+	if got, want := vs.Int("WORKERS", 0), 4; got != want {
+		t.Errorf("Int(%q): got %d, want %d", "WORKERS", got, want)
+	}
+	if got, want := vs.String("HOST", ""), "localhost"; got != want {
+		t.Errorf("String(%q): got %q, want %q", "HOST", got, want)
+	}
+	if got, want := vs.String("MISSING", "fallback"), "fallback"; got != want {
+		t.Errorf("String(%q): got %q, want %q", "MISSING", got, want)
+	}
+	if got, want := vs.String("BROKEN", "fallback"), "fallback"; got != want {
+		t.Errorf("String(%q): got %q, want %q", "BROKEN", got, want)
+	}
+
+	origins := make(map[string]string)
+	for _, r := range vs.Records() {
+		origins[r.Key] = r.Origin
+		if r.Key == "BROKEN" && (r.Err == nil || r.Err.Error() != "env: ENV_TEST_BROKEN: map: broken") {
+			t.Errorf("Records(): got error %v for %q", r.Err, r.Key)
+		}
+	}
+	want := map[string]string{"PORT": "env", "WORKERS": "map", "HOST": "map", "MISSING": "default", "BROKEN": "default"}
+	for key, origin := range want {
+		if origins[key] != origin {
+			t.Errorf("Records(): got origin %q for %q, want %q", origins[key], key, origin)
+		}
+	}
+
+	vs.SetSources()
+	if got, want := vs.String("HOST", "fallback"), "fallback"; got != want {
+		t.Errorf("String(%q): got %q, want %q", "HOST", got, want)
+	}
+}
+
+func TestVarSet_SetSources_expand(t *testing.T) {
+	t.Setenv("ENV_TEST_DB_HOST", "db.internal")
+
+	var vs env.VarSet
+	vs.SetPrefix("ENV_TEST_")
+	vs.SetExpand(true)
+	vs.SetSources(mapSource{"ENV_TEST_DB_URL": "postgres://${DB_HOST}/${DB_NAME}", "ENV_TEST_DB_NAME": "app"})
+
+	if got, want := vs.String("DB_URL", ""), "postgres://db.internal/app"; got != want {
+		t.Errorf("String(%q): got %q, want %q", "DB_URL", got, want)
+	}
+}
+
+func TestVarSet_SetSources_sensitive(t *testing.T) {
+	var vs env.VarSet
+	vs.SetPrefix("ENV_TEST_")
+	vs.SetSources(
+		mapSource{"ENV_TEST_PORT": "9000"},
+		secretSource{mapSource{"ENV_TEST_DB_PASSWORD": "hunter2"}},
+	)
+
+	if got, want := vs.String("DB_PASSWORD", ""), "hunter2"; got != want {
+		t.Errorf("String(%q): got %q, want %q", "DB_PASSWORD", got, want)
+	}
+	if got, want := vs.Int("PORT", 0), 9000; got != want {
+		t.Errorf("Int(%q): got %d, want %d", "PORT", got, want)
+	}
+	checkRedacted(t, &vs, "DB_PASSWORD", "hunter2")
+	if vs.IsSensitive("PORT") {
+		t.Errorf("IsSensitive(%q): got true, want false", "PORT")
+	}
+}
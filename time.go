@@ -0,0 +1,95 @@
+package env
+
+import (
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Layouts understood by ParseTime, Time and TimeVar in addition to the layouts
+// of the time package.
+const (
+	// UnixSeconds parses values as the number of seconds since the Unix epoch.
+	UnixSeconds = "unix"
+	// UnixMillis parses values as the number of milliseconds since the Unix
+	// epoch.
+	UnixMillis = "unixmilli"
+)
+
+// ParseTime parses value as a time formatted according to layout. The layout is
+// either a layout understood by time.Parse, such as time.RFC3339 or
+// time.DateOnly, or one of UnixSeconds and UnixMillis, in which case value is an
+// integer number of seconds or milliseconds since the Unix epoch and the result
+// is in UTC. An empty layout is the same as time.RFC3339.
+func ParseTime(layout, value string) (time.Time, error) {
+	switch layout {
+	case "":
+		return time.Parse(time.RFC3339, value)
+	case UnixSeconds:
+		n, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return time.Time{}, err
+		}
+		return time.Unix(n, 0).UTC(), nil
+	case UnixMillis:
+		n, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return time.Time{}, err
+		}
+		return time.UnixMilli(n).UTC(), nil
+	default:
+		return time.Parse(layout, value)
+	}
+}
+
+// Time retrieves the value of the environment variable named by the key, parses
+// the value as a time formatted according to layout, and returns the result.
+// See ParseTime for the accepted layouts. If the variable is not present or its
+// value cannot be parsed, fallback is returned.
+func (vs *VarSet) Time(key, layout string, fallback time.Time) time.Time {
+	return get(vs, key, fallback, func(s string) (time.Time, error) {
+		return ParseTime(layout, s)
+	})
+}
+
+// Location retrieves the value of the environment variable named by the key,
+// loads the time zone it names, such as "Europe/Athens" or "UTC", and returns
+// the result. As with the TZ environment variable, the name may be preceded by
+// a colon, and an empty name denotes UTC. If the variable is not present or the
+// time zone cannot be loaded, fallback is returned.
+func (vs *VarSet) Location(key string, fallback *time.Location) *time.Location {
+	return get(vs, key, fallback, func(s string) (*time.Location, error) {
+		return time.LoadLocation(strings.TrimPrefix(s, ":"))
+	})
+}
+
+// Time retrieves the value of the environment variable named by the key, parses
+// the value as a time formatted according to layout, and returns the result.
+// See ParseTime for the accepted layouts. If the variable is not present or its
+// value cannot be parsed, fallback is returned.
+func Time(key, layout string, fallback time.Time) time.Time {
+	return osVarSet.Time(key, layout, fallback)
+}
+
+// Location retrieves the value of the environment variable named by the key,
+// loads the time zone it names, such as "Europe/Athens" or "UTC", and returns
+// the result. As with the TZ environment variable, the name may be preceded by
+// a colon, and an empty name denotes UTC. If the variable is not present or the
+// time zone cannot be loaded, fallback is returned.
+func Location(key string, fallback *time.Location) *time.Location {
+	return osVarSet.Location(key, fallback)
+}
+
+// TimeVar retrieves the value of the environment variable named by the key,
+// parses the value as a time formatted according to layout, and stores the
+// result into the variable pointed by p.
+func TimeVar(p *time.Time, key, layout string, fallback time.Time) {
+	*p = osVarSet.Time(key, layout, fallback)
+}
+
+// LocationVar retrieves the value of the environment variable named by the key,
+// loads the time zone it names, and stores the result into the variable pointed
+// by p.
+func LocationVar(p **time.Location, key string, fallback *time.Location) {
+	*p = osVarSet.Location(key, fallback)
+}
@@ -0,0 +1,157 @@
+package env_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/christgf/env"
+)
+
+func TestTime(t *testing.T) {
+	const envKey = "ENV_TEST_TIME"
+
+	fallback := time.Date(2020, time.January, 1, 0, 0, 0, 0, time.UTC)
+	if got := env.Time(envKey, "", fallback); !got.Equal(fallback) {
+		t.Errorf("Time(%q): got %v, want %v", envKey, got, fallback)
+	}
+
+	tests := []struct {
+		name      string
+		envValue  string
+		layout    string
+		wantValue time.Time
+	}{
+		{
+			name:      "value is empty",
+			envValue:  "",
+			wantValue: fallback,
+		},
+		{
+			name:      "value is RFC 3339",
+			envValue:  "2024-03-01T02:30:00+02:00",
+			wantValue: time.Date(2024, time.March, 1, 0, 30, 0, 0, time.UTC),
+		},
+		{
+			name:      "value is RFC 3339 with fraction",
+			envValue:  "2024-03-01T00:30:00.25Z",
+			wantValue: time.Date(2024, time.March, 1, 0, 30, 0, 250e6, time.UTC),
+		},
+		{
+			name:      "value is custom layout",
+			envValue:  "2024-03-01",
+			layout:    "2006-01-02",
+			wantValue: time.Date(2024, time.March, 1, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			name:      "value does not match layout",
+			envValue:  "2024-03-01",
+			wantValue: fallback,
+		},
+		{
+			name:      "value is Unix seconds",
+			envValue:  "1709253000",
+			layout:    env.UnixSeconds,
+			wantValue: time.Date(2024, time.March, 1, 0, 30, 0, 0, time.UTC),
+		},
+		{
+			name:      "value is Unix milliseconds",
+			envValue:  "1709253000250",
+			layout:    env.UnixMillis,
+			wantValue: time.Date(2024, time.March, 1, 0, 30, 0, 250e6, time.UTC),
+		},
+		{
+			name:      "value is not Unix seconds",
+			envValue:  "2024-03-01",
+			layout:    env.UnixSeconds,
+			wantValue: fallback,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv(envKey, tt.envValue)
+			if got, want := env.Time(envKey, tt.layout, fallback), tt.wantValue; !got.Equal(want) {
+				t.Errorf("Time(%q): got %v, want %v", envKey, got, want)
+			}
+
+			var p time.Time
+			env.TimeVar(&p, envKey, tt.layout, fallback)
+			if got, want := p, tt.wantValue; !got.Equal(want) {
+				t.Errorf("TimeVar(%q): got %v, want %v", envKey, got, want)
+			}
+
+			prefix, key := "ENV_", "TEST_TIME"
+			env.SetPrefix(prefix)
+			if got, want := env.Time(key, tt.layout, fallback), tt.wantValue; !got.Equal(want) {
+				t.Errorf("Time(Prefix=%q, Key=%q): got %v, want %v", prefix, key, got, want)
+			}
+
+			env.SetPrefix("")
+			if got, want := env.Time(key, tt.layout, fallback), fallback; !got.Equal(want) {
+				t.Errorf("Time(Prefix=%q, Key=%q): got %v, want %v", prefix, key, got, want)
+			}
+		})
+	}
+}
+
+func TestLocation(t *testing.T) {
+	const envKey = "ENV_TEST_LOCATION"
+
+	athens, err := time.LoadLocation("Europe/Athens")
+	if err != nil {
+		t.Skipf("time zone database not available: %v", err)
+	}
+
+	fallback := time.Local
+	tests := []struct {
+		name     string
+		envValue string
+		wantName string
+	}{
+		{
+			name:     "value is empty",
+			envValue: "",
+			wantName: "UTC",
+		},
+		{
+			name:     "value is UTC",
+			envValue: "UTC",
+			wantName: "UTC",
+		},
+		{
+			name:     "value is Europe/Athens",
+			envValue: "Europe/Athens",
+			wantName: athens.String(),
+		},
+		{
+			name:     "value is TZ style",
+			envValue: ":Europe/Athens",
+			wantName: athens.String(),
+		},
+		{
+			name:     "value is unknown",
+			envValue: "Mars/Olympus_Mons",
+			wantName: fallback.String(),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv(envKey, tt.envValue)
+			if got, want := env.Location(envKey, fallback).String(), tt.wantName; got != want {
+				t.Errorf("Location(%q): got %q, want %q", envKey, got, want)
+			}
+
+			var p *time.Location
+			env.LocationVar(&p, envKey, fallback)
+			if got, want := p.String(), tt.wantName; got != want {
+				t.Errorf("LocationVar(%q): got %q, want %q", envKey, got, want)
+			}
+
+			prefix, key := "ENV_", "TEST_LOCATION"
+			env.SetPrefix(prefix)
+			if got, want := env.Location(key, fallback).String(), tt.wantName; got != want {
+				t.Errorf("Location(Prefix=%q, Key=%q): got %q, want %q", prefix, key, got, want)
+			}
+			env.SetPrefix("")
+		})
+	}
+}
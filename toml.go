@@ -0,0 +1,735 @@
+package env
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// tomlTable is a table of a TOML document, along with how it was defined, so
+// that tables and keys are not defined twice.
+type tomlTable struct {
+	values map[string]any // *tomlTable, *tomlTableArray, []any or string
+	// defined reports whether the table was defined by a header.
+	defined bool
+	// dotted reports whether the table was defined by dotted keys.
+	dotted bool
+	// inline reports whether the table is an inline table, or was defined by
+	// dotted keys within one. Inline tables cannot be extended once closed;
+	// the tables defined by dotted keys within them are reached only through
+	// them.
+	inline bool
+}
+
+// tomlTableArray is an array of tables, defined by [[headers]].
+type tomlTableArray struct {
+	tables []*tomlTable
+}
+
+// tomlParser parses a TOML document into a tree for flatten. Strings, numbers,
+// booleans and dates are kept as text; integers are converted to decimal.
+type tomlParser struct {
+	s    string
+	pos  int
+	line int
+}
+
+// newTOMLTable returns an empty table.
+func newTOMLTable() *tomlTable {
+	return &tomlTable{values: make(map[string]any)}
+}
+
+// parseTOML parses a TOML document.
+func parseTOML(b []byte) (map[string]any, error) {
+	if !utf8.Valid(b) {
+		return nil, errors.New("document is not valid UTF-8")
+	}
+	p := &tomlParser{s: string(b), line: 1}
+	root := newTOMLTable()
+	current := root
+
+	for {
+		p.skipBlank()
+		if p.pos == len(p.s) {
+			break
+		}
+
+		var err error
+		switch {
+		case strings.HasPrefix(p.s[p.pos:], "[["):
+			p.pos += 2
+			current, err = p.tableArrayHeader(root)
+		case p.s[p.pos] == '[':
+			p.pos++
+			current, err = p.tableHeader(root)
+		default:
+			err = p.keyValue(current)
+		}
+		if err == nil {
+			err = p.endOfLine()
+		}
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", p.line, err)
+		}
+	}
+
+	return tomlTree(root).(map[string]any), nil
+}
+
+// tomlTree converts a parsed value to a tree for flatten.
+func tomlTree(v any) any {
+	switch v := v.(type) {
+	case *tomlTable:
+		m := make(map[string]any, len(v.values))
+		for key, elem := range v.values {
+			m[key] = tomlTree(elem)
+		}
+		return m
+	case *tomlTableArray:
+		s := make([]any, len(v.tables))
+		for i, t := range v.tables {
+			s[i] = tomlTree(t)
+		}
+		return s
+	case []any:
+		s := make([]any, len(v))
+		for i, elem := range v {
+			s[i] = tomlTree(elem)
+		}
+		return s
+	default:
+		return v
+	}
+}
+
+// skipBlank skips white space, newlines and comments.
+func (p *tomlParser) skipBlank() {
+	for p.pos < len(p.s) {
+		switch p.s[p.pos] {
+		case ' ', '\t', '\r':
+			p.pos++
+		case '\n':
+			p.pos++
+			p.line++
+		case '#':
+			p.skipComment()
+		default:
+			return
+		}
+	}
+}
+
+// skipSpace skips spaces and tabs.
+func (p *tomlParser) skipSpace() {
+	for p.pos < len(p.s) && (p.s[p.pos] == ' ' || p.s[p.pos] == '\t') {
+		p.pos++
+	}
+}
+
+// skipComment skips a comment, up to the end of the line.
+func (p *tomlParser) skipComment() {
+	for p.pos < len(p.s) && p.s[p.pos] != '\n' {
+		p.pos++
+	}
+}
+
+// endOfLine expects the end of a line, after optional white space and a
+// comment.
+func (p *tomlParser) endOfLine() error {
+	p.skipSpace()
+	if p.pos < len(p.s) && p.s[p.pos] == '#' {
+		p.skipComment()
+	}
+	switch {
+	case p.pos == len(p.s):
+		return nil
+	case p.s[p.pos] == '\n':
+		p.pos++
+		p.line++
+		return nil
+	case strings.HasPrefix(p.s[p.pos:], "\r\n"):
+		p.pos += 2
+		p.line++
+		return nil
+	default:
+		return fmt.Errorf("unexpected %q", p.rest())
+	}
+}
+
+// rest returns the rest of the current line, for errors.
+func (p *tomlParser) rest() string {
+	s := p.s[p.pos:]
+	if i := strings.IndexByte(s, '\n'); i >= 0 {
+		s = s[:i]
+	}
+
+	return strings.TrimSpace(s)
+}
+
+// key parses a key, which may be dotted, and returns its parts.
+func (p *tomlParser) key() ([]string, error) {
+	var parts []string
+	for {
+		p.skipSpace()
+		if p.pos == len(p.s) {
+			return nil, errors.New("expected a key")
+		}
+
+		var part string
+		switch c := p.s[p.pos]; {
+		case c == '"':
+			s, err := p.basicString()
+			if err != nil {
+				return nil, err
+			}
+			part = s
+		case c == '\'':
+			s, err := p.literalString()
+			if err != nil {
+				return nil, err
+			}
+			part = s
+		default:
+			start := p.pos
+			for p.pos < len(p.s) && isTOMLBareKey(p.s[p.pos]) {
+				p.pos++
+			}
+			if p.pos == start {
+				return nil, fmt.Errorf("invalid key at %q", p.rest())
+			}
+			part = p.s[start:p.pos]
+		}
+		parts = append(parts, part)
+
+		p.skipSpace()
+		if p.pos == len(p.s) || p.s[p.pos] != '.' {
+			return parts, nil
+		}
+		p.pos++
+	}
+}
+
+// isTOMLBareKey reports whether c may appear in a bare key.
+func isTOMLBareKey(c byte) bool {
+	return 'A' <= c && c <= 'Z' || 'a' <= c && c <= 'z' || '0' <= c && c <= '9' || c == '_' || c == '-'
+}
+
+// tableHeader parses the header of a table, after "[", and returns the table.
+func (p *tomlParser) tableHeader(root *tomlTable) (*tomlTable, error) {
+	parts, err := p.key()
+	if err != nil {
+		return nil, err
+	}
+	if p.pos == len(p.s) || p.s[p.pos] != ']' {
+		return nil, errors.New("expected ']' after table name")
+	}
+	p.pos++
+
+	parent, err := p.walk(root, parts[:len(parts)-1])
+	if err != nil {
+		return nil, err
+	}
+	name := strings.Join(parts, ".")
+	last := parts[len(parts)-1]
+	switch v := parent.values[last].(type) {
+	case nil:
+		t := newTOMLTable()
+		t.defined = true
+		parent.values[last] = t
+		return t, nil
+	case *tomlTable:
+		if v.defined || v.dotted || v.inline {
+			return nil, fmt.Errorf("table %s is defined twice", name)
+		}
+		v.defined = true
+		return v, nil
+	case *tomlTableArray:
+		return nil, fmt.Errorf("key %s is already defined as an array of tables", name)
+	default:
+		return nil, fmt.Errorf("key %s is already defined as a value", name)
+	}
+}
+
+// tableArrayHeader parses the header of an array of tables, after "[[", and
+// returns the table it adds to the array.
+func (p *tomlParser) tableArrayHeader(root *tomlTable) (*tomlTable, error) {
+	parts, err := p.key()
+	if err != nil {
+		return nil, err
+	}
+	if !strings.HasPrefix(p.s[p.pos:], "]]") {
+		return nil, errors.New("expected ']]' after array of tables name")
+	}
+	p.pos += 2
+
+	parent, err := p.walk(root, parts[:len(parts)-1])
+	if err != nil {
+		return nil, err
+	}
+	name := strings.Join(parts, ".")
+	last := parts[len(parts)-1]
+	t := newTOMLTable()
+	t.defined = true
+	switch v := parent.values[last].(type) {
+	case nil:
+		parent.values[last] = &tomlTableArray{tables: []*tomlTable{t}}
+	case *tomlTableArray:
+		v.tables = append(v.tables, t)
+	case *tomlTable:
+		return nil, fmt.Errorf("key %s is already defined as a table", name)
+	default:
+		return nil, fmt.Errorf("key %s is already defined as a value", name)
+	}
+
+	return t, nil
+}
+
+// walk returns the table named by the parts of a header, relative to t,
+// creating tables that do not exist. Arrays of tables resolve to their last
+// table.
+func (p *tomlParser) walk(t *tomlTable, parts []string) (*tomlTable, error) {
+	for i, part := range parts {
+		switch v := t.values[part].(type) {
+		case nil:
+			child := newTOMLTable()
+			t.values[part] = child
+			t = child
+		case *tomlTable:
+			if v.inline {
+				return nil, fmt.Errorf("inline table %s cannot be extended", strings.Join(parts[:i+1], "."))
+			}
+			t = v
+		case *tomlTableArray:
+			t = v.tables[len(v.tables)-1]
+		default:
+			return nil, fmt.Errorf("key %s is already defined as a value", strings.Join(parts[:i+1], "."))
+		}
+	}
+
+	return t, nil
+}
+
+// keyValue parses a key/value pair, and adds it to t.
+func (p *tomlParser) keyValue(t *tomlTable) error {
+	parts, err := p.key()
+	if err != nil {
+		return err
+	}
+	if p.pos == len(p.s) || p.s[p.pos] != '=' {
+		return fmt.Errorf("expected '=' after key %s", strings.Join(parts, "."))
+	}
+	p.pos++
+	p.skipSpace()
+
+	// Dotted keys define tables, which cannot be defined again by headers.
+	for i, part := range parts[:len(parts)-1] {
+		switch v := t.values[part].(type) {
+		case nil:
+			child := newTOMLTable()
+			child.dotted = true
+			child.inline = t.inline
+			t.values[part] = child
+			t = child
+		case *tomlTable:
+			// Tables defined by dotted keys within the inline table being
+			// parsed may be extended by its other keys.
+			if v.inline && !v.dotted {
+				return fmt.Errorf("inline table %s cannot be extended", strings.Join(parts[:i+1], "."))
+			}
+			if v.defined {
+				return fmt.Errorf("table %s is already defined", strings.Join(parts[:i+1], "."))
+			}
+			t = v
+		case *tomlTableArray:
+			return fmt.Errorf("key %s is already defined as an array of tables", strings.Join(parts[:i+1], "."))
+		default:
+			return fmt.Errorf("key %s is already defined as a value", strings.Join(parts[:i+1], "."))
+		}
+	}
+
+	name := strings.Join(parts, ".")
+	last := parts[len(parts)-1]
+	switch t.values[last].(type) {
+	case nil:
+	case *tomlTable, *tomlTableArray:
+		return fmt.Errorf("key %s is already defined as a table", name)
+	default:
+		return fmt.Errorf("key %s is defined twice", name)
+	}
+
+	v, err := p.value()
+	if err != nil {
+		return fmt.Errorf("key %s: %w", name, err)
+	}
+	t.values[last] = v
+
+	return nil
+}
+
+// value parses a value.
+func (p *tomlParser) value() (any, error) {
+	if p.pos == len(p.s) {
+		return nil, errors.New("expected a value")
+	}
+
+	switch c := p.s[p.pos]; {
+	case strings.HasPrefix(p.s[p.pos:], `"""`):
+		return p.multilineBasicString()
+	case strings.HasPrefix(p.s[p.pos:], "'''"):
+		return p.multilineLiteralString()
+	case c == '"':
+		return p.basicString()
+	case c == '\'':
+		return p.literalString()
+	case c == '[':
+		return p.array()
+	case c == '{':
+		return p.inlineTable()
+	default:
+		return p.scalar()
+	}
+}
+
+// array parses an array, which may span lines.
+func (p *tomlParser) array() ([]any, error) {
+	p.pos++
+	s := []any{}
+	for {
+		p.skipBlank()
+		if p.pos == len(p.s) {
+			return nil, errors.New("unterminated array")
+		}
+		if p.s[p.pos] == ']' {
+			p.pos++
+			return s, nil
+		}
+
+		v, err := p.value()
+		if err != nil {
+			return nil, err
+		}
+		s = append(s, v)
+
+		p.skipBlank()
+		switch {
+		case p.pos < len(p.s) && p.s[p.pos] == ',':
+			p.pos++
+		case p.pos < len(p.s) && p.s[p.pos] == ']':
+		default:
+			return nil, errors.New("expected ',' or ']' in array")
+		}
+	}
+}
+
+// inlineTable parses an inline table, which must fit on a line.
+func (p *tomlParser) inlineTable() (*tomlTable, error) {
+	p.pos++
+	t := newTOMLTable()
+	t.inline = true
+	for first := true; ; first = false {
+		p.skipSpace()
+		if p.pos == len(p.s) || p.s[p.pos] == '\n' {
+			return nil, errors.New("unterminated inline table")
+		}
+		if p.s[p.pos] == '}' && first {
+			p.pos++
+			break
+		}
+
+		if err := p.keyValue(t); err != nil {
+			return nil, err
+		}
+
+		p.skipSpace()
+		if p.pos < len(p.s) && p.s[p.pos] == ',' {
+			p.pos++
+			continue
+		}
+		if p.pos < len(p.s) && p.s[p.pos] == '}' {
+			p.pos++
+			break
+		}
+		return nil, errors.New("expected ',' or '}' in inline table")
+	}
+
+	return t, nil
+}
+
+// basicString parses a basic string, in double quotes.
+func (p *tomlParser) basicString() (string, error) {
+	p.pos++
+	var b strings.Builder
+	for p.pos < len(p.s) {
+		c := p.s[p.pos]
+		switch {
+		case c == '"':
+			p.pos++
+			return b.String(), nil
+		case c == '\n':
+			return "", errors.New("unterminated string")
+		case c == '\\':
+			r, err := p.escape()
+			if err != nil {
+				return "", err
+			}
+			b.WriteRune(r)
+		default:
+			b.WriteByte(c)
+			p.pos++
+		}
+	}
+
+	return "", errors.New("unterminated string")
+}
+
+// multilineBasicString parses a multi-line basic string, in triple double
+// quotes. A newline that immediately follows the opening delimiter is trimmed,
+// and a backslash at the end of a line trims the white space that follows it.
+func (p *tomlParser) multilineBasicString() (string, error) {
+	p.pos += 3
+	p.skipFirstNewline()
+
+	var b strings.Builder
+	for p.pos < len(p.s) {
+		if strings.HasPrefix(p.s[p.pos:], `"""`) {
+			// Up to two quotes may precede the closing delimiter.
+			n := 3
+			for n < 5 && p.pos+n < len(p.s) && p.s[p.pos+n] == '"' {
+				n++
+			}
+			b.WriteString(strings.Repeat(`"`, n-3))
+			p.pos += n
+			return b.String(), nil
+		}
+
+		c := p.s[p.pos]
+		switch {
+		case c == '\\' && p.lineEndingBackslash():
+			p.pos++
+			for p.pos < len(p.s) && strings.IndexByte(" \t\r\n", p.s[p.pos]) >= 0 {
+				if p.s[p.pos] == '\n' {
+					p.line++
+				}
+				p.pos++
+			}
+		case c == '\\':
+			r, err := p.escape()
+			if err != nil {
+				return "", err
+			}
+			b.WriteRune(r)
+		default:
+			if c == '\n' {
+				p.line++
+			}
+			b.WriteByte(c)
+			p.pos++
+		}
+	}
+
+	return "", errors.New("unterminated multi-line string")
+}
+
+// lineEndingBackslash reports whether the backslash at the current position is
+// followed only by white space up to the end of the line.
+func (p *tomlParser) lineEndingBackslash() bool {
+	for i := p.pos + 1; i < len(p.s); i++ {
+		switch p.s[i] {
+		case ' ', '\t', '\r':
+		case '\n':
+			return true
+		default:
+			return false
+		}
+	}
+
+	return false
+}
+
+// literalString parses a literal string, in single quotes.
+func (p *tomlParser) literalString() (string, error) {
+	p.pos++
+	start := p.pos
+	for p.pos < len(p.s) {
+		switch p.s[p.pos] {
+		case '\'':
+			s := p.s[start:p.pos]
+			p.pos++
+			return s, nil
+		case '\n':
+			return "", errors.New("unterminated string")
+		}
+		p.pos++
+	}
+
+	return "", errors.New("unterminated string")
+}
+
+// multilineLiteralString parses a multi-line literal string, in triple single
+// quotes.
+func (p *tomlParser) multilineLiteralString() (string, error) {
+	p.pos += 3
+	p.skipFirstNewline()
+
+	i := strings.Index(p.s[p.pos:], "'''")
+	if i < 0 {
+		return "", errors.New("unterminated multi-line string")
+	}
+	// Up to two quotes may precede the closing delimiter.
+	end := p.pos + i + 3
+	for end < len(p.s) && end-(p.pos+i) < 5 && p.s[end] == '\'' {
+		end++
+	}
+	s := p.s[p.pos : end-3]
+	p.line += strings.Count(s, "\n")
+	p.pos = end
+
+	return s, nil
+}
+
+// skipFirstNewline skips a newline that immediately follows the opening
+// delimiter of a multi-line string.
+func (p *tomlParser) skipFirstNewline() {
+	switch {
+	case strings.HasPrefix(p.s[p.pos:], "\n"):
+		p.pos++
+		p.line++
+	case strings.HasPrefix(p.s[p.pos:], "\r\n"):
+		p.pos += 2
+		p.line++
+	}
+}
+
+// escape decodes the escape sequence at the current position.
+func (p *tomlParser) escape() (rune, error) {
+	p.pos++
+	if p.pos == len(p.s) {
+		return 0, errors.New("unterminated string")
+	}
+
+	c := p.s[p.pos]
+	p.pos++
+	switch c {
+	case 'b':
+		return '\b', nil
+	case 't':
+		return '\t', nil
+	case 'n':
+		return '\n', nil
+	case 'f':
+		return '\f', nil
+	case 'r':
+		return '\r', nil
+	case 'e':
+		return 0x1b, nil
+	case '"', '\\':
+		return rune(c), nil
+	case 'u', 'U':
+		n := 4
+		if c == 'U' {
+			n = 8
+		}
+		r, _, err := hexRune(p.s[p.pos-1:], n)
+		if err != nil {
+			return 0, err
+		}
+		p.pos += n
+		return r, nil
+	default:
+		return 0, fmt.Errorf("invalid escape sequence \\%c", c)
+	}
+}
+
+// scalar parses a boolean, a number or a date.
+func (p *tomlParser) scalar() (string, error) {
+	start := p.pos
+	for p.pos < len(p.s) && isTOMLScalar(p.s[p.pos]) {
+		p.pos++
+	}
+	// A space may separate the date and the time of a date-time.
+	if p.pos-start == 10 && p.s[start+4] == '-' && p.pos+3 < len(p.s) && p.s[p.pos] == ' ' && isDigit(p.s[p.pos+1]) && isDigit(p.s[p.pos+2]) && p.s[p.pos+3] == ':' {
+		p.pos++
+		for p.pos < len(p.s) && isTOMLScalar(p.s[p.pos]) {
+			p.pos++
+		}
+	}
+	s := p.s[start:p.pos]
+
+	switch {
+	case len(s) == 0:
+		return "", fmt.Errorf("invalid value %q", p.rest())
+	case s == "true" || s == "false":
+		return s, nil
+	case isTOMLDate(s):
+		return s, nil
+	}
+
+	if strings.Contains(s, "__") || strings.HasPrefix(s, "_") || strings.HasSuffix(s, "_") {
+		return "", fmt.Errorf("invalid number %q", s)
+	}
+	num := strings.ReplaceAll(s, "_", "")
+	unsigned := strings.TrimLeft(num, "+-")
+	if strings.HasPrefix(unsigned, "0x") || strings.HasPrefix(unsigned, "0o") || strings.HasPrefix(unsigned, "0b") {
+		// Signs are not allowed, the prefix must be followed by digits, and
+		// underscores must be between digits.
+		if unsigned != num || len(s) == 2 || s[:2] != num[:2] || s[2] == '_' {
+			return "", fmt.Errorf("invalid number %q", s)
+		}
+		n, err := strconv.ParseInt(num, 0, 64)
+		if err != nil {
+			return "", fmt.Errorf("invalid number %q", s)
+		}
+		return strconv.FormatInt(n, 10), nil
+	}
+	if len(unsigned) > 1 && unsigned[0] == '0' && isDigit(unsigned[1]) {
+		return "", fmt.Errorf("invalid number %q: leading zeros are not allowed", s)
+	}
+	// Only floats have a fraction, an exponent or a special value, so integers
+	// out of the range of int64 are not taken for floats.
+	if !strings.ContainsAny(unsigned, ".eE") && unsigned != "inf" && unsigned != "nan" {
+		if _, err := strconv.ParseInt(num, 10, 64); err != nil {
+			if errors.Is(err, strconv.ErrRange) {
+				return "", fmt.Errorf("invalid number %q: out of range", s)
+			}
+			return "", fmt.Errorf("invalid value %q", s)
+		}
+		return strings.TrimPrefix(num, "+"), nil
+	}
+	switch unsigned {
+	case "inf", "nan":
+		return num, nil
+	}
+	if i := strings.IndexByte(unsigned, '.'); i >= 0 && (i == 0 || i == len(unsigned)-1 || !isDigit(unsigned[i-1]) || !isDigit(unsigned[i+1])) {
+		return "", fmt.Errorf("invalid number %q", s)
+	}
+	if _, err := strconv.ParseFloat(num, 64); err == nil && isDigit(unsigned[0]) && !strings.ContainsAny(unsigned, "xXpP") {
+		return strings.TrimPrefix(num, "+"), nil
+	}
+
+	return "", fmt.Errorf("invalid value %q", s)
+}
+
+// isTOMLScalar reports whether c may appear in a boolean, a number or a date.
+func isTOMLScalar(c byte) bool {
+	return isTOMLBareKey(c) || c == '+' || c == '.' || c == ':'
+}
+
+// isTOMLDate reports whether s is a date, a time or a date-time, such as
+// "1979-05-27", "07:32:00" or "1979-05-27T07:32:00Z".
+func isTOMLDate(s string) bool {
+	switch {
+	case len(s) >= 10 && isDigit(s[0]) && s[4] == '-' && s[7] == '-':
+		return true
+	case len(s) >= 8 && isDigit(s[0]) && s[2] == ':' && s[5] == ':':
+		return true
+	default:
+		return false
+	}
+}
+
+// isDigit reports whether c is a decimal digit.
+func isDigit(c byte) bool {
+	return '0' <= c && c <= '9'
+}
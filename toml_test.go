@@ -0,0 +1,171 @@
+package env_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/christgf/env"
+)
+
+func TestTOMLFileSource(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    map[string]string
+		absent  []string
+	}{
+		{
+			name: "tables",
+			content: `# Service configuration
+port = 9000
+debug = true
+
+[db]
+host = "db.internal" # primary
+pool.max = 10
+pool.maxIdleConns = 2
+
+[db.replica]
+"host name" = 'replica.internal'
+`,
+			want: map[string]string{
+				"PORT":                   "9000",
+				"DEBUG":                  "true",
+				"DB_HOST":                "db.internal",
+				"DB_POOL_MAX":            "10",
+				"DB_POOL_MAX_IDLE_CONNS": "2",
+				"DB_REPLICA_HOST NAME":   "replica.internal",
+			},
+			absent: []string{"DB", "DB_POOL"},
+		},
+		{
+			name: "arrays",
+			content: `hosts = ["a.internal", "b.internal",]
+matrix = [
+  [1, 2], # first row
+  [3, 4],
+]
+owner = { name = "ops", contact = { email = "ops@example.com" } }
+
+[[servers]]
+name = "primary"
+
+[[servers]]
+name = "secondary"
+tags = ["blue"]
+`,
+			want: map[string]string{
+				"HOSTS_0":             "a.internal",
+				"HOSTS_1":             "b.internal",
+				"MATRIX_0_0":          "1",
+				"MATRIX_1_1":          "4",
+				"OWNER_NAME":          "ops",
+				"OWNER_CONTACT_EMAIL": "ops@example.com",
+				"SERVERS_0_NAME":      "primary",
+				"SERVERS_1_NAME":      "secondary",
+				"SERVERS_1_TAGS_0":    "blue",
+			},
+			absent: []string{"HOSTS", "HOSTS_2", "SERVERS_0_TAGS_0"},
+		},
+		{
+			name: "numbers and dates",
+			content: `int = +1_000
+hex = 0xff
+oct = 0o17
+bin = 0b101
+negative = -17
+float = 6.626e-34
+inf = -inf
+date = 1979-05-27
+datetime = 1979-05-27T07:32:00Z
+local = 1979-05-27 07:32:00
+time = 07:32:00.999
+`,
+			want: map[string]string{
+				"INT":      "1000",
+				"HEX":      "255",
+				"OCT":      "15",
+				"BIN":      "5",
+				"NEGATIVE": "-17",
+				"FLOAT":    "6.626e-34",
+				"INF":      "-inf",
+				"DATE":     "1979-05-27",
+				"DATETIME": "1979-05-27T07:32:00Z",
+				"LOCAL":    "1979-05-27 07:32:00",
+				"TIME":     "07:32:00.999",
+			},
+		},
+		{
+			name: "strings",
+			content: `basic = "tab\there \u00e9 \"quoted\""
+literal = 'C:\Users\app'
+multi = """
+one
+two"""
+folded = """\
+    one \
+    two"""
+raw = '''
+first\n
+second'''
+quotes = """""two quotes"""""
+`,
+			want: map[string]string{
+				"BASIC":   "tab\there é \"quoted\"",
+				"LITERAL": `C:\Users\app`,
+				"MULTI":   "one\ntwo",
+				"FOLDED":  "one two",
+				"RAW":     "first\\n\nsecond",
+				"QUOTES":  `""two quotes""`,
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := writeConfig(t, "config.toml", tt.content)
+			src, err := env.NewTOMLFileSource(path)
+			if err != nil {
+				t.Fatalf("NewTOMLFileSource(): %v", err)
+			}
+			checkSource(t, src, tt.want, tt.absent...)
+		})
+	}
+}
+
+func TestTOMLFileSource_errors(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		wantErr string
+	}{
+		{name: "conflict", content: "db_host = \"a\"\n[db]\nhost = \"b\"\n", wantErr: "keys db.host and db_host are both mapped to DB_HOST"},
+		{name: "value then table", content: "db = 1\n[db]\nhost = \"a\"\n", wantErr: "line 2: key db is already defined as a value"},
+		{name: "table then value", content: "[db]\nhost = \"a\"\n[other]\n[db.host.x]\n", wantErr: "line 4: key db.host is already defined as a value"},
+		{name: "dotted value", content: "db = 1\ndb.host = \"a\"\n", wantErr: "line 2: key db is already defined as a value"},
+		{name: "table twice", content: "[db]\n[db]\n", wantErr: "line 2: table db is defined twice"},
+		{name: "key twice", content: "port = 1\nport = 2\n", wantErr: "line 2: key port is defined twice"},
+		{name: "key then table", content: "[db.pool]\n[db]\npool = 1\n", wantErr: "line 3: key pool is already defined as a table"},
+		{name: "array of tables", content: "[db]\n[[db]]\n", wantErr: "line 2: key db is already defined as a table"},
+		{name: "inline table", content: "db = { host = \"a\" }\n[db.pool]\n", wantErr: "line 2: inline table db cannot be extended"},
+		{name: "invalid value", content: "port = 90 00\n", wantErr: "line 1: unexpected \"00\""},
+		{name: "leading zeros", content: "port = 0900\n", wantErr: "leading zeros are not allowed"},
+		{name: "integer out of range", content: "port = 9223372036854775808\n", wantErr: "line 1: key port: invalid number \"9223372036854775808\": out of range"},
+		{name: "underscore after prefix", content: "port = 0x_1\n", wantErr: "line 1: key port: invalid number \"0x_1\""},
+		{name: "underscore in prefix", content: "port = 0_x1\n", wantErr: "line 1: key port: invalid number \"0_x1\""},
+		{name: "bare hexadecimal prefix", content: "a = 0x\n", wantErr: "line 1: key a: invalid number \"0x\""},
+		{name: "bare octal prefix", content: "a = 0o\n", wantErr: "line 1: key a: invalid number \"0o\""},
+		{name: "bare binary prefix", content: "a = 0b\n", wantErr: "line 1: key a: invalid number \"0b\""},
+		{name: "unterminated string", content: "host = \"db\n", wantErr: "line 1: key host: unterminated string"},
+		{name: "unterminated array", content: "hosts = [\"a\",\n", wantErr: "key hosts: unterminated array"},
+		{name: "missing value", content: "host =\n", wantErr: "line 1: key host: invalid value"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := writeConfig(t, "config.toml", tt.content)
+			_, err := env.NewTOMLFileSource(path)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("NewTOMLFileSource(): got %v, want error containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,365 @@
+package env
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// defaultVaultCacheTTL is the time secrets without a lease are cached for.
+const defaultVaultCacheTTL = 5 * time.Minute
+
+// errVaultDenied is returned for requests that Vault rejects with status 403.
+var errVaultDenied = errors.New("permission denied")
+
+// VaultSource is a Source backed by a secret of the key/value (KV) version 2
+// secrets engine of HashiCorp Vault. Every field of the secret provides the
+// variable of the same name; field names are matched case-insensitively, so a
+// field named "db_password" provides DB_PASSWORD.
+//
+// Vault is accessed over its HTTP API, using a token or AppRole credentials.
+// Tokens obtained with AppRole are renewed when two thirds of their lease have
+// elapsed, and obtained again when they expire or cannot be renewed. The secret
+// is read once and cached for its lease duration, or for the cache TTL if it
+// has no lease, as is usual for KV secrets. A VaultSource is safe for
+// concurrent use.
+type VaultSource struct {
+	addr       string
+	mount      string
+	path       string
+	namespace  string
+	token      string
+	roleID     string
+	secretID   string
+	trimPrefix string
+	ttl        time.Duration
+	timeout    time.Duration
+	client     *http.Client
+
+	mu         sync.Mutex
+	authToken  string
+	renewable  bool
+	renewAt    time.Time
+	expiresAt  time.Time
+	fields     map[string]string
+	fieldsTill time.Time
+}
+
+// VaultOption configures a VaultSource.
+type VaultOption func(*VaultSource)
+
+// VaultToken makes a VaultSource authenticate with token.
+func VaultToken(token string) VaultOption {
+	return func(v *VaultSource) {
+		v.token = token
+	}
+}
+
+// VaultAppRole makes a VaultSource authenticate with the AppRole auth method,
+// mounted at "approle", using roleID and secretID.
+func VaultAppRole(roleID, secretID string) VaultOption {
+	return func(v *VaultSource) {
+		v.roleID, v.secretID = roleID, secretID
+	}
+}
+
+// VaultNamespace makes a VaultSource access the Vault Enterprise namespace ns.
+func VaultNamespace(ns string) VaultOption {
+	return func(v *VaultSource) {
+		v.namespace = ns
+	}
+}
+
+// VaultTrimPrefix makes a VaultSource remove prefix from the names of the
+// variables it looks up before it matches them against fields, so that the
+// fields of a secret do not need to repeat the prefix of a VarSet.
+func VaultTrimPrefix(prefix string) VaultOption {
+	return func(v *VaultSource) {
+		v.trimPrefix = prefix
+	}
+}
+
+// VaultCacheTTL sets the time secrets without a lease are cached for. The
+// default is 5 minutes, and zero disables caching.
+func VaultCacheTTL(d time.Duration) VaultOption {
+	return func(v *VaultSource) {
+		v.ttl = d
+	}
+}
+
+// VaultTimeout sets the time a request to Vault is given to complete, after
+// which it fails. The default is 10 seconds.
+func VaultTimeout(d time.Duration) VaultOption {
+	return func(v *VaultSource) {
+		v.timeout = d
+	}
+}
+
+// VaultHTTPClient makes a VaultSource use c to access Vault. The default is
+// http.DefaultClient; either way, requests are limited by VaultTimeout.
+func VaultHTTPClient(c *http.Client) VaultOption {
+	return func(v *VaultSource) {
+		v.client = c
+	}
+}
+
+// NewVaultSource returns a VaultSource for the secret at path of the KV version
+// 2 secrets engine mounted at mount, such as "secret", of the Vault server at
+// addr, such as "https://vault.internal:8200". Either VaultToken or
+// VaultAppRole must be provided.
+func NewVaultSource(addr, mount, path string, opts ...VaultOption) (*VaultSource, error) {
+	v := &VaultSource{
+		addr:    strings.TrimSuffix(addr, "/"),
+		mount:   strings.Trim(mount, "/"),
+		path:    strings.Trim(path, "/"),
+		ttl:     defaultVaultCacheTTL,
+		timeout: defaultRequestTimeout,
+		client:  http.DefaultClient,
+	}
+	for _, opt := range opts {
+		opt(v)
+	}
+
+	if _, err := url.Parse(v.addr); err != nil || len(v.addr) == 0 {
+		return nil, fmt.Errorf("env: invalid Vault address %q", addr)
+	}
+	if len(v.mount) == 0 || len(v.path) == 0 {
+		return nil, errors.New("env: Vault mount and path are required")
+	}
+	if len(v.token) == 0 && len(v.roleID) == 0 {
+		return nil, errors.New("env: Vault token or AppRole credentials are required")
+	}
+
+	return v, nil
+}
+
+// Lookup retrieves the field of the secret that matches name, reading the
+// secret from Vault unless it is cached.
+func (v *VaultSource) Lookup(name string) (string, bool, error) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	if v.fields == nil || !time.Now().Before(v.fieldsTill) {
+		if err := v.read(); err != nil {
+			return "", false, err
+		}
+	}
+
+	return matchName(v.fields, strings.TrimPrefix(name, v.trimPrefix))
+}
+
+// Sensitive reports true, so that the fields of the secret are redacted by
+// Dump. See SensitiveSource.
+func (v *VaultSource) Sensitive() bool {
+	return true
+}
+
+// Forget removes the cached secret, so that it is read again by the next
+// lookup.
+func (v *VaultSource) Forget() {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	v.fields = nil
+}
+
+// String returns the name of v, as in "vault:secret/app".
+func (v *VaultSource) String() string {
+	return "vault:" + v.mount + "/" + v.path
+}
+
+// read reads the secret from Vault and caches its fields.
+func (v *VaultSource) read() error {
+	var resp struct {
+		LeaseDuration int `json:"lease_duration"`
+		Data          struct {
+			Data map[string]json.RawMessage `json:"data"`
+		} `json:"data"`
+	}
+
+	endpoint := "/v1/" + v.mount + "/data/" + v.path
+	status, err := v.authorized(http.MethodGet, endpoint, nil, &resp)
+	if err != nil {
+		return err
+	}
+
+	fields := make(map[string]string)
+	if status != http.StatusNotFound {
+		fields = jsonFields(resp.Data.Data)
+	}
+
+	ttl := v.ttl
+	if resp.LeaseDuration > 0 {
+		ttl = time.Duration(resp.LeaseDuration) * time.Second
+	}
+	v.fields, v.fieldsTill = fields, time.Now().Add(ttl)
+
+	return nil
+}
+
+// authorized sends an authenticated request to Vault. If the token is rejected
+// and it was obtained with AppRole, a new token is obtained and the request is
+// sent again. A response with status 404 is not an error.
+func (v *VaultSource) authorized(method, endpoint string, body, out any) (int, error) {
+	token, err := v.currentToken()
+	if err != nil {
+		return 0, err
+	}
+
+	status, err := v.do(method, endpoint, token, body, out)
+	if errors.Is(err, errVaultDenied) && len(v.roleID) > 0 {
+		if err := v.login(); err != nil {
+			return 0, err
+		}
+		status, err = v.do(method, endpoint, v.authToken, body, out)
+	}
+
+	return status, err
+}
+
+// currentToken returns the token to authenticate with, logging in with
+// AppRole or renewing the token first if needed.
+func (v *VaultSource) currentToken() (string, error) {
+	if len(v.roleID) == 0 {
+		return v.token, nil
+	}
+
+	now := time.Now()
+	switch {
+	case len(v.authToken) == 0 || (!v.expiresAt.IsZero() && !now.Before(v.expiresAt)):
+		if err := v.login(); err != nil {
+			return "", err
+		}
+	case v.renewable && !now.Before(v.renewAt):
+		if err := v.renew(); err != nil {
+			if err := v.login(); err != nil {
+				return "", err
+			}
+		}
+	}
+
+	return v.authToken, nil
+}
+
+// vaultAuth is the auth part of a Vault login or renew response.
+type vaultAuth struct {
+	Auth *struct {
+		ClientToken   string `json:"client_token"`
+		LeaseDuration int    `json:"lease_duration"`
+		Renewable     bool   `json:"renewable"`
+	} `json:"auth"`
+}
+
+// login obtains a new token with AppRole.
+func (v *VaultSource) login() error {
+	v.authToken = ""
+
+	var resp vaultAuth
+	body := map[string]string{"role_id": v.roleID, "secret_id": v.secretID}
+	status, err := v.do(http.MethodPost, "/v1/auth/approle/login", "", body, &resp)
+	if err != nil {
+		return err
+	}
+	if status == http.StatusNotFound || resp.Auth == nil {
+		return errors.New("AppRole login returned no token")
+	}
+
+	v.setAuth(resp)
+	return nil
+}
+
+// renew renews the current token.
+func (v *VaultSource) renew() error {
+	var resp vaultAuth
+	status, err := v.do(http.MethodPost, "/v1/auth/token/renew-self", v.authToken, struct{}{}, &resp)
+	if err != nil {
+		return err
+	}
+	if status == http.StatusNotFound || resp.Auth == nil {
+		return errors.New("token renewal returned no token")
+	}
+
+	v.setAuth(resp)
+	return nil
+}
+
+// setAuth stores the token of a login or renew response.
+func (v *VaultSource) setAuth(resp vaultAuth) {
+	now := time.Now()
+	lease := time.Duration(resp.Auth.LeaseDuration) * time.Second
+
+	v.authToken = resp.Auth.ClientToken
+	v.renewable = resp.Auth.Renewable && lease > 0
+	v.renewAt, v.expiresAt = time.Time{}, time.Time{}
+	if lease > 0 {
+		v.renewAt = now.Add(lease * 2 / 3)
+		v.expiresAt = now.Add(lease)
+	}
+}
+
+// do sends a request to Vault, and decodes the response into out. A response
+// with status 404 is returned without an error, along with its status, so that
+// callers can handle it. The request fails if it does not complete in time.
+func (v *VaultSource) do(method, endpoint, token string, body, out any) (int, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), v.timeout)
+	defer cancel()
+
+	var r io.Reader
+	if body != nil {
+		b, err := json.Marshal(body)
+		if err != nil {
+			return 0, err
+		}
+		r = bytes.NewReader(b)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, v.addr+endpoint, r)
+	if err != nil {
+		return 0, err
+	}
+	if len(token) > 0 {
+		req.Header.Set("X-Vault-Token", token)
+	}
+	if len(v.namespace) > 0 {
+		req.Header.Set("X-Vault-Namespace", v.namespace)
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := v.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode == http.StatusNotFound:
+		return resp.StatusCode, nil
+	case resp.StatusCode == http.StatusForbidden:
+		return resp.StatusCode, fmt.Errorf("%s %s: %w", method, endpoint, errVaultDenied)
+	case resp.StatusCode >= 300:
+		var e struct {
+			Errors []string `json:"errors"`
+		}
+		_ = json.NewDecoder(resp.Body).Decode(&e)
+		if len(e.Errors) > 0 {
+			return resp.StatusCode, fmt.Errorf("%s %s: %s", method, endpoint, strings.Join(e.Errors, "; "))
+		}
+		return resp.StatusCode, fmt.Errorf("%s %s: %s", method, endpoint, resp.Status)
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return resp.StatusCode, fmt.Errorf("%s %s: invalid response: %w", method, endpoint, err)
+	}
+
+	return resp.StatusCode, nil
+}
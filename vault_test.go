@@ -0,0 +1,227 @@
+package env_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/christgf/env"
+)
+
+// fakeVault is a stand-in for the HTTP API of Vault, serving a single KV
+// version 2 secret.
+type fakeVault struct {
+	t         *testing.T
+	namespace string
+	tokenTTL  int
+
+	mu     sync.Mutex
+	tokens map[string]bool
+	issued int
+	calls  map[string]int
+}
+
+func newFakeVault(t *testing.T, namespace string, tokenTTL int) (*fakeVault, string) {
+	t.Helper()
+
+	fv := &fakeVault{
+		t:         t,
+		namespace: namespace,
+		tokenTTL:  tokenTTL,
+		tokens:    map[string]bool{"root": true},
+		calls:     make(map[string]int),
+	}
+	srv := httptest.NewServer(fv)
+	t.Cleanup(srv.Close)
+
+	return fv, srv.URL
+}
+
+func (fv *fakeVault) count(endpoint string) int {
+	fv.mu.Lock()
+	defer fv.mu.Unlock()
+
+	return fv.calls[endpoint]
+}
+
+func (fv *fakeVault) revokeAll() {
+	fv.mu.Lock()
+	defer fv.mu.Unlock()
+
+	fv.tokens = make(map[string]bool)
+}
+
+func (fv *fakeVault) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	fv.mu.Lock()
+	defer fv.mu.Unlock()
+
+	fv.calls[r.URL.Path]++
+	if got := r.Header.Get("X-Vault-Namespace"); got != fv.namespace {
+		fv.t.Errorf("%s %s: got namespace %q, want %q", r.Method, r.URL.Path, got, fv.namespace)
+	}
+
+	deny := func() {
+		w.WriteHeader(http.StatusForbidden)
+		fmt.Fprint(w, `{"errors":["permission denied"]}`)
+	}
+	auth := func(token string) {
+		fmt.Fprintf(w, `{"auth":{"client_token":%q,"lease_duration":%d,"renewable":true}}`, token, fv.tokenTTL)
+	}
+
+	switch {
+	case r.Method == http.MethodPost && r.URL.Path == "/v1/auth/approle/login":
+		var body struct {
+			RoleID   string `json:"role_id"`
+			SecretID string `json:"secret_id"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil || body.RoleID != "billing" || body.SecretID != "s3cret" {
+			w.WriteHeader(http.StatusBadRequest)
+			fmt.Fprint(w, `{"errors":["invalid role or secret ID"]}`)
+			return
+		}
+		fv.issued++
+		token := fmt.Sprintf("token-%d", fv.issued)
+		fv.tokens[token] = true
+		auth(token)
+	case r.Method == http.MethodPost && r.URL.Path == "/v1/auth/token/renew-self":
+		token := r.Header.Get("X-Vault-Token")
+		if !fv.tokens[token] {
+			deny()
+			return
+		}
+		auth(token)
+	case r.Method == http.MethodGet && r.URL.Path == "/v1/secret/data/apps/billing":
+		if !fv.tokens[r.Header.Get("X-Vault-Token")] {
+			deny()
+			return
+		}
+		fmt.Fprint(w, `{"lease_duration":0,"data":{"data":{"db_password":"hunter2","PORT":5432,"debug":true},"metadata":{"version":3}}}`)
+	default:
+		w.WriteHeader(http.StatusNotFound)
+		fmt.Fprint(w, `{"errors":[]}`)
+	}
+}
+
+func TestVaultSource_token(t *testing.T) {
+	fv, addr := newFakeVault(t, "team-a", 0)
+
+	src, err := env.NewVaultSource(addr, "secret", "apps/billing", env.VaultToken("root"), env.VaultNamespace("team-a"), env.VaultTrimPrefix("BILLING_"))
+	if err != nil {
+		t.Fatalf("NewVaultSource(): %v", err)
+	}
+
+	var vs env.VarSet
+	vs.SetPrefix("BILLING_")
+	vs.SetSources(src)
+	if got, want := vs.String("DB_PASSWORD", ""), "hunter2"; got != want {
+		t.Errorf("String(%q): got %q, want %q", "DB_PASSWORD", got, want)
+	}
+	if got, want := vs.Int("PORT", 0), 5432; got != want {
+		t.Errorf("Int(%q): got %d, want %d", "PORT", got, want)
+	}
+	if got, want := vs.Bool("DEBUG", false), true; got != want {
+		t.Errorf("Bool(%q): got %v, want %v", "DEBUG", got, want)
+	}
+	if got, want := vs.String("MISSING", "fallback"), "fallback"; got != want {
+		t.Errorf("String(%q): got %q, want %q", "MISSING", got, want)
+	}
+	if got, want := fv.count("/v1/secret/data/apps/billing"), 1; got != want {
+		t.Errorf("secret read %d times, want %d", got, want)
+	}
+	checkRedacted(t, &vs, "DB_PASSWORD", "hunter2")
+
+	src.Forget()
+	vs.String("DB_PASSWORD", "")
+	if got, want := fv.count("/v1/secret/data/apps/billing"), 2; got != want {
+		t.Errorf("secret read %d times, want %d", got, want)
+	}
+	if got, want := src.String(), "vault:secret/apps/billing"; got != want {
+		t.Errorf("String(): got %q, want %q", got, want)
+	}
+}
+
+func TestVaultSource_tokenDenied(t *testing.T) {
+	_, addr := newFakeVault(t, "", 0)
+
+	src, err := env.NewVaultSource(addr, "secret", "apps/billing", env.VaultToken("expired"))
+	if err != nil {
+		t.Fatalf("NewVaultSource(): %v", err)
+	}
+	_, ok, err := src.Lookup("db_password")
+	if ok || err == nil || !strings.Contains(err.Error(), "permission denied") {
+		t.Errorf("Lookup(): got %v, %v, want permission denied", ok, err)
+	}
+}
+
+func TestVaultSource_appRole(t *testing.T) {
+	fv, addr := newFakeVault(t, "", 1)
+
+	src, err := env.NewVaultSource(addr, "secret", "apps/billing", env.VaultAppRole("billing", "s3cret"), env.VaultCacheTTL(0))
+	if err != nil {
+		t.Fatalf("NewVaultSource(): %v", err)
+	}
+
+	lookup := func() {
+		t.Helper()
+		if value, ok, err := src.Lookup("DB_PASSWORD"); err != nil || !ok || value != "hunter2" {
+			t.Fatalf("Lookup(%q): got %q, %v, %v", "DB_PASSWORD", value, ok, err)
+		}
+	}
+
+	lookup()
+	lookup()
+	if got, want := fv.count("/v1/auth/approle/login"), 1; got != want {
+		t.Errorf("logged in %d times, want %d", got, want)
+	}
+
+	time.Sleep(700 * time.Millisecond)
+	lookup()
+	if got, want := fv.count("/v1/auth/token/renew-self"), 1; got != want {
+		t.Errorf("renewed %d times, want %d", got, want)
+	}
+
+	fv.revokeAll()
+	lookup()
+	if got, want := fv.count("/v1/auth/approle/login"), 2; got != want {
+		t.Errorf("logged in %d times, want %d", got, want)
+	}
+}
+
+func TestVaultSource_notFound(t *testing.T) {
+	_, addr := newFakeVault(t, "", 0)
+
+	src, err := env.NewVaultSource(addr, "secret", "apps/missing", env.VaultToken("root"))
+	if err != nil {
+		t.Fatalf("NewVaultSource(): %v", err)
+	}
+	if _, ok, err := src.Lookup("DB_PASSWORD"); ok || err != nil {
+		t.Errorf("Lookup(): got %v, %v, want not found", ok, err)
+	}
+}
+
+func TestNewVaultSource_errors(t *testing.T) {
+	if _, err := env.NewVaultSource("http://vault", "secret", "app"); err == nil {
+		t.Errorf("NewVaultSource(): got no error without credentials")
+	}
+	if _, err := env.NewVaultSource("http://vault", "", "app", env.VaultToken("root")); err == nil {
+		t.Errorf("NewVaultSource(): got no error without mount")
+	}
+	if _, err := env.NewVaultSource("", "secret", "app", env.VaultToken("root")); err == nil {
+		t.Errorf("NewVaultSource(): got no error without address")
+	}
+}
+
+func TestVaultSource_timeout(t *testing.T) {
+	srv := newHangingServer(t)
+
+	src, err := env.NewVaultSource(srv.URL, "secret", "apps/billing", env.VaultToken("root"), env.VaultTimeout(50*time.Millisecond))
+	if err != nil {
+		t.Fatalf("NewVaultSource(): %v", err)
+	}
+	checkTimeout(t, src)
+}
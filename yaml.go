@@ -0,0 +1,621 @@
+package env
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// yamlParser parses the subset of YAML described by NewYAMLFileSource into a
+// tree for flatten. Scalars are kept as text, except for null values.
+type yamlParser struct {
+	lines []yamlLine
+	pos   int
+}
+
+// yamlLine is a line of a YAML document.
+type yamlLine struct {
+	num    int
+	indent int
+	raw    string // the line, without its indentation
+	text   string // raw, without a comment and trailing white space
+}
+
+// yamlError is an error at a line of a YAML document.
+func yamlError(num int, format string, args ...any) error {
+	return fmt.Errorf("line %d: %s", num, fmt.Sprintf(format, args...))
+}
+
+// parseYAML parses a YAML document holding a mapping.
+func parseYAML(b []byte) (map[string]any, error) {
+	p := &yamlParser{}
+	started := false
+	for i, raw := range strings.Split(strings.ReplaceAll(string(b), "\r\n", "\n"), "\n") {
+		num := i + 1
+		trimmed := strings.TrimLeft(raw, " ")
+		if strings.HasPrefix(trimmed, "\t") {
+			return nil, yamlError(num, "tabs are not allowed in indentation")
+		}
+		l := yamlLine{num: num, indent: len(raw) - len(trimmed), raw: trimmed, text: stripYAMLComment(trimmed)}
+
+		if l.indent == 0 && (l.text == "---" || strings.HasPrefix(l.text, "--- ")) {
+			if started {
+				return nil, yamlError(num, "multiple documents are not supported")
+			}
+			started = true
+			if rest := strings.TrimSpace(l.text[len("---"):]); len(rest) > 0 {
+				return nil, yamlError(num, "content after document marker is not supported")
+			}
+			continue
+		}
+		if l.indent == 0 && l.text == "..." {
+			break
+		}
+		if l.indent == 0 && strings.HasPrefix(l.text, "%") && !started {
+			continue
+		}
+		if len(l.text) > 0 {
+			started = true
+		}
+		p.lines = append(p.lines, l)
+	}
+
+	if !p.next() {
+		return map[string]any{}, nil
+	}
+	l := p.lines[p.pos]
+	if l.indent > 0 {
+		return nil, yamlError(l.num, "unexpected indentation")
+	}
+	if _, _, ok := splitYAMLKey(l.text); !ok {
+		return nil, yamlError(l.num, "top-level value is not a mapping")
+	}
+	root, err := p.mapping(0)
+	if err != nil {
+		return nil, err
+	}
+	if p.next() {
+		return nil, yamlError(p.lines[p.pos].num, "unexpected content")
+	}
+
+	return root, nil
+}
+
+// next skips blank lines and comments, and reports whether any line is left.
+func (p *yamlParser) next() bool {
+	for ; p.pos < len(p.lines); p.pos++ {
+		if len(p.lines[p.pos].text) > 0 {
+			return true
+		}
+	}
+
+	return false
+}
+
+// node parses the block node that starts at the current line, whose indent is
+// indent.
+func (p *yamlParser) node(indent int) (any, error) {
+	l := p.lines[p.pos]
+	if l.text == "-" || strings.HasPrefix(l.text, "- ") {
+		return p.sequence(indent)
+	}
+	if _, _, ok := splitYAMLKey(l.text); ok {
+		return p.mapping(indent)
+	}
+
+	p.pos++
+	v, err := yamlValue(l.num, l.text)
+	if err != nil {
+		return nil, err
+	}
+
+	return v, p.endOfScalar(indent)
+}
+
+// endOfScalar checks that a scalar whose node has indent indent is not
+// continued on the next line.
+func (p *yamlParser) endOfScalar(indent int) error {
+	if !p.next() || p.lines[p.pos].indent <= indent {
+		return nil
+	}
+
+	l := p.lines[p.pos]
+	if _, _, ok := splitYAMLKey(l.text); ok || strings.HasPrefix(l.text, "- ") {
+		return yamlError(l.num, "unexpected indentation")
+	}
+	return yamlError(l.num, "multi-line plain scalars are not supported")
+}
+
+// mapping parses the entries of a block mapping whose indent is indent.
+func (p *yamlParser) mapping(indent int) (map[string]any, error) {
+	m := make(map[string]any)
+	for p.next() {
+		l := p.lines[p.pos]
+		if l.indent < indent {
+			break
+		}
+		if l.indent > indent {
+			return nil, yamlError(l.num, "unexpected indentation")
+		}
+		key, rest, ok := splitYAMLKey(l.text)
+		if !ok {
+			return nil, yamlError(l.num, "expected a mapping entry")
+		}
+		if _, dup := m[key]; dup {
+			return nil, yamlError(l.num, "duplicate key %q", key)
+		}
+
+		v, err := p.value(indent, l, rest, true)
+		if err != nil {
+			return nil, err
+		}
+		m[key] = v
+	}
+
+	return m, nil
+}
+
+// sequence parses the entries of a block sequence whose indent is indent.
+func (p *yamlParser) sequence(indent int) ([]any, error) {
+	var s []any
+	for p.next() {
+		l := p.lines[p.pos]
+		if l.indent < indent {
+			break
+		}
+		if l.indent > indent {
+			return nil, yamlError(l.num, "unexpected indentation")
+		}
+		if l.text != "-" && !strings.HasPrefix(l.text, "- ") {
+			break
+		}
+
+		rest := strings.TrimLeft(l.text[1:], " ")
+		if len(rest) > 0 && !isYAMLBlockScalar(rest) {
+			// A compact node, such as "- key: value" or "- - item", continues
+			// at the column it starts at.
+			if _, _, ok := splitYAMLKey(rest); ok || rest == "-" || strings.HasPrefix(rest, "- ") {
+				offset := len(l.text) - len(rest)
+				p.lines[p.pos] = yamlLine{
+					num:    l.num,
+					indent: indent + offset,
+					raw:    l.raw[offset:],
+					text:   rest,
+				}
+				v, err := p.node(indent + offset)
+				if err != nil {
+					return nil, err
+				}
+				s = append(s, v)
+				continue
+			}
+		}
+
+		v, err := p.value(indent, l, rest, false)
+		if err != nil {
+			return nil, err
+		}
+		s = append(s, v)
+	}
+
+	return s, nil
+}
+
+// value parses the value of a mapping entry or a sequence entry at the line l,
+// whose indent is indent, given the rest of the line after the key or the dash.
+// A mapping entry may hold a sequence with the same indent as its key.
+func (p *yamlParser) value(indent int, l yamlLine, rest string, entry bool) (any, error) {
+	p.pos++
+	if isYAMLBlockScalar(rest) {
+		return p.blockScalar(indent, l.num, rest)
+	}
+	if len(rest) > 0 {
+		v, err := yamlValue(l.num, rest)
+		if err != nil {
+			return nil, err
+		}
+		return v, p.endOfScalar(indent)
+	}
+
+	if !p.next() {
+		return nil, nil
+	}
+	child := p.lines[p.pos]
+	switch {
+	case child.indent > indent:
+		return p.node(child.indent)
+	case entry && child.indent == indent && (child.text == "-" || strings.HasPrefix(child.text, "- ")):
+		return p.sequence(indent)
+	default:
+		return nil, nil
+	}
+}
+
+// isYAMLBlockScalar reports whether s is the header of a literal or folded
+// block scalar, such as "|" or ">-".
+func isYAMLBlockScalar(s string) bool {
+	if len(s) == 0 || (s[0] != '|' && s[0] != '>') {
+		return false
+	}
+	for _, c := range s[1:] {
+		if c != '-' && c != '+' && (c < '1' || c > '9') {
+			return false
+		}
+	}
+
+	return true
+}
+
+// blockScalar parses the content of a block scalar with header, whose parent
+// node has indent indent. Content lines keep their comments.
+func (p *yamlParser) blockScalar(indent, num int, header string) (string, error) {
+	literal := header[0] == '|'
+	chomp := byte(0)
+	for i := 1; i < len(header); i++ {
+		switch c := header[i]; c {
+		case '-', '+':
+			chomp = c
+		default:
+			return "", yamlError(num, "explicit indentation of block scalars is not supported")
+		}
+	}
+
+	var lines []string
+	content := -1
+	for ; p.pos < len(p.lines); p.pos++ {
+		l := p.lines[p.pos]
+		blank := len(strings.TrimSpace(l.raw)) == 0
+		if !blank && l.indent <= indent {
+			break
+		}
+		if !blank && content < 0 {
+			content = l.indent
+		}
+		if !blank && l.indent < content {
+			return "", yamlError(l.num, "unexpected indentation in block scalar")
+		}
+		if blank {
+			lines = append(lines, "")
+			continue
+		}
+		lines = append(lines, strings.Repeat(" ", l.indent-content)+strings.TrimRight(l.raw, " "))
+	}
+
+	// Trailing blank lines only matter for chomping.
+	trailing := 0
+	for len(lines) > 0 && lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+		trailing++
+	}
+
+	var b strings.Builder
+	for i, line := range lines {
+		if i > 0 {
+			prev := lines[i-1]
+			switch {
+			case literal:
+				b.WriteByte('\n')
+			case line == "" || prev == "" || strings.HasPrefix(line, " ") || strings.HasPrefix(prev, " "):
+				// Folding only joins consecutive lines of text with a space;
+				// blank lines and more indented lines are kept as newlines.
+				if line != "" || prev == "" {
+					b.WriteByte('\n')
+				}
+			default:
+				b.WriteByte(' ')
+			}
+		}
+		b.WriteString(line)
+	}
+
+	s := b.String()
+	switch {
+	case len(lines) == 0:
+		if chomp == '+' {
+			return strings.Repeat("\n", trailing), nil
+		}
+		return "", nil
+	case chomp == '-':
+		return s, nil
+	case chomp == '+':
+		return s + "\n" + strings.Repeat("\n", trailing), nil
+	default:
+		return s + "\n", nil
+	}
+}
+
+// splitYAMLKey splits a mapping entry, such as "key: value", into its key and
+// the rest of the line, and reports whether text is a mapping entry.
+func splitYAMLKey(text string) (key, rest string, ok bool) {
+	if len(text) == 0 || text[0] == '[' || text[0] == '{' || text == "-" || strings.HasPrefix(text, "- ") {
+		return "", "", false
+	}
+
+	if text[0] == '"' || text[0] == '\'' {
+		key, n, err := yamlQuoted(text)
+		if err != nil {
+			return "", "", false
+		}
+		rest := strings.TrimLeft(text[n:], " ")
+		if rest != ":" && !strings.HasPrefix(rest, ": ") {
+			return "", "", false
+		}
+		return key, strings.TrimSpace(rest[1:]), true
+	}
+
+	for i := 0; i < len(text); i++ {
+		if text[i] == ':' && (i+1 == len(text) || text[i+1] == ' ') {
+			return strings.TrimSpace(text[:i]), strings.TrimSpace(text[i+1:]), i > 0
+		}
+	}
+
+	return "", "", false
+}
+
+// stripYAMLComment removes a comment and trailing white space from a line. A
+// comment starts with '#' at the start of the line or after white space, outside
+// of quoted scalars.
+func stripYAMLComment(s string) string {
+	var quote byte
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case quote == '"' && c == '\\':
+			i++
+		case quote != 0:
+			if c == quote {
+				if quote == '\'' && i+1 < len(s) && s[i+1] == '\'' {
+					i++
+				} else {
+					quote = 0
+				}
+			}
+		case c == '"' || c == '\'':
+			if i == 0 || strings.IndexByte(" [{,:-", s[i-1]) >= 0 {
+				quote = c
+			}
+		case c == '#':
+			if i == 0 || s[i-1] == ' ' || s[i-1] == '\t' {
+				return strings.TrimRight(s[:i], " \t")
+			}
+		}
+	}
+
+	return strings.TrimRight(s, " \t")
+}
+
+// yamlValue parses a value that fits on a line: a flow collection or a scalar.
+func yamlValue(num int, s string) (any, error) {
+	switch s[0] {
+	case '&', '*', '!':
+		return nil, yamlError(num, "anchors, aliases and tags are not supported")
+	case '?':
+		if s == "?" || strings.HasPrefix(s, "? ") {
+			return nil, yamlError(num, "complex keys are not supported")
+		}
+	case '[', '{':
+		v, n, err := yamlFlow(s, 0)
+		if err != nil {
+			return nil, yamlError(num, "%v", err)
+		}
+		if rest := strings.TrimSpace(s[n:]); len(rest) > 0 {
+			return nil, yamlError(num, "unexpected %q after flow collection", rest)
+		}
+		return v, nil
+	case '"', '\'':
+		v, n, err := yamlQuoted(s)
+		if err != nil {
+			return nil, yamlError(num, "%v", err)
+		}
+		if rest := strings.TrimSpace(s[n:]); len(rest) > 0 {
+			return nil, yamlError(num, "unexpected %q after quoted scalar", rest)
+		}
+		return v, nil
+	}
+
+	return yamlPlain(s), nil
+}
+
+// yamlPlain returns the value of a plain scalar: nil for null values, and the
+// text itself otherwise.
+func yamlPlain(s string) any {
+	switch s {
+	case "", "~", "null", "Null", "NULL":
+		return nil
+	default:
+		return s
+	}
+}
+
+// yamlFlow parses the flow collection that starts at s[i], and returns it
+// along with the index of the byte that follows it. Flow collections must fit
+// on a line.
+func yamlFlow(s string, i int) (any, int, error) {
+	open := s[i]
+	end := byte(']')
+	if open == '{' {
+		end = '}'
+	}
+	i++
+
+	var seq []any
+	m := make(map[string]any)
+	for {
+		i = skipSpaces(s, i)
+		if i == len(s) {
+			return nil, 0, errors.New("unterminated flow collection; flow collections must fit on a line")
+		}
+		if s[i] == end {
+			i++
+			break
+		}
+
+		var key string
+		if open == '{' {
+			k, n, err := yamlFlowScalar(s, i, true)
+			if err != nil {
+				return nil, 0, err
+			}
+			i = skipSpaces(s, n)
+			if i == len(s) || s[i] != ':' {
+				return nil, 0, fmt.Errorf("missing ':' after key %q in flow mapping", k)
+			}
+			i++
+			ks, _ := k.(string)
+			if _, dup := m[ks]; dup {
+				return nil, 0, fmt.Errorf("duplicate key %q", ks)
+			}
+			key = ks
+			i = skipSpaces(s, i)
+		}
+
+		var v any
+		var err error
+		if i < len(s) && (s[i] == '[' || s[i] == '{') {
+			v, i, err = yamlFlow(s, i)
+		} else {
+			v, i, err = yamlFlowScalar(s, i, false)
+		}
+		if err != nil {
+			return nil, 0, err
+		}
+		if open == '{' {
+			m[key] = v
+		} else {
+			seq = append(seq, v)
+		}
+
+		i = skipSpaces(s, i)
+		switch {
+		case i == len(s):
+			return nil, 0, errors.New("unterminated flow collection; flow collections must fit on a line")
+		case s[i] == ',':
+			i++
+		case s[i] != end:
+			return nil, 0, fmt.Errorf("expected ',' or '%c' in flow collection", end)
+		}
+	}
+
+	if open == '{' {
+		return m, i, nil
+	}
+	if seq == nil {
+		seq = []any{}
+	}
+	return seq, i, nil
+}
+
+// yamlFlowScalar parses the scalar that starts at s[i] in a flow collection.
+// Plain scalars end at ',', ']', '}', or at ':' if they are keys.
+func yamlFlowScalar(s string, i int, isKey bool) (any, int, error) {
+	if i < len(s) && (s[i] == '"' || s[i] == '\'') {
+		v, n, err := yamlQuoted(s[i:])
+		return v, i + n, err
+	}
+	if i < len(s) && strings.IndexByte("&*!", s[i]) >= 0 {
+		return nil, 0, errors.New("anchors, aliases and tags are not supported")
+	}
+
+	j := i
+	for j < len(s) && s[j] != ',' && s[j] != ']' && s[j] != '}' {
+		if s[j] == ':' && (isKey || j+1 == len(s) || s[j+1] == ' ') {
+			break
+		}
+		j++
+	}
+
+	return yamlPlain(strings.TrimSpace(s[i:j])), j, nil
+}
+
+// skipSpaces returns the index of the first byte of s at or after i that is not
+// a space.
+func skipSpaces(s string, i int) int {
+	for i < len(s) && (s[i] == ' ' || s[i] == '\t') {
+		i++
+	}
+
+	return i
+}
+
+// yamlQuoted parses the single or double quoted scalar at the start of s, and
+// returns it along with its length in s.
+func yamlQuoted(s string) (string, int, error) {
+	quote := s[0]
+	var b strings.Builder
+	for i := 1; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case c == quote && quote == '\'' && i+1 < len(s) && s[i+1] == '\'':
+			b.WriteByte('\'')
+			i++
+		case c == quote:
+			return b.String(), i + 1, nil
+		case c == '\\' && quote == '"':
+			if i+1 == len(s) {
+				return "", 0, errors.New("unterminated quoted scalar")
+			}
+			i++
+			r, n, err := yamlEscape(s[i:])
+			if err != nil {
+				return "", 0, err
+			}
+			b.WriteRune(r)
+			i += n - 1
+		default:
+			b.WriteByte(c)
+		}
+	}
+
+	return "", 0, errors.New("unterminated quoted scalar; quoted scalars must fit on a line")
+}
+
+// yamlEscape decodes the escape sequence at the start of s, after the
+// backslash, and returns the rune along with the length of the sequence.
+func yamlEscape(s string) (rune, int, error) {
+	switch s[0] {
+	case '0':
+		return 0, 1, nil
+	case 'a':
+		return '\a', 1, nil
+	case 'b':
+		return '\b', 1, nil
+	case 't', '\t':
+		return '\t', 1, nil
+	case 'n':
+		return '\n', 1, nil
+	case 'v':
+		return '\v', 1, nil
+	case 'f':
+		return '\f', 1, nil
+	case 'r':
+		return '\r', 1, nil
+	case 'e':
+		return 0x1b, 1, nil
+	case ' ', '"', '/', '\\':
+		return rune(s[0]), 1, nil
+	case 'x':
+		return hexRune(s, 2)
+	case 'u':
+		return hexRune(s, 4)
+	case 'U':
+		return hexRune(s, 8)
+	default:
+		return 0, 0, fmt.Errorf("invalid escape sequence \\%c", s[0])
+	}
+}
+
+// hexRune decodes the rune given by the n hexadecimal digits that follow the
+// first byte of s, and returns it along with the number of bytes used.
+func hexRune(s string, n int) (rune, int, error) {
+	if len(s) < n+1 {
+		return 0, 0, fmt.Errorf("invalid escape sequence \\%s", s)
+	}
+	v, err := strconv.ParseUint(s[1:n+1], 16, 32)
+	if err != nil || !utf8.ValidRune(rune(v)) {
+		return 0, 0, fmt.Errorf("invalid escape sequence \\%s", s[:n+1])
+	}
+
+	return rune(v), n + 1, nil
+}
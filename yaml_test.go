@@ -0,0 +1,170 @@
+package env_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/christgf/env"
+)
+
+func TestYAMLFileSource(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    map[string]string
+		absent  []string
+	}{
+		{
+			name: "mappings",
+			content: `# Service configuration
+%YAML 1.2
+---
+port: 9000
+debug: true # inline comment
+db:
+  host: db.internal
+  pool:
+    max: 10
+    maxIdleConns: 2
+replica: ~
+empty:
+url: http://example.com/#anchor
+`,
+			want: map[string]string{
+				"PORT":                   "9000",
+				"DEBUG":                  "true",
+				"DB_HOST":                "db.internal",
+				"DB_POOL_MAX":            "10",
+				"DB_POOL_MAX_IDLE_CONNS": "2",
+				"URL":                    "http://example.com/#anchor",
+			},
+			absent: []string{"REPLICA", "EMPTY", "DB"},
+		},
+		{
+			name: "sequences",
+			content: `hosts:
+  - a.internal
+  - b.internal
+servers:
+- name: primary
+  weight: 3
+- name: secondary
+  tags: [blue, "green, too"]
+matrix:
+  - - 1
+    - 2
+  - [3, 4]
+`,
+			want: map[string]string{
+				"HOSTS_0":          "a.internal",
+				"HOSTS_1":          "b.internal",
+				"SERVERS_0_NAME":   "primary",
+				"SERVERS_0_WEIGHT": "3",
+				"SERVERS_1_NAME":   "secondary",
+				"SERVERS_1_TAGS_0": "blue",
+				"SERVERS_1_TAGS_1": "green, too",
+				"MATRIX_0_0":       "1",
+				"MATRIX_0_1":       "2",
+				"MATRIX_1_0":       "3",
+				"MATRIX_1_1":       "4",
+			},
+			absent: []string{"HOSTS", "HOSTS_2", "SERVERS_1_WEIGHT"},
+		},
+		{
+			name: "flow mappings",
+			content: `db: {host: db.internal, port: 5432, opts: {ssl: "on", url: http://x:80}}
+`,
+			want: map[string]string{
+				"DB_HOST":     "db.internal",
+				"DB_PORT":     "5432",
+				"DB_OPTS_SSL": "on",
+				"DB_OPTS_URL": "http://x:80",
+			},
+		},
+		{
+			name: "quoted scalars",
+			content: `double: "tab\there é # not a comment"
+single: 'it''s # not a comment'
+"quoted key": value
+plain: it's fine # a comment
+`,
+			want: map[string]string{
+				"DOUBLE":     "tab\there é # not a comment",
+				"SINGLE":     "it's # not a comment",
+				"QUOTED KEY": "value",
+				"PLAIN":      "it's fine",
+			},
+		},
+		{
+			name: "block scalars",
+			content: `literal: |
+  line one
+    indented # kept
+
+  line three
+folded: >
+  one
+  two
+
+  three
+strip: |-
+  text
+
+keep: |+
+  text
+
+next: value
+`,
+			want: map[string]string{
+				"LITERAL": "line one\n  indented # kept\n\nline three\n",
+				"FOLDED":  "one two\nthree\n",
+				"STRIP":   "text",
+				"KEEP":    "text\n\n",
+				"NEXT":    "value",
+			},
+		},
+		{
+			name:    "empty document",
+			content: "# nothing here\n",
+			absent:  []string{"PORT"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := writeConfig(t, "config.yaml", tt.content)
+			src, err := env.NewYAMLFileSource(path)
+			if err != nil {
+				t.Fatalf("NewYAMLFileSource(): %v", err)
+			}
+			checkSource(t, src, tt.want, tt.absent...)
+		})
+	}
+}
+
+func TestYAMLFileSource_errors(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		wantErr string
+	}{
+		{name: "conflict", content: "db:\n  host: a\ndb_host: b\n", wantErr: "keys db.host and db_host are both mapped to DB_HOST"},
+		{name: "duplicate key", content: "port: 1\nport: 2\n", wantErr: `line 2: duplicate key "port"`},
+		{name: "indentation", content: "db:\n  host: a\n    port: 1\n", wantErr: "line 3: unexpected indentation"},
+		{name: "tabs", content: "db:\n\thost: a\n", wantErr: "line 2: tabs are not allowed in indentation"},
+		{name: "not a mapping", content: "- a\n- b\n", wantErr: "line 1: top-level value is not a mapping"},
+		{name: "anchor", content: "base: &base 1\n", wantErr: "line 1: anchors, aliases and tags are not supported"},
+		{name: "multiple documents", content: "a: 1\n---\nb: 2\n", wantErr: "line 2: multiple documents are not supported"},
+		{name: "unterminated quote", content: "a: \"open\n", wantErr: "line 1: unterminated quoted scalar"},
+		{name: "unterminated flow", content: "a: [1, 2\n", wantErr: "line 1: unterminated flow collection"},
+		{name: "multi-line plain", content: "a: one\n  two\n", wantErr: "line 2: multi-line plain scalars are not supported"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := writeConfig(t, "config.yaml", tt.content)
+			_, err := env.NewYAMLFileSource(path)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("NewYAMLFileSource(): got %v, want error containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}